@@ -0,0 +1,307 @@
+// Package assert evaluates declarative expectations against a stream of
+// domain.LogEntry values so CI pipelines can gate on log quality.
+package assert
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+// Rule evaluates a stream of log entries and produces a Result describing
+// whether the expectation held.
+type Rule interface {
+	// Name identifies the rule in output (defaults to a generated name if the
+	// rule config omits one).
+	Name() string
+	// Evaluate is called once per log entry as the stream is consumed.
+	Evaluate(entry *domain.LogEntry)
+	// Result finalizes the rule after the stream has been fully consumed.
+	Result() Result
+}
+
+// Result is the outcome of evaluating a single Rule.
+type Result struct {
+	Name           string      `json:"name"`
+	Passed         bool        `json:"passed"`
+	Actual         interface{} `json:"actual"`
+	Expected       interface{} `json:"expected"`
+	FailureMessage string      `json:"failure_message,omitempty"`
+}
+
+// Summary aggregates the results of running a rule set against a stream.
+type Summary struct {
+	Passed  bool     `json:"passed"`
+	Total   int      `json:"total"`
+	Failed  int      `json:"failed"`
+	Results []Result `json:"results"`
+}
+
+// RuleConfig is the declarative, user-facing shape of a single rule as read
+// from a YAML/JSON assertion file or built from --assert flags.
+type RuleConfig struct {
+	Name               string              `json:"name" yaml:"name"`
+	MaxErrors          *int                `json:"max_errors,omitempty" yaml:"max_errors,omitempty"`
+	MaxFaults          *int                `json:"max_faults,omitempty" yaml:"max_faults,omitempty"`
+	MustContain        string              `json:"must_contain,omitempty" yaml:"must_contain,omitempty"`
+	MustNotContain     string              `json:"must_not_contain,omitempty" yaml:"must_not_contain,omitempty"`
+	MaxDurationBetween string              `json:"max_duration_between,omitempty" yaml:"max_duration_between,omitempty"`
+	PatternCount       *PatternCountConfig `json:"pattern_count,omitempty" yaml:"pattern_count,omitempty"`
+}
+
+// PatternCountConfig configures a pattern_count rule: count entries whose
+// message matches Regex and compare against Value using Op (==, !=, <, <=, >, >=).
+type PatternCountConfig struct {
+	Regex string `json:"regex" yaml:"regex"`
+	Op    string `json:"op" yaml:"op"`
+	Value int    `json:"value" yaml:"value"`
+}
+
+// Build converts rule configs into evaluable Rule instances, in order.
+func Build(configs []RuleConfig) ([]Rule, error) {
+	rules := make([]Rule, 0, len(configs))
+	for i, c := range configs {
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("rule_%d", i+1)
+		}
+		switch {
+		case c.MaxErrors != nil:
+			rules = append(rules, &maxLevelRule{name: name, level: domain.LogLevelError, max: *c.MaxErrors})
+		case c.MaxFaults != nil:
+			rules = append(rules, &maxLevelRule{name: name, level: domain.LogLevelFault, max: *c.MaxFaults})
+		case c.MustContain != "":
+			rules = append(rules, &mustContainRule{name: name, substr: c.MustContain})
+		case c.MustNotContain != "":
+			rules = append(rules, &mustNotContainRule{name: name, substr: c.MustNotContain})
+		case c.MaxDurationBetween != "":
+			d, err := time.ParseDuration(c.MaxDurationBetween)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max_duration_between in rule %q: %w", name, err)
+			}
+			rules = append(rules, &maxDurationBetweenRule{name: name, max: d})
+		case c.PatternCount != nil:
+			re, err := regexp.Compile(c.PatternCount.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern_count regex in rule %q: %w", name, err)
+			}
+			op, err := parseOp(c.PatternCount.Op)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern_count op in rule %q: %w", name, err)
+			}
+			rules = append(rules, &patternCountRule{name: name, regex: re, op: op, want: c.PatternCount.Value})
+		default:
+			return nil, fmt.Errorf("rule %q has no recognized condition", name)
+		}
+	}
+	return rules, nil
+}
+
+// Run feeds every entry through every rule and returns the aggregate summary.
+func Run(rules []Rule, entries []domain.LogEntry) Summary {
+	for i := range entries {
+		for _, r := range rules {
+			r.Evaluate(&entries[i])
+		}
+	}
+	summary := Summary{Passed: true, Total: len(rules), Results: make([]Result, 0, len(rules))}
+	for _, r := range rules {
+		res := r.Result()
+		summary.Results = append(summary.Results, res)
+		if !res.Passed {
+			summary.Passed = false
+			summary.Failed++
+		}
+	}
+	return summary
+}
+
+type op int
+
+const (
+	opEq op = iota
+	opNe
+	opLt
+	opLe
+	opGt
+	opGe
+)
+
+func parseOp(s string) (op, error) {
+	switch strings.TrimSpace(s) {
+	case "==", "":
+		return opEq, nil
+	case "!=":
+		return opNe, nil
+	case "<":
+		return opLt, nil
+	case "<=":
+		return opLe, nil
+	case ">":
+		return opGt, nil
+	case ">=":
+		return opGe, nil
+	default:
+		return 0, fmt.Errorf("unsupported operator %q", s)
+	}
+}
+
+func (o op) compare(actual, want int) bool {
+	switch o {
+	case opEq:
+		return actual == want
+	case opNe:
+		return actual != want
+	case opLt:
+		return actual < want
+	case opLe:
+		return actual <= want
+	case opGt:
+		return actual > want
+	case opGe:
+		return actual >= want
+	default:
+		return false
+	}
+}
+
+func (o op) String() string {
+	switch o {
+	case opEq:
+		return "=="
+	case opNe:
+		return "!="
+	case opLt:
+		return "<"
+	case opLe:
+		return "<="
+	case opGt:
+		return ">"
+	case opGe:
+		return ">="
+	default:
+		return "?"
+	}
+}
+
+// maxLevelRule fails when more than `max` entries at exactly `level` are seen.
+type maxLevelRule struct {
+	name  string
+	level domain.LogLevel
+	max   int
+	count int
+}
+
+func (r *maxLevelRule) Name() string { return r.name }
+func (r *maxLevelRule) Evaluate(entry *domain.LogEntry) {
+	if entry.Level == r.level {
+		r.count++
+	}
+}
+func (r *maxLevelRule) Result() Result {
+	res := Result{Name: r.name, Actual: r.count, Expected: r.max, Passed: r.count <= r.max}
+	if !res.Passed {
+		res.FailureMessage = fmt.Sprintf("expected at most %d %s entries, saw %d", r.max, r.level, r.count)
+	}
+	return res
+}
+
+// mustContainRule fails unless at least one entry message contains substr.
+type mustContainRule struct {
+	name   string
+	substr string
+	found  bool
+}
+
+func (r *mustContainRule) Name() string { return r.name }
+func (r *mustContainRule) Evaluate(entry *domain.LogEntry) {
+	if strings.Contains(entry.Message, r.substr) {
+		r.found = true
+	}
+}
+func (r *mustContainRule) Result() Result {
+	res := Result{Name: r.name, Actual: r.found, Expected: true, Passed: r.found}
+	if !res.Passed {
+		res.FailureMessage = fmt.Sprintf("expected a log message containing %q", r.substr)
+	}
+	return res
+}
+
+// mustNotContainRule fails as soon as any entry message contains substr.
+type mustNotContainRule struct {
+	name    string
+	substr  string
+	matched string
+}
+
+func (r *mustNotContainRule) Name() string { return r.name }
+func (r *mustNotContainRule) Evaluate(entry *domain.LogEntry) {
+	if r.matched == "" && strings.Contains(entry.Message, r.substr) {
+		r.matched = entry.Message
+	}
+}
+func (r *mustNotContainRule) Result() Result {
+	passed := r.matched == ""
+	res := Result{Name: r.name, Actual: r.matched, Expected: nil, Passed: passed}
+	if !passed {
+		res.FailureMessage = fmt.Sprintf("expected no log message containing %q, found: %q", r.substr, r.matched)
+	}
+	return res
+}
+
+// maxDurationBetweenRule fails if the gap between two consecutive entries
+// exceeds `max`.
+type maxDurationBetweenRule struct {
+	name     string
+	max      time.Duration
+	last     time.Time
+	haveLast bool
+	worst    time.Duration
+}
+
+func (r *maxDurationBetweenRule) Name() string { return r.name }
+func (r *maxDurationBetweenRule) Evaluate(entry *domain.LogEntry) {
+	if r.haveLast {
+		gap := entry.Timestamp.Sub(r.last)
+		if gap > r.worst {
+			r.worst = gap
+		}
+	}
+	r.last = entry.Timestamp
+	r.haveLast = true
+}
+func (r *maxDurationBetweenRule) Result() Result {
+	res := Result{Name: r.name, Actual: r.worst.String(), Expected: r.max.String(), Passed: r.worst <= r.max}
+	if !res.Passed {
+		res.FailureMessage = fmt.Sprintf("gap of %s between entries exceeds max %s", r.worst, r.max)
+	}
+	return res
+}
+
+// patternCountRule compares the number of messages matching regex against
+// want using op.
+type patternCountRule struct {
+	name  string
+	regex *regexp.Regexp
+	op    op
+	want  int
+	count int
+}
+
+func (r *patternCountRule) Name() string { return r.name }
+func (r *patternCountRule) Evaluate(entry *domain.LogEntry) {
+	if r.regex.MatchString(entry.Message) {
+		r.count++
+	}
+}
+func (r *patternCountRule) Result() Result {
+	passed := r.op.compare(r.count, r.want)
+	res := Result{Name: r.name, Actual: r.count, Expected: r.want, Passed: passed}
+	if !passed {
+		res.FailureMessage = fmt.Sprintf("pattern %q count %d does not satisfy %s %d", r.regex.String(), r.count, r.op, r.want)
+	}
+	return res
+}
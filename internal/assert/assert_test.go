@@ -0,0 +1,95 @@
+package assert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+func entries() []domain.LogEntry {
+	base := time.Now()
+	return []domain.LogEntry{
+		{Timestamp: base, Level: domain.LogLevelInfo, Message: "DidFinishLaunching"},
+		{Timestamp: base.Add(time.Second), Level: domain.LogLevelError, Message: "network blip"},
+		{Timestamp: base.Add(2 * time.Second), Level: domain.LogLevelFault, Message: "Thread 4 Crashed"},
+	}
+}
+
+func TestBuildAndRun_MaxErrors(t *testing.T) {
+	zero := 0
+	rules, err := Build([]RuleConfig{{Name: "no_errors", MaxErrors: &zero}})
+	require.NoError(t, err)
+
+	summary := Run(rules, entries())
+	assert.False(t, summary.Passed)
+	require.Len(t, summary.Results, 1)
+	assert.Equal(t, 1, summary.Results[0].Actual)
+}
+
+func TestMustContainAndMustNotContain(t *testing.T) {
+	rules, err := Build([]RuleConfig{
+		{Name: "has_launch", MustContain: "DidFinishLaunching"},
+		{Name: "no_inconsistency", MustNotContain: "NSInternalInconsistencyException"},
+	})
+	require.NoError(t, err)
+
+	summary := Run(rules, entries())
+	assert.True(t, summary.Passed)
+}
+
+func TestPatternCountRule(t *testing.T) {
+	rules, err := Build([]RuleConfig{
+		{Name: "crashes", PatternCount: &PatternCountConfig{Regex: `Thread \d+ Crashed`, Op: "==", Value: 1}},
+	})
+	require.NoError(t, err)
+
+	summary := Run(rules, entries())
+	assert.True(t, summary.Passed)
+}
+
+func TestMaxDurationBetweenRule(t *testing.T) {
+	rules, err := Build([]RuleConfig{
+		{Name: "tight_gap", MaxDurationBetween: "500ms"},
+	})
+	require.NoError(t, err)
+
+	summary := Run(rules, entries())
+	assert.False(t, summary.Passed)
+	assert.Contains(t, summary.Results[0].FailureMessage, "exceeds max")
+}
+
+func TestParseFlag(t *testing.T) {
+	cfg, err := ParseFlag("max_errors:0")
+	require.NoError(t, err)
+	require.NotNil(t, cfg.MaxErrors)
+	assert.Equal(t, 0, *cfg.MaxErrors)
+
+	_, err = ParseFlag("not-a-valid-flag")
+	assert.Error(t, err)
+
+	_, err = ParseFlag("unknown_key:1")
+	assert.Error(t, err)
+}
+
+func TestParseFlagPatternCount(t *testing.T) {
+	cfg, err := ParseFlag(`pattern_count{regex:"Thread \d+ Crashed", op:"=="}: 0`)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.PatternCount)
+	assert.Equal(t, `Thread \d+ Crashed`, cfg.PatternCount.Regex)
+	assert.Equal(t, "==", cfg.PatternCount.Op)
+	assert.Equal(t, 0, cfg.PatternCount.Value)
+
+	_, err = ParseFlag(`pattern_count{op:"=="}: 0`)
+	assert.Error(t, err)
+
+	_, err = ParseFlag(`pattern_count{regex:"x"}: not-a-number`)
+	assert.Error(t, err)
+}
+
+func TestBuildUnknownRule(t *testing.T) {
+	_, err := Build([]RuleConfig{{Name: "empty"}})
+	assert.Error(t, err)
+}
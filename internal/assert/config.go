@@ -0,0 +1,142 @@
+package assert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// patternCountFlagRe matches the pattern_count{...}: value form documented
+// for --assert, e.g. `pattern_count{regex:"Thread \d+ Crashed", op:"=="}: 0`.
+var patternCountFlagRe = regexp.MustCompile(`^pattern_count\{(.*)\}\s*:\s*(.*)$`)
+
+// LoadFile reads a YAML or JSON assertion file into a list of RuleConfig.
+// The file may be either a bare list of rules or an object with a "rules" key.
+func LoadFile(path string) ([]RuleConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading assertion file: %w", err)
+	}
+
+	var wrapper struct {
+		Rules []RuleConfig `json:"rules" yaml:"rules"`
+	}
+
+	unmarshal := yaml.Unmarshal
+	if strings.HasSuffix(path, ".json") {
+		unmarshal = func(b []byte, v interface{}) error { return json.Unmarshal(b, v) }
+	}
+
+	if err := unmarshal(data, &wrapper); err == nil && len(wrapper.Rules) > 0 {
+		return wrapper.Rules, nil
+	}
+
+	var bare []RuleConfig
+	if err := unmarshal(data, &bare); err != nil {
+		return nil, fmt.Errorf("parsing assertion file %s: %w", path, err)
+	}
+	return bare, nil
+}
+
+// ParseFlag parses a single `--assert key:value` style flag such as
+// "max_errors:0" or "must_contain:DidFinishLaunching" into a RuleConfig.
+func ParseFlag(flag string) (RuleConfig, error) {
+	if m := patternCountFlagRe.FindStringSubmatch(flag); m != nil {
+		return parsePatternCountFlag(m[1], m[2])
+	}
+
+	key, value, ok := strings.Cut(flag, ":")
+	if !ok {
+		return RuleConfig{}, fmt.Errorf("invalid --assert flag %q, expected key:value", flag)
+	}
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+
+	cfg := RuleConfig{Name: key}
+	switch key {
+	case "max_errors":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return RuleConfig{}, fmt.Errorf("invalid max_errors value %q: %w", value, err)
+		}
+		cfg.MaxErrors = &n
+	case "max_faults":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return RuleConfig{}, fmt.Errorf("invalid max_faults value %q: %w", value, err)
+		}
+		cfg.MaxFaults = &n
+	case "must_contain":
+		cfg.MustContain = value
+	case "must_not_contain":
+		cfg.MustNotContain = value
+	case "max_duration_between":
+		cfg.MaxDurationBetween = value
+	default:
+		return RuleConfig{}, fmt.Errorf("unsupported --assert key %q", key)
+	}
+	return cfg, nil
+}
+
+// parsePatternCountFlag builds a pattern_count rule from the contents of the
+// `pattern_count{...}` braces and the value after the top-level colon, e.g.
+// params=`regex:"Thread \d+ Crashed", op:"=="` and value=`0`.
+func parsePatternCountFlag(params, value string) (RuleConfig, error) {
+	pc := &PatternCountConfig{}
+	for _, part := range splitTopLevelCommas(params) {
+		k, v, ok := strings.Cut(part, ":")
+		if !ok {
+			return RuleConfig{}, fmt.Errorf("invalid pattern_count parameter %q, expected key:value", strings.TrimSpace(part))
+		}
+		k = strings.TrimSpace(k)
+		v = strings.Trim(strings.TrimSpace(v), `"`)
+		switch k {
+		case "regex":
+			pc.Regex = v
+		case "op":
+			pc.Op = v
+		default:
+			return RuleConfig{}, fmt.Errorf("unsupported pattern_count parameter %q", k)
+		}
+	}
+	if pc.Regex == "" {
+		return RuleConfig{}, fmt.Errorf("pattern_count requires a regex parameter")
+	}
+	if pc.Op == "" {
+		return RuleConfig{}, fmt.Errorf("pattern_count requires an op parameter")
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return RuleConfig{}, fmt.Errorf("invalid pattern_count value %q: %w", value, err)
+	}
+	pc.Value = n
+
+	return RuleConfig{Name: "pattern_count", PatternCount: pc}, nil
+}
+
+// splitTopLevelCommas splits s on commas that fall outside double-quoted
+// spans, so a regex parameter like "a,b" isn't split mid-pattern.
+func splitTopLevelCommas(s string) []string {
+	var parts []string
+	var inQuotes bool
+	start := 0
+	for i, r := range s {
+		switch r {
+		case '"':
+			inQuotes = !inQuotes
+		case ',':
+			if !inQuotes {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+	return parts
+}
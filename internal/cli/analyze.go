@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/vburojevic/xcw/internal/assert"
+	"github.com/vburojevic/xcw/internal/domain"
+	"github.com/vburojevic/xcw/internal/output"
+)
+
+// AnalyzeCmd reads an NDJSON log file and reports aggregate statistics,
+// optionally persisting learned patterns and gating on a set of assertions.
+type AnalyzeCmd struct {
+	File            string   `arg:"" optional:"" help:"Path to an NDJSON log file to analyze (omit or use '-' with --stream to read stdin)"`
+	PersistPatterns bool     `help:"Persist learned patterns across runs"`
+	PatternFile     string   `help:"Path to the pattern store file (default: ~/.xcw/patterns.json)"`
+	AssertFile      string   `help:"Path to a YAML/JSON assertion file to evaluate against the log"`
+	Assert          []string `help:"Inline assertion in key:value form (e.g. max_errors:0), repeatable"`
+
+	Stream     bool   `help:"Read NDJSON from stdin and emit incremental analysis_tick events"`
+	EmitEvery  string `help:"Emit an analysis_tick at most this often (duration, e.g. 5s)" default:"5s"`
+	EmitEveryN int    `help:"Emit an analysis_tick after this many new entries" default:"50"`
+}
+
+type analysisSummary struct {
+	TotalEntries int `json:"total_entries"`
+	Debug        int `json:"debug"`
+	Info         int `json:"info"`
+	Default      int `json:"default"`
+	Errors       int `json:"errors"`
+	Faults       int `json:"faults"`
+}
+
+type analysisOutput struct {
+	Type              string          `json:"type"`
+	SchemaVersion     int             `json:"schemaVersion"`
+	File              string          `json:"file"`
+	Summary           analysisSummary `json:"summary"`
+	NewPatternCount   int             `json:"new_pattern_count,omitempty"`
+	KnownPatternCount int             `json:"known_pattern_count,omitempty"`
+	Assertions        *assert.Summary `json:"assertions,omitempty"`
+}
+
+// Run executes the analyze command.
+func (c *AnalyzeCmd) Run(globals *Globals) error {
+	if c.Stream {
+		return c.runStream(globals)
+	}
+
+	entries, err := readNDJSONLogEntries(c.File)
+	if err != nil {
+		return outputErrorCommon(globals, "ANALYZE_FAILED", err.Error())
+	}
+	if len(entries) == 0 {
+		return outputErrorCommon(globals, "NO_VALID_ENTRIES", fmt.Sprintf("no valid log entries found in %s", c.File))
+	}
+
+	summary := summarizeEntries(entries)
+
+	out := analysisOutput{
+		Type:          "analysis",
+		SchemaVersion: output.SchemaVersion,
+		File:          c.File,
+		Summary:       summary,
+	}
+
+	if c.PersistPatterns {
+		store := output.NewPatternStore(c.PatternFile)
+		matches := extractPatterns(entries)
+		enhanced := store.RecordPatterns(matches)
+		for _, m := range enhanced {
+			if m.IsNew {
+				out.NewPatternCount++
+			} else {
+				out.KnownPatternCount++
+			}
+		}
+		if err := store.Save(); err != nil {
+			return outputErrorCommon(globals, "PATTERN_SAVE_FAILED", err.Error())
+		}
+	}
+
+	assertSummary, err := c.runAssertions(entries)
+	if err != nil {
+		return outputErrorCommon(globals, "INVALID_ASSERTIONS", err.Error())
+	}
+	out.Assertions = assertSummary
+
+	if globals.Format == "ndjson" {
+		if err := json.NewEncoder(globals.Stdout).Encode(out); err != nil {
+			return err
+		}
+	} else {
+		c.printText(globals, out)
+	}
+
+	if assertSummary != nil && !assertSummary.Passed {
+		return outputErrorCommon(globals, "ASSERTIONS_FAILED", "one or more log assertions failed")
+	}
+	return nil
+}
+
+// runAssertions builds and evaluates the configured assertion rules, if any.
+func (c *AnalyzeCmd) runAssertions(entries []domain.LogEntry) (*assert.Summary, error) {
+	if c.AssertFile == "" && len(c.Assert) == 0 {
+		return nil, nil
+	}
+
+	var configs []assert.RuleConfig
+	if c.AssertFile != "" {
+		fileConfigs, err := assert.LoadFile(c.AssertFile)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, fileConfigs...)
+	}
+	for _, a := range c.Assert {
+		cfg, err := assert.ParseFlag(a)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, cfg)
+	}
+
+	rules, err := assert.Build(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := assert.Run(rules, entries)
+	return &summary, nil
+}
+
+func (c *AnalyzeCmd) printText(globals *Globals, out analysisOutput) {
+	fmt.Fprintf(globals.Stdout, "Analysis of %s\n", out.File)
+	fmt.Fprintf(globals.Stdout, "Total entries: %d\n", out.Summary.TotalEntries)
+	fmt.Fprintf(globals.Stdout, "  Debug:   %d\n", out.Summary.Debug)
+	fmt.Fprintf(globals.Stdout, "  Info:    %d\n", out.Summary.Info)
+	fmt.Fprintf(globals.Stdout, "  Default: %d\n", out.Summary.Default)
+	fmt.Fprintf(globals.Stdout, "  Error:   %d\n", out.Summary.Errors)
+	fmt.Fprintf(globals.Stdout, "  Fault:   %d\n", out.Summary.Faults)
+
+	if out.Assertions != nil {
+		fmt.Fprintln(globals.Stdout)
+		status := "PASSED"
+		if !out.Assertions.Passed {
+			status = "FAILED"
+		}
+		fmt.Fprintf(globals.Stdout, "Assertions: %s (%d/%d passed)\n", status, out.Assertions.Total-out.Assertions.Failed, out.Assertions.Total)
+		for _, r := range out.Assertions.Results {
+			mark := "ok"
+			if !r.Passed {
+				mark = "FAIL"
+			}
+			fmt.Fprintf(globals.Stdout, "  [%s] %s: %v (expected %v)\n", mark, r.Name, r.Actual, r.Expected)
+			if r.FailureMessage != "" {
+				fmt.Fprintf(globals.Stdout, "        %s\n", r.FailureMessage)
+			}
+		}
+	}
+}
+
+func readNDJSONLogEntries(path string) ([]domain.LogEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var entries []domain.LogEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry domain.LogEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func summarizeEntries(entries []domain.LogEntry) analysisSummary {
+	var s analysisSummary
+	for i := range entries {
+		accumulate(&s, &entries[i])
+	}
+	return s
+}
+
+func extractPatterns(entries []domain.LogEntry) []output.PatternMatch {
+	counts := map[string]*output.PatternMatch{}
+	order := make([]string, 0)
+	for _, e := range entries {
+		m, ok := counts[e.Message]
+		if !ok {
+			m = &output.PatternMatch{Pattern: e.Message}
+			counts[e.Message] = m
+			order = append(order, e.Message)
+		}
+		m.Count++
+		if len(m.Samples) < 3 {
+			m.Samples = append(m.Samples, e.Message)
+		}
+	}
+	sort.Strings(order)
+	matches := make([]output.PatternMatch, 0, len(order))
+	for _, k := range order {
+		matches = append(matches, *counts[k])
+	}
+	return matches
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/vburojevic/xcw/internal/domain"
+	"github.com/vburojevic/xcw/internal/output"
+)
+
+// newPatternThreshold is the occurrence count at which a previously-rare
+// pattern is considered worth a real-time novelty signal.
+const newPatternThreshold = 1
+
+// analysisTick is emitted periodically while AnalyzeCmd is streaming.
+type analysisTick struct {
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schemaVersion"`
+	Summary       analysisSummary `json:"summary"`
+}
+
+// newPatternEvent fires the first time a pattern crosses newPatternThreshold.
+type newPatternEvent struct {
+	Type          string `json:"type"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Pattern       string `json:"pattern"`
+	Count         int    `json:"count"`
+}
+
+// patternAnomalyEvent fires when a pattern's rate spikes above its learned
+// baseline, or a long-dormant pattern suddenly resumes firing.
+type patternAnomalyEvent struct {
+	Type          string `json:"type"`
+	SchemaVersion int    `json:"schemaVersion"`
+	output.PatternAnomaly
+}
+
+// runStream consumes NDJSON entries from stdin (or c.File, if it names a
+// real path rather than "-"/empty) and emits analysis_tick events on a
+// rolling window, plus new_pattern events in real time, finishing with a
+// full "analysis" summary on EOF.
+func (c *AnalyzeCmd) runStream(globals *Globals) error {
+	var r io.Reader = os.Stdin
+	if c.File != "" && c.File != "-" {
+		f, err := os.Open(c.File)
+		if err != nil {
+			return outputErrorCommon(globals, "ANALYZE_FAILED", err.Error())
+		}
+		defer f.Close()
+		r = f
+	}
+
+	interval, err := time.ParseDuration(c.EmitEvery)
+	if err != nil {
+		return outputErrorCommon(globals, "INVALID_INTERVAL", fmt.Sprintf("invalid --emit-every: %s", err))
+	}
+	emitEveryN := c.EmitEveryN
+	if emitEveryN <= 0 {
+		emitEveryN = 50
+	}
+
+	var store *output.PatternStore
+	if c.PersistPatterns {
+		store = output.NewPatternStore(c.PatternFile)
+	}
+
+	var summary analysisSummary
+	enc := json.NewEncoder(globals.Stdout)
+	lastEmit := time.Now()
+	sinceEmit := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry domain.LogEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+
+		accumulate(&summary, &entry)
+		sinceEmit++
+
+		if store != nil {
+			wasNew := store.RecordPattern(entry.Message, 1)
+			info := store.GetPattern(entry.Message)
+			if wasNew || (info != nil && info.TotalCount == newPatternThreshold) {
+				_ = enc.Encode(newPatternEvent{
+					Type:          "new_pattern",
+					SchemaVersion: output.SchemaVersion,
+					Pattern:       entry.Message,
+					Count:         info.TotalCount,
+				})
+			}
+			if anomaly, ok := store.CheckAnomaly(entry.Message); ok {
+				_ = enc.Encode(patternAnomalyEvent{
+					Type:           "pattern_anomaly",
+					SchemaVersion:  output.SchemaVersion,
+					PatternAnomaly: anomaly,
+				})
+			}
+		}
+
+		if sinceEmit >= emitEveryN || time.Since(lastEmit) >= interval {
+			_ = enc.Encode(analysisTick{Type: "analysis_tick", SchemaVersion: output.SchemaVersion, Summary: summary})
+			sinceEmit = 0
+			lastEmit = time.Now()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return outputErrorCommon(globals, "ANALYZE_FAILED", err.Error())
+	}
+
+	if store != nil {
+		if err := store.Save(); err != nil {
+			return outputErrorCommon(globals, "PATTERN_SAVE_FAILED", err.Error())
+		}
+	}
+
+	return enc.Encode(analysisOutput{
+		Type:          "analysis",
+		SchemaVersion: output.SchemaVersion,
+		File:          c.File,
+		Summary:       summary,
+	})
+}
+
+func accumulate(s *analysisSummary, e *domain.LogEntry) {
+	s.TotalEntries++
+	switch e.Level {
+	case domain.LogLevelDebug:
+		s.Debug++
+	case domain.LogLevelInfo:
+		s.Info++
+	case domain.LogLevelError:
+		s.Errors++
+	case domain.LogLevelFault:
+		s.Faults++
+	default:
+		s.Default++
+	}
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vburojevic/xcw/internal/config"
+)
+
+func TestAnalyzeCmd_StreamEmitsTicksAndSummary(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "test.ndjson")
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, enc.Encode(map[string]any{"level": "Error", "message": "boom"}))
+	}
+	require.NoError(t, writeFileHelper(logFile, buf.Bytes()))
+
+	stdout := &bytes.Buffer{}
+	globals := &Globals{Format: "ndjson", Stdout: stdout, Stderr: &bytes.Buffer{}, Config: config.Default()}
+	cmd := &AnalyzeCmd{File: logFile, Stream: true, EmitEvery: "1h", EmitEveryN: 1}
+
+	require.NoError(t, cmd.Run(globals))
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.NotEmpty(t, lines)
+
+	var last map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &last))
+	assert.Equal(t, "analysis", last["type"])
+}
@@ -337,6 +337,56 @@ func TestAnalyzeCmd_Run(t *testing.T) {
 		assert.Contains(t, result, "new_pattern_count")
 		assert.Contains(t, result, "known_pattern_count")
 	})
+
+	t.Run("fails assertions via --assert in text format", func(t *testing.T) {
+		globals, stdout, _ := testGlobals("text")
+		cmd := &AnalyzeCmd{File: logFile, Assert: []string{"max_errors:0"}}
+
+		err := cmd.Run(globals)
+		assert.Error(t, err)
+
+		output := stdout.String()
+		assert.Contains(t, output, "Assertions: FAILED")
+		assert.Contains(t, output, "max_errors")
+	})
+
+	t.Run("fails assertions via --assert in NDJSON format", func(t *testing.T) {
+		globals, stdout, _ := testGlobals("ndjson")
+		cmd := &AnalyzeCmd{File: logFile, Assert: []string{"max_errors:0"}}
+
+		err := cmd.Run(globals)
+		assert.Error(t, err)
+
+		// The analysis object comes first; the ASSERTIONS_FAILED error
+		// object follows it on the next line.
+		var result map[string]interface{}
+		err = json.NewDecoder(stdout).Decode(&result)
+		require.NoError(t, err)
+
+		assertions, ok := result["assertions"].(map[string]interface{})
+		require.True(t, ok, "expected an assertions object in %v", result)
+		assert.Equal(t, false, assertions["passed"])
+	})
+
+	t.Run("passes assertions via --assert-file", func(t *testing.T) {
+		assertFile := filepath.Join(tmpDir, "assertions.yaml")
+		yaml := "rules:\n  - name: max_errors\n    max_errors: 10\n"
+		require.NoError(t, os.WriteFile(assertFile, []byte(yaml), 0644))
+
+		globals, stdout, _ := testGlobals("ndjson")
+		cmd := &AnalyzeCmd{File: logFile, AssertFile: assertFile}
+
+		err := cmd.Run(globals)
+		require.NoError(t, err)
+
+		var result map[string]interface{}
+		err = json.Unmarshal(stdout.Bytes(), &result)
+		require.NoError(t, err)
+
+		assertions, ok := result["assertions"].(map[string]interface{})
+		require.True(t, ok, "expected an assertions object in %v", result)
+		assert.Equal(t, true, assertions["passed"])
+	})
 }
 
 // --- Replay Command Tests ---
@@ -10,7 +10,9 @@ import (
 
 // CompletionCmd generates shell completions
 type CompletionCmd struct {
-	Shell string `arg:"" enum:"bash,zsh,fish" help:"Shell type (bash, zsh, fish)"`
+	Shell string `arg:"" enum:"bash,zsh,fish,pwsh,nu" help:"Shell type (bash, zsh, fish, pwsh, nu)"`
+
+	Install CompletionInstallCmd `cmd:"" help:"Install completions into your shell's startup file"`
 }
 
 type completionNode struct {
@@ -41,6 +43,10 @@ func (c *CompletionCmd) Run(globals *Globals, ctx *kong.Context) error {
 		return c.generateZsh(globals, idx)
 	case "fish":
 		return c.generateFish(globals, idx)
+	case "pwsh":
+		return c.generatePwsh(globals, idx)
+	case "nu":
+		return c.generateNu(globals, idx)
 	default:
 		return fmt.Errorf("unsupported shell: %s", c.Shell)
 	}
@@ -209,10 +215,17 @@ func (c *CompletionCmd) generateBash(globals *Globals, idx completionIndex) erro
 # Add to ~/.bashrc or ~/.bash_profile:
 #   eval "$(xcw completion bash)"
 
-_xcw_complete_simulators() {
-    local sims
-    sims=$(xcrun simctl list devices booted -j 2>/dev/null | grep '"name"' | cut -d'"' -f4 | tr '\n' ' ')
-    COMPREPLY=($(compgen -W "booted ${sims}" -- "${cur}"))
+# _xcw_dynamic calls the hidden "xcw __complete" backend so simulator
+# names/UDIDs and installed bundle IDs stay correct without this script
+# shelling out to simctl itself. Entries come back NUL-separated, each as
+# "value<TAB>description"; we only need the value for COMPREPLY.
+_xcw_dynamic() {
+    local kind="$1"
+    shift
+    COMPREPLY=()
+    while IFS=$'\t' read -r -d '' value _ || [[ -n "${value}" ]]; do
+        COMPREPLY+=("${value}")
+    done < <(xcw __complete "${kind}" "$@" "${cur}" 2>/dev/null)
 }
 
 _xcw_is_cmdpath() {
@@ -260,7 +273,11 @@ _xcw_completions() {
 
     case "${prev}" in
         -s|--simulator)
-            _xcw_complete_simulators
+            _xcw_dynamic simulator
+            return
+            ;;
+        -a|--app)
+            _xcw_dynamic app ""
             return
             ;;
 `)
@@ -343,10 +360,19 @@ func (c *CompletionCmd) generateZsh(globals *Globals, idx completionIndex) error
 # Add to ~/.zshrc:
 #   eval "$(xcw completion zsh)"
 
-_xcw_complete_simulators() {
-  local -a sims
-  sims=(booted ${(f)"$(xcrun simctl list devices booted -j 2>/dev/null | grep '\"name\"' | cut -d'\"' -f4)"})
-  _describe 'simulator' sims
+_xcw_dynamic() {
+  local kind="$1"
+  shift
+  local -a matches
+  local value desc
+  while IFS=$'\t' read -r -d $'\0' value desc || [[ -n "${value}" ]]; do
+    if [[ -n "${desc}" ]]; then
+      matches+=("${value}:${desc}")
+    else
+      matches+=("${value}")
+    fi
+  done < <(xcw __complete "${kind}" "$@" "${cur}" 2>/dev/null)
+  _describe "${kind}" matches
 }
 
 _xcw_is_cmdpath() {
@@ -391,7 +417,11 @@ _xcw() {
 
   case "${prev}" in
     -s|--simulator)
-      _xcw_complete_simulators
+      _xcw_dynamic simulator
+      return
+      ;;
+    -a|--app)
+      _xcw_dynamic app ""
       return
       ;;
 `)
@@ -462,6 +492,179 @@ compdef _xcw xcw
 	return err
 }
 
+func (c *CompletionCmd) generatePwsh(globals *Globals, idx completionIndex) error {
+	// Top-level commands and the global flags that take enum values only (fish
+	// also lists the other global flags), kept simple since PowerShell's native
+	// completer has to run on every keystroke.
+	var sb strings.Builder
+	sb.WriteString(`# xcw PowerShell completion script
+# Add to $PROFILE:
+#   Invoke-Expression (xcw completion pwsh | Out-String)
+
+Register-ArgumentCompleter -Native -CommandName xcw -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $subcommands = @(
+`)
+	root := idx.Nodes[""]
+	for _, cmd := range root.Subcommands {
+		sb.WriteString("        '")
+		sb.WriteString(cmd)
+		sb.WriteString("'\n")
+	}
+	sb.WriteString("    )\n\n    $flags = @{\n")
+	for _, flag := range root.Flags {
+		if !strings.HasPrefix(flag, "--") {
+			continue
+		}
+		values, hasEnum := idx.EnumByFlag[flag]
+		if !hasEnum || len(values) == 0 {
+			continue
+		}
+		sb.WriteString("        '")
+		sb.WriteString(flag)
+		sb.WriteString("' = @(")
+		sb.WriteString(quotePwshList(values))
+		sb.WriteString(")\n")
+	}
+	sb.WriteString(`    }
+
+    $prev = $commandAst.CommandElements | Select-Object -Last 2 -First 1 | ForEach-Object { $_.ToString() }
+
+    # Dynamic values (simulator names/UDIDs, installed bundle IDs) come from
+    # the hidden "xcw __complete" backend instead of a Register-ArgumentCompleter
+    # tied to a specific xcrun pipeline, so they stay correct as simulators
+    # come and go.
+    if ($prev -eq '-s' -or $prev -eq '--simulator') {
+        (xcw __complete simulator $wordToComplete) -split "\0" | ForEach-Object {
+            $parts = $_ -split "\t", 2
+            [System.Management.Automation.CompletionResult]::new($parts[0], $parts[0], 'ParameterValue', $(if ($parts.Count -gt 1) { $parts[1] } else { $parts[0] }))
+        }
+        return
+    }
+    if ($prev -eq '-a' -or $prev -eq '--app') {
+        (xcw __complete app '' $wordToComplete) -split "\0" | ForEach-Object {
+            $parts = $_ -split "\t", 2
+            [System.Management.Automation.CompletionResult]::new($parts[0], $parts[0], 'ParameterValue', $(if ($parts.Count -gt 1) { $parts[1] } else { $parts[0] }))
+        }
+        return
+    }
+
+    if ($flags.ContainsKey($prev)) {
+        $flags[$prev] | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+            [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+        }
+        return
+    }
+
+    $candidates = if ($wordToComplete -like '-*') { $flags.Keys } else { $subcommands }
+    $candidates | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`)
+
+	_, err := fmt.Fprint(globals.Stdout, sb.String())
+	return err
+}
+
+func (c *CompletionCmd) generateNu(globals *Globals, idx completionIndex) error {
+	// Same top-level-only scope as the fish/pwsh generators.
+	var sb strings.Builder
+	sb.WriteString(`# xcw Nushell completion script
+# Add to your config.nu:
+#   source ~/.config/nushell/completions/xcw.nu
+
+def "nu-complete xcw subcommands" [] {
+    [
+`)
+	root := idx.Nodes[""]
+	for _, cmd := range root.Subcommands {
+		sb.WriteString("        \"")
+		sb.WriteString(cmd)
+		sb.WriteString("\"\n")
+	}
+	sb.WriteString(`    ]
+}
+
+export extern "xcw" [
+`)
+	for _, flag := range root.Flags {
+		if !strings.HasPrefix(flag, "--") {
+			continue
+		}
+		long := strings.TrimPrefix(flag, "--")
+		switch {
+		case long == "simulator" || long == "app":
+			sb.WriteString("    --")
+			sb.WriteString(long)
+			sb.WriteString(": string@\"nu-complete xcw ")
+			sb.WriteString(long)
+			sb.WriteString("\"\n")
+		default:
+			if values, ok := idx.EnumByFlag[flag]; ok && len(values) > 0 {
+				sb.WriteString("    --")
+				sb.WriteString(long)
+				sb.WriteString(": string@\"nu-complete xcw ")
+				sb.WriteString(long)
+				sb.WriteString("\"\n")
+				continue
+			}
+			sb.WriteString("    --")
+			sb.WriteString(long)
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString(`    ...args: string@"nu-complete xcw subcommands"
+]
+
+# Dynamic values (simulator names/UDIDs, installed bundle IDs) are delegated
+# to the hidden "xcw __complete" backend rather than re-implemented in nu.
+def "nu-complete xcw simulator" [] {
+    (xcw __complete simulator | split row (char nul) | where {|x| $x != "" } | each {|x| $x | split column "\t" value description | first })
+}
+
+def "nu-complete xcw app" [] {
+    (xcw __complete app "" | split row (char nul) | where {|x| $x != "" } | each {|x| $x | split column "\t" value description | first })
+}
+
+`)
+	for _, flag := range root.Flags {
+		if !strings.HasPrefix(flag, "--") {
+			continue
+		}
+		values, ok := idx.EnumByFlag[flag]
+		if !ok || len(values) == 0 {
+			continue
+		}
+		long := strings.TrimPrefix(flag, "--")
+		sb.WriteString("def \"nu-complete xcw ")
+		sb.WriteString(long)
+		sb.WriteString("\" [] {\n    [")
+		sb.WriteString(quoteNuList(values))
+		sb.WriteString("]\n}\n\n")
+	}
+
+	_, err := fmt.Fprint(globals.Stdout, sb.String())
+	return err
+}
+
+func quotePwshList(values []string) string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, "'"+v+"'")
+	}
+	return strings.Join(out, ", ")
+}
+
+func quoteNuList(values []string) string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, "\""+v+"\"")
+	}
+	return strings.Join(out, " ")
+}
+
 func (c *CompletionCmd) generateFish(globals *Globals, idx completionIndex) error {
 	var sb strings.Builder
 	sb.WriteString(`# xcw fish completion script
@@ -501,8 +704,12 @@ complete -c xcw -f
 	}
 
 	sb.WriteString(`
-# Simulator completion (booted)
-complete -c xcw -n "__fish_contains_opt -s s simulator" -a "(xcrun simctl list devices booted -j 2>/dev/null | grep '\"name\"' | cut -d'\"' -f4; echo booted)"
+# Simulator/app completion, backed by "xcw __complete" so values (names,
+# UDIDs, installed bundle IDs, recent history) stay in sync with Go instead
+# of this script shelling out to simctl itself. "string split0" turns the
+# NUL-separated entries back into one candidate per line.
+complete -c xcw -n "__fish_contains_opt -s s simulator" -a "(xcw __complete simulator | string split0)"
+complete -c xcw -n "__fish_contains_opt -s a app" -a "(xcw __complete app '' | string split0)"
 `)
 
 	_, err := fmt.Fprint(globals.Stdout, sb.String())
@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CompleteCmd is the hidden backend the generated bash/zsh/fish/pwsh
+// completion scripts shell out to for anything that can't be known at
+// script-generation time: booted/shutdown simulators, installed bundle IDs,
+// and recently-used values. Keeping this logic in Go means the shell
+// templates stay thin wrappers instead of each re-implementing their own
+// `xcrun simctl` pipelines.
+//
+// It is registered on the root CLI as `__complete` with `hidden:""` so it
+// doesn't show up in `xcw --help` or `xcw help --json`.
+type CompleteCmd struct {
+	Kind string   `arg:"" help:"Completion kind: simulator, udid, app, format, level, history"`
+	Args []string `arg:"" optional:"" help:"Extra arguments for the kind (e.g. a UDID for 'app', then a prefix to filter by)"`
+}
+
+// completionEntry is one candidate plus an optional human-readable
+// description. Shells that support it (zsh, fish, pwsh) show the
+// description alongside the value; others just see Value.
+type completionEntry struct {
+	Value       string
+	Description string
+}
+
+// Run executes the completion backend. Output is a sequence of
+// "value\tdescription" records separated by NUL bytes (never newlines,
+// since simulator/app names can legitimately contain them) so the shell
+// wrappers can split reliably with read -d '' / IFS tricks.
+func (c *CompleteCmd) Run(globals *Globals) error {
+	prefix := ""
+	if len(c.Args) > 0 {
+		prefix = c.Args[len(c.Args)-1]
+	}
+
+	var (
+		entries []completionEntry
+		err     error
+	)
+
+	switch c.Kind {
+	case "simulator", "udid":
+		entries, err = completeSimulators()
+	case "app":
+		udid := ""
+		if len(c.Args) > 1 {
+			udid = c.Args[0]
+		}
+		entries, err = completeApps(udid)
+	case "format":
+		entries = []completionEntry{
+			{Value: "text", Description: "human-readable output"},
+			{Value: "ndjson", Description: "newline-delimited JSON"},
+		}
+	case "level":
+		entries = []completionEntry{
+			{Value: "debug"},
+			{Value: "info"},
+			{Value: "default"},
+			{Value: "error"},
+			{Value: "fault"},
+		}
+	case "history":
+		key := ""
+		if len(c.Args) > 0 {
+			key = c.Args[0]
+		}
+		if len(c.Args) < 2 {
+			// The only argument is the history key, not a prefix.
+			prefix = ""
+		}
+		entries, err = loadCompletionHistory(key)
+	default:
+		err = fmt.Errorf("unknown completion kind: %s", c.Kind)
+	}
+
+	if err != nil {
+		// Completion is best-effort: a broken simctl call shouldn't break
+		// the user's shell, it should just produce no completions.
+		globals.Debug("completion %s failed: %v", c.Kind, err)
+		return nil
+	}
+
+	writeCompletionEntries(globals, filterCompletionEntries(entries, prefix))
+	return nil
+}
+
+func filterCompletionEntries(entries []completionEntry, prefix string) []completionEntry {
+	if prefix == "" {
+		return entries
+	}
+	out := make([]completionEntry, 0, len(entries))
+	for _, e := range entries {
+		if strings.HasPrefix(e.Value, prefix) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func writeCompletionEntries(globals *Globals, entries []completionEntry) {
+	for i, e := range entries {
+		if i > 0 {
+			fmt.Fprint(globals.Stdout, "\x00")
+		}
+		fmt.Fprint(globals.Stdout, e.Value)
+		if e.Description != "" {
+			fmt.Fprint(globals.Stdout, "\t", e.Description)
+		}
+	}
+}
+
+// simctlDevice mirrors the subset of `xcrun simctl list devices --json`
+// we care about.
+type simctlDevice struct {
+	Name  string `json:"name"`
+	UDID  string `json:"udid"`
+	State string `json:"state"`
+}
+
+type simctlDeviceList struct {
+	Devices map[string][]simctlDevice `json:"devices"`
+}
+
+func completeSimulators() ([]completionEntry, error) {
+	entries := []completionEntry{{Value: "booted", Description: "first booted simulator"}}
+	if recent, err := loadCompletionHistory("simulator"); err == nil {
+		entries = append(entries, recent...)
+	}
+
+	out, err := exec.Command("xcrun", "simctl", "list", "devices", "--json").Output()
+	if err != nil {
+		// Still return history/the "booted" alias - a missing/broken xcrun
+		// shouldn't wipe out otherwise-useful completions.
+		return entries, nil
+	}
+
+	var list simctlDeviceList
+	if err := json.Unmarshal(out, &list); err != nil {
+		return entries, nil
+	}
+
+	runtimes := make([]string, 0, len(list.Devices))
+	for runtime := range list.Devices {
+		runtimes = append(runtimes, runtime)
+	}
+	sort.Strings(runtimes)
+
+	for _, runtime := range runtimes {
+		for _, d := range list.Devices[runtime] {
+			entries = append(entries,
+				completionEntry{Value: d.Name, Description: fmt.Sprintf("%s (%s)", runtimeShortName(runtime), d.State)},
+				completionEntry{Value: d.UDID, Description: fmt.Sprintf("%s - %s (%s)", d.Name, runtimeShortName(runtime), d.State)},
+			)
+		}
+	}
+	return entries, nil
+}
+
+func runtimeShortName(identifier string) string {
+	// com.apple.CoreSimulator.SimRuntime.iOS-17-0 -> iOS-17-0
+	idx := strings.LastIndex(identifier, ".")
+	if idx < 0 || idx == len(identifier)-1 {
+		return identifier
+	}
+	return identifier[idx+1:]
+}
+
+// bundleBlockRe matches one `"<id>" = { ... };` stanza from the legacy
+// plist-ish text `xcrun simctl listapps <udid>` prints (there is no --json
+// form of this subcommand).
+var bundleBlockRe = regexp.MustCompile(`(?ms)^\s*"([^"]+)"\s*=\s*\{(.*?)^\s*\};`)
+var bundleDisplayNameRe = regexp.MustCompile(`CFBundleDisplayName\s*=\s*"?([^;"]+)"?;`)
+
+func completeApps(udid string) ([]completionEntry, error) {
+	var entries []completionEntry
+	if recent, err := loadCompletionHistory("app"); err == nil {
+		entries = append(entries, recent...)
+	}
+
+	if strings.TrimSpace(udid) == "" {
+		udid = "booted"
+	}
+	out, err := exec.Command("xcrun", "simctl", "listapps", udid).Output()
+	if err != nil {
+		// A simulator that isn't booted yet (or a bad UDID) is a normal
+		// state while typing a command out, not a hard failure.
+		return entries, nil
+	}
+
+	for _, m := range bundleBlockRe.FindAllStringSubmatch(string(out), -1) {
+		bundleID, block := m[1], m[2]
+		desc := bundleID
+		if dm := bundleDisplayNameRe.FindStringSubmatch(block); len(dm) == 2 {
+			desc = strings.TrimSpace(dm[1])
+		}
+		entries = append(entries, completionEntry{Value: bundleID, Description: desc})
+	}
+	return entries, nil
+}
+
+// completionHistoryDir follows the XDG base directory spec: recent
+// completion values are cache-like, not config, so they live under
+// $XDG_STATE_HOME (falling back to ~/.local/state) rather than next to
+// resume state in ~/.xcw.
+func completionHistoryDir() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv("XDG_STATE_HOME")); dir != "" {
+		return filepath.Join(dir, "xcw", "completions"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "xcw", "completions"), nil
+}
+
+func completionHistoryPath(key string) (string, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("history key is required")
+	}
+	dir, err := completionHistoryDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, key+".txt"), nil
+}
+
+const completionHistoryLimit = 20
+
+func loadCompletionHistory(key string) ([]completionEntry, error) {
+	path, err := completionHistoryPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []completionEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		entries = append(entries, completionEntry{Value: line, Description: "recent"})
+	}
+	return entries, scanner.Err()
+}
+
+// RecordCompletionValue moves value to the front of key's recent-value
+// history, trimming to completionHistoryLimit. Commands that resolve a
+// simulator or app from user input call this on success so the next
+// invocation's tab-completion surfaces it first. A failure here is
+// intentionally swallowed by callers - history is a convenience, not
+// something worth failing a command over.
+func RecordCompletionValue(key, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	path, err := completionHistoryPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	existing, err := loadCompletionHistory(key)
+	if err != nil {
+		return err
+	}
+
+	values := make([]string, 0, len(existing)+1)
+	values = append(values, value)
+	for _, e := range existing {
+		if e.Value != value {
+			values = append(values, e.Value)
+		}
+	}
+	if len(values) > completionHistoryLimit {
+		values = values[:completionHistoryLimit]
+	}
+
+	var buf bytes.Buffer
+	for _, v := range values {
+		buf.WriteString(v)
+		buf.WriteString("\n")
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/vburojevic/xcw/internal/output"
+)
+
+// CompletionInstallCmd detects the user's shell and wires up xcw completions
+// without requiring a manual copy-paste into the shell's rc file.
+type CompletionInstallCmd struct {
+	Shell  string `optional:"" enum:"bash,zsh,fish,pwsh,nu,auto" default:"auto" help:"Shell to install completions for (default: auto-detect from $SHELL)"`
+	DryRun bool   `help:"Print what would change without writing any files"`
+	Force  bool   `help:"Re-install even if the completion marker is already present"`
+}
+
+// completionInstallMarkerBegin/End wrap whatever this command writes so a
+// second run (or --force) can find and skip/replace its own block instead of
+// appending duplicates.
+const (
+	completionInstallMarkerBegin = "# >>> xcw completion >>>"
+	completionInstallMarkerEnd   = "# <<< xcw completion <<<"
+)
+
+// completionInstallMode describes how a shell wants its completions wired up:
+// either a one-line `eval` appended to an rc file, or the generated script
+// written in full to a shell-specific completions directory.
+type completionInstallMode int
+
+const (
+	completionModeEval completionInstallMode = iota
+	completionModeWrite
+)
+
+type completionInstallTarget struct {
+	Shell string
+	Path  string
+	Mode  completionInstallMode
+}
+
+// CompletionInstallOutput represents the NDJSON output for the install command.
+type CompletionInstallOutput struct {
+	Type          string `json:"type"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Shell         string `json:"shell"`
+	Path          string `json:"path"`
+	Action        string `json:"action"` // installed, skipped, dry_run
+	Reason        string `json:"reason,omitempty"`
+}
+
+// Run executes the completion install command.
+func (c *CompletionInstallCmd) Run(globals *Globals, ctx *kong.Context) error {
+	shell := c.Shell
+	if shell == "" || shell == "auto" {
+		detected, err := detectShell()
+		if err != nil {
+			return outputErrorCommon(globals, "SHELL_DETECT_FAILED", err.Error(),
+				"pass --shell explicitly, e.g. --shell=zsh")
+		}
+		shell = detected
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return outputErrorCommon(globals, "HOME_NOT_FOUND", err.Error())
+	}
+
+	target, err := completionInstallTargetFor(shell, home)
+	if err != nil {
+		return outputErrorCommon(globals, "UNSUPPORTED_SHELL", err.Error())
+	}
+
+	script, err := renderCompletionScript(ctx, shell)
+	if err != nil {
+		return outputErrorCommon(globals, "COMPLETION_GENERATE_FAILED", err.Error())
+	}
+
+	existing, _ := os.ReadFile(target.Path)
+	if !c.Force && strings.Contains(string(existing), completionInstallMarkerBegin) {
+		return c.report(globals, target, "skipped", "marker already present; rerun with --force to reinstall")
+	}
+
+	block := completionInstallBlock(shell, target.Mode, script)
+
+	if c.DryRun {
+		fmt.Fprintf(globals.Stderr, "Would write to %s:\n\n%s", target.Path, block)
+		return c.report(globals, target, "dry_run", "")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target.Path), 0o755); err != nil {
+		return outputErrorCommon(globals, "WRITE_FAILED", err.Error())
+	}
+
+	content := completionInstallMerge(string(existing), block, c.Force)
+	if err := os.WriteFile(target.Path, []byte(content), 0o644); err != nil {
+		return outputErrorCommon(globals, "WRITE_FAILED", err.Error())
+	}
+
+	return c.report(globals, target, "installed", "")
+}
+
+func (c *CompletionInstallCmd) report(globals *Globals, target completionInstallTarget, action, reason string) error {
+	if globals.Format == "ndjson" {
+		out := CompletionInstallOutput{
+			Type:          "completion_install",
+			SchemaVersion: output.SchemaVersion,
+			Shell:         target.Shell,
+			Path:          target.Path,
+			Action:        action,
+			Reason:        reason,
+		}
+		return json.NewEncoder(globals.Stdout).Encode(out)
+	}
+
+	switch action {
+	case "skipped":
+		fmt.Fprintf(globals.Stdout, "xcw completions already installed for %s in %s (%s)\n", target.Shell, target.Path, reason)
+	case "dry_run":
+		fmt.Fprintf(globals.Stdout, "Dry run: would install xcw completions for %s into %s\n", target.Shell, target.Path)
+	default:
+		fmt.Fprintf(globals.Stdout, "Installed xcw completions for %s in %s\n", target.Shell, target.Path)
+	}
+	return nil
+}
+
+// renderCompletionScript generates the completion script for shell by
+// delegating to CompletionCmd, so install never drifts from `xcw completion`.
+func renderCompletionScript(ctx *kong.Context, shell string) (string, error) {
+	var buf bytes.Buffer
+	tmp := &Globals{Stdout: &buf}
+	cmd := &CompletionCmd{Shell: shell}
+	if err := cmd.Run(tmp, ctx); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// detectShell guesses the user's shell from $SHELL (POSIX shells) or, on
+// Windows, falls back to PowerShell since that's the default interactive shell.
+func detectShell() (string, error) {
+	if runtime.GOOS == "windows" {
+		return "pwsh", nil
+	}
+
+	shellPath := strings.TrimSpace(os.Getenv("SHELL"))
+	if shellPath == "" {
+		return "", fmt.Errorf("$SHELL is not set; pass --shell explicitly")
+	}
+
+	switch base := filepath.Base(shellPath); base {
+	case "bash":
+		return "bash", nil
+	case "zsh":
+		return "zsh", nil
+	case "fish":
+		return "fish", nil
+	case "nu":
+		return "nu", nil
+	default:
+		return "", fmt.Errorf("could not determine shell from $SHELL=%q", shellPath)
+	}
+}
+
+func completionInstallTargetFor(shell, home string) (completionInstallTarget, error) {
+	switch shell {
+	case "bash":
+		return completionInstallTarget{Shell: shell, Path: filepath.Join(home, ".bash_profile"), Mode: completionModeEval}, nil
+	case "zsh":
+		return completionInstallTarget{Shell: shell, Path: filepath.Join(home, ".zshrc"), Mode: completionModeEval}, nil
+	case "fish":
+		return completionInstallTarget{Shell: shell, Path: filepath.Join(home, ".config", "fish", "completions", "xcw.fish"), Mode: completionModeWrite}, nil
+	case "nu":
+		return completionInstallTarget{Shell: shell, Path: filepath.Join(home, ".config", "nushell", "completions", "xcw.nu"), Mode: completionModeWrite}, nil
+	case "pwsh":
+		profile := strings.TrimSpace(os.Getenv("PROFILE"))
+		if profile == "" {
+			profile = filepath.Join(home, ".config", "powershell", "Microsoft.PowerShell_profile.ps1")
+		}
+		return completionInstallTarget{Shell: shell, Path: profile, Mode: completionModeEval}, nil
+	default:
+		return completionInstallTarget{}, fmt.Errorf("unsupported shell: %s", shell)
+	}
+}
+
+func completionInstallBlock(shell string, mode completionInstallMode, script string) string {
+	var body string
+	switch mode {
+	case completionModeEval:
+		switch shell {
+		case "pwsh":
+			body = "Invoke-Expression (xcw completion pwsh | Out-String)"
+		default:
+			body = fmt.Sprintf("eval \"$(xcw completion %s)\"", shell)
+		}
+	default:
+		body = strings.TrimRight(script, "\n")
+	}
+
+	return fmt.Sprintf("%s\n%s\n%s\n", completionInstallMarkerBegin, body, completionInstallMarkerEnd)
+}
+
+// completionInstallMerge appends block to existing, replacing any prior
+// xcw-managed block so --force re-installs are idempotent rather than
+// stacking duplicate eval lines on every run.
+func completionInstallMerge(existing, block string, force bool) string {
+	if force {
+		if start := strings.Index(existing, completionInstallMarkerBegin); start >= 0 {
+			if end := strings.Index(existing[start:], completionInstallMarkerEnd); end >= 0 {
+				endIdx := start + end + len(completionInstallMarkerEnd)
+				existing = existing[:start] + existing[endIdx:]
+			}
+		}
+	}
+
+	existing = strings.TrimRight(existing, "\n")
+	if existing == "" {
+		return block
+	}
+	return existing + "\n\n" + block
+}
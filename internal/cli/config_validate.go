@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/vburojevic/xcw/internal/config"
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+// ConfigValidateCmd checks an xcw.yaml for semantic mistakes - misspelled
+// enum values, malformed durations, unparseable regexes/wildcards, negative
+// sizes - that a bare YAML parse accepts but that blow up deep inside the
+// streamer. It always reports every issue it finds rather than stopping at
+// the first, and exits non-zero if any issue was found.
+type ConfigValidateCmd struct {
+	File string `arg:"" optional:"" help:"Path to the config file to validate (default: the file Load would resolve)"`
+}
+
+// Run executes the config validate command.
+func (c *ConfigValidateCmd) Run(globals *Globals) error {
+	cfg, path, err := c.load()
+	if err != nil {
+		return outputErrorCommon(globals, "CONFIG_LOAD_FAILED", err.Error())
+	}
+
+	issues := validationIssues(cfg.Validate())
+	for _, issue := range issues {
+		c.emitIssue(globals, issue)
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("%s: %d config validation issue(s) found", path, len(issues))
+	}
+	if !globals.Quiet && globals.Format != "ndjson" {
+		fmt.Fprintf(globals.Stdout, "%s: no issues found\n", path)
+	}
+	return nil
+}
+
+// load reads c.File (or, if unset, whatever path config.Load would resolve)
+// without applying Load/LoadFromFile's own Validate call, so a config with
+// issues can still be loaded far enough to report them instead of only
+// surfacing the first one as a load error.
+func (c *ConfigValidateCmd) load() (*config.Config, string, error) {
+	if c.File != "" {
+		cfg, err := config.LoadFromFileUnvalidated(c.File)
+		return cfg, c.File, err
+	}
+	path := config.ConfigFile()
+	if path == "" {
+		return config.Default(), "(defaults)", nil
+	}
+	cfg, err := config.LoadFromFileUnvalidated(path)
+	return cfg, path, err
+}
+
+// emitIssue reports one ValidationIssue in the same ndjson-or-one-liner
+// shape every other xcw command uses.
+func (c *ConfigValidateCmd) emitIssue(globals *Globals, issue config.ValidationIssue) {
+	if globals.Format == "ndjson" {
+		if b, err := json.Marshal(domain.NewConfigValidationIssue(issue.FieldPath, issue.Value, issue.Reason, issue.Suggestion)); err == nil {
+			fmt.Fprintln(globals.Stdout, string(b))
+		}
+		return
+	}
+	fmt.Fprintln(globals.Stdout, issue.String())
+}
+
+// validationIssues unwraps the config.ValidationErrors Validate returns, so
+// a nil error (clean config) yields an empty slice rather than a nil-check
+// at every call site.
+func validationIssues(err error) config.ValidationErrors {
+	if err == nil {
+		return nil
+	}
+	if verrs, ok := err.(config.ValidationErrors); ok {
+		return verrs
+	}
+	return nil
+}
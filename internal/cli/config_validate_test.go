@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidateCmd_Run(t *testing.T) {
+	t.Run("reports no issues for a clean config", func(t *testing.T) {
+		tmp := t.TempDir()
+		path := filepath.Join(tmp, "xcw.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("format: ndjson\nlevel: error\n"), 0644))
+
+		globals, stdout, _ := testGlobals("text")
+		cmd := &ConfigValidateCmd{File: path}
+
+		require.NoError(t, cmd.Run(globals))
+		assert.Contains(t, stdout.String(), "no issues found")
+	})
+
+	t.Run("reports a misspelled level with a suggestion in text format", func(t *testing.T) {
+		tmp := t.TempDir()
+		path := filepath.Join(tmp, "xcw.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("level: erorr\n"), 0644))
+
+		globals, stdout, _ := testGlobals("text")
+		cmd := &ConfigValidateCmd{File: path}
+
+		err := cmd.Run(globals)
+		require.Error(t, err)
+		assert.Contains(t, stdout.String(), "level")
+		assert.Contains(t, stdout.String(), `did you mean "error"?`)
+	})
+
+	t.Run("reports issues as NDJSON, one object per issue", func(t *testing.T) {
+		tmp := t.TempDir()
+		path := filepath.Join(tmp, "xcw.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("level: erorr\nformat: json\n"), 0644))
+
+		globals, stdout, _ := testGlobals("ndjson")
+		cmd := &ConfigValidateCmd{File: path}
+
+		err := cmd.Run(globals)
+		require.Error(t, err)
+
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		require.Len(t, lines, 2)
+		for _, line := range lines {
+			var issue map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(line), &issue))
+			assert.Equal(t, "config_validation_issue", issue["type"])
+		}
+	})
+
+	t.Run("returns an error for a file that doesn't exist", func(t *testing.T) {
+		globals, _, _ := testGlobals("text")
+		cmd := &ConfigValidateCmd{File: filepath.Join(t.TempDir(), "missing.yaml")}
+
+		assert.Error(t, cmd.Run(globals))
+	})
+}
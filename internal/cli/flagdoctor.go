@@ -1,5 +1,25 @@
 package cli
 
+import "fmt"
+
+// validateLogRotationFlags validates --log-rotate-size/--log-keep (only
+// meaningful alongside --log-file) and returns the rotation threshold in
+// bytes. logFile == "" short-circuits to (0, nil): the flags are simply
+// unused.
+func validateLogRotationFlags(globals *Globals, logFile, rotateSize string, keep int) (int64, error) {
+	if logFile == "" {
+		return 0, nil
+	}
+	mb, err := parseSize(rotateSize)
+	if err != nil {
+		return 0, outputErrorCommon(globals, "INVALID_FLAGS", fmt.Sprintf("invalid --log-rotate-size: %s", err), "use a size like 10MB or 1GB")
+	}
+	if keep < 0 {
+		return 0, outputErrorCommon(globals, "INVALID_FLAGS", "--log-keep must be >= 0", "use 0 to keep every rotated segment")
+	}
+	return int64(mb) * 1024 * 1024, nil
+}
+
 // validateFlags centralizes common flag combinations to keep behavior consistent.
 func validateFlags(globals *Globals, dryRunJSON bool, tmux bool) error {
 	// dry-run-json requires ndjson and no tmux
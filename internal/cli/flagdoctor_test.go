@@ -18,3 +18,21 @@ func TestValidateFlags(t *testing.T) {
 	globals = &Globals{Format: "ndjson", Quiet: false, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
 	require.NoError(t, validateFlags(globals, false, false))
 }
+
+func TestValidateLogRotationFlags(t *testing.T) {
+	globals := &Globals{Format: "ndjson", Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+
+	maxBytes, err := validateLogRotationFlags(globals, "", "50MB", 5)
+	require.NoError(t, err)
+	require.Zero(t, maxBytes, "no --log-file means the flags are unused")
+
+	maxBytes, err = validateLogRotationFlags(globals, "/tmp/out.ndjson", "10MB", 5)
+	require.NoError(t, err)
+	require.EqualValues(t, 10*1024*1024, maxBytes)
+
+	_, err = validateLogRotationFlags(globals, "/tmp/out.ndjson", "not-a-size", 5)
+	require.Error(t, err)
+
+	_, err = validateLogRotationFlags(globals, "/tmp/out.ndjson", "10MB", -1)
+	require.Error(t, err)
+}
@@ -0,0 +1,323 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// GenerateCmd groups subcommands that turn a working `xcw tail` invocation
+// into a supervised background service, so a user doesn't have to
+// hand-write a plist or unit file just to keep a tail running across
+// reboots.
+type GenerateCmd struct {
+	Launchd GenerateLaunchdCmd `cmd:"" help:"Generate a launchd LaunchAgent plist that runs 'xcw tail' with these flags"`
+	Systemd GenerateSystemdCmd `cmd:"" help:"Generate a systemd --user unit that runs 'xcw tail' with these flags"`
+}
+
+// tailUnitFlags mirrors TailCmd's flag surface (see tail_flags_test.go) so
+// `xcw generate launchd/systemd` can reproduce the exact `xcw tail`
+// invocation a user already has working, inside a supervised unit.
+type tailUnitFlags struct {
+	Simulator       string `short:"s" default:"booted" help:"Simulator name, UDID, or 'booted' for auto-detect"`
+	App             string `short:"a" required:"" help:"App bundle identifier to filter logs"`
+	Pattern         string `name:"filter" short:"p" help:"Regex pattern to filter log messages"`
+	Where           string `help:"Filter entries by a 'key OP value' clause or boolean expression"`
+	Dedupe          bool   `help:"Collapse repeated log messages using semantic pattern matching"`
+	Output          string `help:"Write NDJSON events to this file instead of stdout"`
+	Heartbeat       string `help:"Emit a session_heartbeat event on this interval, e.g. 15s"`
+	SummaryInterval string `name:"summary-interval" help:"Emit a periodic summary on this interval"`
+	SessionIdle     string `name:"session-idle" help:"Close a tracked session after this long without a matching entry"`
+}
+
+// args reconstructs the `xcw tail ...` argument list f describes, leaving
+// out anything at its zero value so the generated unit's ExecStart
+// matches what a user typing the equivalent `xcw tail` command by hand
+// would have run.
+func (f tailUnitFlags) args() []string {
+	args := []string{"tail", "-s", f.Simulator, "-a", f.App}
+	if f.Pattern != "" {
+		args = append(args, "--filter", f.Pattern)
+	}
+	if f.Where != "" {
+		args = append(args, "--where", f.Where)
+	}
+	if f.Dedupe {
+		args = append(args, "--dedupe")
+	}
+	if f.Output != "" {
+		args = append(args, "--output", f.Output)
+	}
+	if f.Heartbeat != "" {
+		args = append(args, "--heartbeat", f.Heartbeat)
+	}
+	if f.SummaryInterval != "" {
+		args = append(args, "--summary-interval", f.SummaryInterval)
+	}
+	if f.SessionIdle != "" {
+		args = append(args, "--session-idle", f.SessionIdle)
+	}
+	return args
+}
+
+// launchdLabel is the plist's Label and the "dev.xcw.<app>" half of its
+// filename.
+func launchdLabel(app string) string {
+	return "dev.xcw." + app
+}
+
+// simulatorDeviceSetDir is where `xcrun simctl` keeps its device set by
+// default - watching it lets a LaunchAgent wake as soon as a simulator
+// boots instead of only at login.
+func simulatorDeviceSetDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "Library", "Developer", "CoreSimulator", "Devices")
+}
+
+// defaultUnitLogPath returns output (if set) or a rotating-by-convention
+// log path under ~/Library/Logs/xcw/<name>.log for the unit to redirect
+// stdout/stderr to.
+func defaultUnitLogPath(output, name string) string {
+	if output != "" {
+		return output
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return name + ".log"
+	}
+	return filepath.Join(home, "Library", "Logs", "xcw", name+".log")
+}
+
+// shellQuote wraps s in single quotes (escaping any embedded ones) when it
+// contains characters a POSIX shell would otherwise split on, so a
+// generated ExecStart= line survives systemd's shell-less but
+// whitespace-splitting argument parsing.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'$&|;<>()") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+const launchdPlistTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{.Label | xmlEscape}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{.ExecPath | xmlEscape}}</string>
+{{- range .Args}}
+		<string>{{. | xmlEscape}}</string>
+{{- end}}
+	</array>
+	<key>KeepAlive</key>
+	<true/>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>{{.LogPath | xmlEscape}}</string>
+	<key>StandardErrorPath</key>
+	<string>{{.LogPath | xmlEscape}}</string>
+	<key>WatchPaths</key>
+	<array>
+		<string>{{.DeviceSetDir | xmlEscape}}</string>
+	</array>
+</dict>
+</plist>
+`
+
+// GenerateLaunchdCmd emits a launchd LaunchAgent plist invoking `xcw tail`
+// with the flags given, so the same tail can run unattended and restart
+// itself across crashes and simulator reboots.
+type GenerateLaunchdCmd struct {
+	tailUnitFlags
+
+	Install bool `help:"Write the plist to ~/Library/LaunchAgents/ instead of only printing it"`
+	Load    bool `help:"After --install, load it with 'launchctl bootstrap'"`
+	DryRun  bool `help:"Print the generated plist to stdout without writing or loading anything"`
+}
+
+// Run executes the generate launchd command.
+func (c *GenerateLaunchdCmd) Run(globals *Globals) error {
+	execPath, err := os.Executable()
+	if err != nil {
+		return outputErrorCommon(globals, "GENERATE_LAUNCHD_FAILED", fmt.Sprintf("locating running binary: %v", err))
+	}
+
+	label := launchdLabel(c.App)
+	plist, err := renderTemplate(launchdPlistTemplate, struct {
+		Label        string
+		ExecPath     string
+		Args         []string
+		LogPath      string
+		DeviceSetDir string
+	}{
+		Label:        label,
+		ExecPath:     execPath,
+		Args:         c.args(),
+		LogPath:      defaultUnitLogPath(c.Output, label),
+		DeviceSetDir: simulatorDeviceSetDir(),
+	})
+	if err != nil {
+		return outputErrorCommon(globals, "GENERATE_LAUNCHD_FAILED", err.Error())
+	}
+
+	if c.DryRun || !c.Install {
+		fmt.Fprint(globals.Stdout, plist)
+		return nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return outputErrorCommon(globals, "GENERATE_LAUNCHD_FAILED", err.Error())
+	}
+	dir := filepath.Join(home, "Library", "LaunchAgents")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return outputErrorCommon(globals, "GENERATE_LAUNCHD_FAILED", err.Error())
+	}
+	path := filepath.Join(dir, label+".plist")
+	if err := os.WriteFile(path, []byte(plist), 0o644); err != nil {
+		return outputErrorCommon(globals, "GENERATE_LAUNCHD_FAILED", err.Error())
+	}
+	fmt.Fprintf(globals.Stdout, "Wrote %s\n", path)
+
+	if c.Load {
+		target := fmt.Sprintf("gui/%d", os.Getuid())
+		out, err := exec.Command("launchctl", "bootstrap", target, path).CombinedOutput()
+		if err != nil {
+			return outputErrorCommon(globals, "GENERATE_LAUNCHD_LOAD_FAILED", fmt.Sprintf("launchctl bootstrap %s %s: %v: %s", target, path, err, strings.TrimSpace(string(out))))
+		}
+		fmt.Fprintf(globals.Stdout, "Loaded %s into %s\n", label, target)
+	}
+	return nil
+}
+
+const systemdUnitTemplate = `[Unit]
+Description=xcw tail for {{.App}}
+After=graphical-session-pre.target
+
+[Service]
+ExecStart={{.ExecStart}}
+Restart=on-failure
+StandardOutput=append:{{.LogPath}}
+StandardError=append:{{.LogPath}}
+
+[Install]
+WantedBy=default.target
+`
+
+// GenerateSystemdCmd emits a systemd --user unit invoking `xcw tail` with
+// the flags given, mirroring GenerateLaunchdCmd for users running xcw
+// against a Linux-hosted simulator bridge or CI runner.
+type GenerateSystemdCmd struct {
+	tailUnitFlags
+
+	Install bool `help:"Write the unit to ~/.config/systemd/user/ instead of only printing it"`
+	Load    bool `help:"After --install, run 'systemctl --user enable --now' on it"`
+	DryRun  bool `help:"Print the generated unit to stdout without writing or loading anything"`
+}
+
+func systemdUnitName(app string) string {
+	return "xcw-" + app + ".service"
+}
+
+// Run executes the generate systemd command.
+func (c *GenerateSystemdCmd) Run(globals *Globals) error {
+	execPath, err := os.Executable()
+	if err != nil {
+		return outputErrorCommon(globals, "GENERATE_SYSTEMD_FAILED", fmt.Sprintf("locating running binary: %v", err))
+	}
+
+	unitName := systemdUnitName(c.App)
+	parts := append([]string{execPath}, c.args()...)
+	quoted := make([]string, len(parts))
+	for i, p := range parts {
+		quoted[i] = shellQuote(p)
+	}
+
+	unit, err := renderTemplate(systemdUnitTemplate, struct {
+		App       string
+		ExecStart string
+		LogPath   string
+	}{
+		App:       c.App,
+		ExecStart: strings.Join(quoted, " "),
+		LogPath:   defaultUnitLogPath(c.Output, strings.TrimSuffix(unitName, ".service")),
+	})
+	if err != nil {
+		return outputErrorCommon(globals, "GENERATE_SYSTEMD_FAILED", err.Error())
+	}
+
+	if c.DryRun || !c.Install {
+		fmt.Fprint(globals.Stdout, unit)
+		return nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return outputErrorCommon(globals, "GENERATE_SYSTEMD_FAILED", err.Error())
+	}
+	dir := filepath.Join(home, ".config", "systemd", "user")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return outputErrorCommon(globals, "GENERATE_SYSTEMD_FAILED", err.Error())
+	}
+	path := filepath.Join(dir, unitName)
+	if err := os.WriteFile(path, []byte(unit), 0o644); err != nil {
+		return outputErrorCommon(globals, "GENERATE_SYSTEMD_FAILED", err.Error())
+	}
+	fmt.Fprintf(globals.Stdout, "Wrote %s\n", path)
+
+	if c.Load {
+		out, err := exec.Command("systemctl", "--user", "enable", "--now", unitName).CombinedOutput()
+		if err != nil {
+			return outputErrorCommon(globals, "GENERATE_SYSTEMD_LOAD_FAILED", fmt.Sprintf("systemctl --user enable --now %s: %v: %s", unitName, err, strings.TrimSpace(string(out))))
+		}
+		fmt.Fprintf(globals.Stdout, "Enabled %s\n", unitName)
+	}
+	return nil
+}
+
+// templateFuncs are available to both unit templates. xmlEscape is only
+// needed by launchdPlistTemplate (systemd's INI-style unit has no such
+// escaping requirement), but text/template doesn't auto-escape by
+// content type the way html/template does, so every XML string value -
+// Label, ExecPath, each arg, LogPath, DeviceSetDir - must pass through it
+// explicitly or a flag value containing "&", "<", or ">" would render a
+// plist that plutil/launchctl reject.
+var templateFuncs = template.FuncMap{
+	"xmlEscape": xmlEscapeText,
+}
+
+func xmlEscapeText(s string) string {
+	var buf bytes.Buffer
+	// xml.EscapeText errors only on write failures, which bytes.Buffer
+	// never produces.
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// renderTemplate executes tmpl with data and returns the result, or an
+// error naming the template problem rather than panicking - both unit
+// templates are package constants, but data (e.g. Args) comes from
+// user-supplied flags.
+func renderTemplate(tmpl string, data interface{}) (string, error) {
+	t, err := template.New("unit").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parsing unit template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering unit template: %w", err)
+	}
+	return buf.String(), nil
+}
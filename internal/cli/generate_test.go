@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTailUnitFlagsArgs(t *testing.T) {
+	f := tailUnitFlags{
+		Simulator:       "iPhone 17 Pro",
+		App:             "com.example.app",
+		Pattern:         "timeout",
+		Where:           "level=error",
+		Dedupe:          true,
+		Output:          "out.ndjson",
+		Heartbeat:       "5s",
+		SummaryInterval: "1m",
+		SessionIdle:     "30s",
+	}
+
+	got := f.args()
+	want := []string{
+		"tail", "-s", "iPhone 17 Pro", "-a", "com.example.app",
+		"--filter", "timeout", "--where", "level=error", "--dedupe",
+		"--output", "out.ndjson", "--heartbeat", "5s",
+		"--summary-interval", "1m", "--session-idle", "30s",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("args() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("args()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTailUnitFlagsArgsOmitsZeroValues(t *testing.T) {
+	f := tailUnitFlags{Simulator: "booted", App: "com.example.app"}
+	got := f.args()
+	want := []string{"tail", "-s", "booted", "-a", "com.example.app"}
+	if len(got) != len(want) {
+		t.Fatalf("args() = %v, want %v", got, want)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	cases := map[string]string{
+		"--dedupe":        "--dedupe",
+		"iPhone 17 Pro":   "'iPhone 17 Pro'",
+		"it's":            `'it'"'"'s'`,
+		"com.example.app": "com.example.app",
+		"level=\"error\"": `'level="error"'`,
+	}
+	for in, want := range cases {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLaunchdLabel(t *testing.T) {
+	if got := launchdLabel("com.example.app"); got != "dev.xcw.com.example.app" {
+		t.Fatalf("launchdLabel = %q", got)
+	}
+}
+
+func TestGenerateLaunchdDryRun(t *testing.T) {
+	globals, stdout, _ := testGlobals("text")
+	cmd := &GenerateLaunchdCmd{
+		tailUnitFlags: tailUnitFlags{Simulator: "booted", App: "com.example.app"},
+		DryRun:        true,
+	}
+
+	if err := cmd.Run(globals); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, "<key>Label</key>") {
+		t.Fatalf("expected a plist, got %s", out)
+	}
+	if !strings.Contains(out, "dev.xcw.com.example.app") {
+		t.Fatalf("expected label in plist, got %s", out)
+	}
+	if !strings.Contains(out, "<key>WatchPaths</key>") {
+		t.Fatalf("expected WatchPaths entry, got %s", out)
+	}
+}
+
+func TestGenerateLaunchdDryRunEscapesXMLSpecialCharacters(t *testing.T) {
+	globals, stdout, _ := testGlobals("text")
+	cmd := &GenerateLaunchdCmd{
+		tailUnitFlags: tailUnitFlags{
+			Simulator: "booted",
+			App:       "com.example.app",
+			Pattern:   `Thread \d+ & "Crashed" <fatal>`,
+		},
+		DryRun: true,
+	}
+
+	if err := cmd.Run(globals); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	out := stdout.String()
+	if strings.Contains(out, `& "Crashed" <fatal>`) {
+		t.Fatalf("expected XML special characters to be escaped, got %s", out)
+	}
+	if !strings.Contains(out, "&amp;") || !strings.Contains(out, "&lt;fatal&gt;") {
+		t.Fatalf("expected escaped entities in plist, got %s", out)
+	}
+}
+
+func TestGenerateSystemdDryRun(t *testing.T) {
+	globals, stdout, _ := testGlobals("text")
+	cmd := &GenerateSystemdCmd{
+		tailUnitFlags: tailUnitFlags{Simulator: "iPhone 17 Pro", App: "com.example.app"},
+		DryRun:        true,
+	}
+
+	if err := cmd.Run(globals); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, "Restart=on-failure") {
+		t.Fatalf("expected Restart=on-failure, got %s", out)
+	}
+	if !strings.Contains(out, "'iPhone 17 Pro'") {
+		t.Fatalf("expected quoted simulator name in ExecStart, got %s", out)
+	}
+}
@@ -1,6 +1,22 @@
 package cli
 
-import "go.uber.org/zap"
+import (
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// defaultDebugLogPath returns ~/.xcw/debug.log, the file agentLogger tees
+// its verbose output to so `xcw support dump` has something to attach
+// besides whatever happened to still be in the user's terminal scrollback.
+func defaultDebugLogPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".xcw", "debug.log")
+}
 
 // agentLogger wraps zap for verbose debug with tail/session context.
 type agentLogger struct {
@@ -17,6 +33,11 @@ func newAgentLogger(globals *Globals, tailID string, sessionFn func() int) *agen
 	cfg := zap.NewProductionConfig()
 	cfg.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
 	cfg.Encoding = "json"
+	if path := defaultDebugLogPath(); path != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err == nil {
+			cfg.OutputPaths = append(cfg.OutputPaths, path)
+		}
+	}
 	logger, _ := cfg.Build()
 	return &agentLogger{
 		sugared:   logger.Sugar(),
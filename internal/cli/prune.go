@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/vburojevic/xcw/internal/config"
+	"github.com/vburojevic/xcw/internal/domain"
+	"github.com/vburojevic/xcw/internal/retention"
+)
+
+// PruneCmd reports (and, with --apply, deletes) rotated session recordings
+// and stale resume-state files that exceed defaults.retention's policy. It
+// defaults to a dry run so a misconfigured policy can be inspected before
+// anything is actually removed.
+type PruneCmd struct {
+	RecordDir string `help:"Directory of recorded session-<n>.tar.gz files to prune (the same directory passed to watch --record); omit to skip session pruning"`
+	ResumeDir string `help:"Directory of resume-state files to prune (default: ~/.xcw/resume)"`
+	Apply     bool   `help:"Delete the reported candidates instead of only reporting them"`
+}
+
+// Run executes the prune command.
+func (c *PruneCmd) Run(globals *Globals) error {
+	policy, err := retentionPolicyFromConfig(globals.Config.Defaults.Retention)
+	if err != nil {
+		return outputErrorCommon(globals, "INVALID_RETENTION_POLICY", err.Error())
+	}
+
+	var toDelete []retention.Candidate
+
+	if c.RecordDir != "" {
+		sessionCandidates, serr := scanGlobCandidates(c.RecordDir, "session-*.tar.gz")
+		if serr != nil {
+			return outputErrorCommon(globals, "PRUNE_SCAN_FAILED", serr.Error())
+		}
+		toDelete = append(toDelete, retention.Select(policy, sessionCandidates)...)
+	}
+
+	resumeDir := c.ResumeDir
+	if resumeDir == "" {
+		if home, herr := os.UserHomeDir(); herr == nil {
+			resumeDir = filepath.Join(home, ".xcw", "resume")
+		}
+	}
+	if resumeDir != "" {
+		if resumeCandidates, rerr := scanResumeStateCandidates(resumeDir); rerr == nil {
+			// Only max_age applies to resume-state: max_files/max_total_bytes/
+			// keep_latest are sized for a pile of rotated recordings, not a
+			// one-file-per-app directory of small JSON snapshots.
+			toDelete = append(toDelete, retention.Select(retention.Policy{MaxAge: policy.MaxAge}, resumeCandidates)...)
+		}
+	}
+
+	now := time.Now()
+	for _, cand := range toDelete {
+		deleted := false
+		if c.Apply {
+			if rerr := os.Remove(cand.Path); rerr == nil {
+				deleted = true
+			} else if !os.IsNotExist(rerr) {
+				globals.Debug("prune: failed to remove %s: %v", cand.Path, rerr)
+			}
+		}
+		c.emitCandidate(globals, cand, now, deleted)
+	}
+	return nil
+}
+
+// emitCandidate reports one file retention would (or, with --apply, did)
+// remove, in the same ndjson-or-one-liner shape every other xcw command
+// uses.
+func (c *PruneCmd) emitCandidate(globals *Globals, cand retention.Candidate, now time.Time, deleted bool) {
+	age := now.Sub(cand.ModTime)
+	if globals.Format == "ndjson" {
+		if b, err := json.Marshal(domain.NewPruneCandidate(cand.Path, cand.Size, age, deleted)); err == nil {
+			fmt.Fprintln(globals.Stdout, string(b))
+		}
+		return
+	}
+	verb := "would delete"
+	if deleted {
+		verb = "deleted"
+	}
+	fmt.Fprintf(globals.Stdout, "%s %s (%d bytes, age %s)\n", verb, cand.Path, cand.Size, age.Round(time.Second))
+}
+
+// scanGlobCandidates gathers retention.Candidates for every file in dir
+// matching pattern, using the file's own mtime/size.
+func scanGlobCandidates(dir, pattern string) ([]retention.Candidate, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+	var candidates []retention.Candidate
+	for _, m := range matches {
+		info, serr := os.Stat(m)
+		if serr != nil {
+			continue
+		}
+		candidates = append(candidates, retention.Candidate{Path: m, Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return candidates, nil
+}
+
+// scanResumeStateCandidates gathers retention.Candidates for every
+// resume-state file in dir, ageing each by its own UpdatedAt field rather
+// than the file's mtime, so age reflects when the app last logged rather
+// than when something merely touched the file on disk.
+func scanResumeStateCandidates(dir string) ([]retention.Candidate, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var candidates []retention.Candidate
+	for _, m := range matches {
+		info, serr := os.Stat(m)
+		if serr != nil {
+			continue
+		}
+		modTime := info.ModTime()
+		if st, lerr := loadResumeState(m); lerr == nil && st != nil {
+			if t, perr := parseRFC3339Any(st.UpdatedAt); perr == nil && !t.IsZero() {
+				modTime = t
+			}
+		}
+		candidates = append(candidates, retention.Candidate{Path: m, Size: info.Size(), ModTime: modTime})
+	}
+	return candidates, nil
+}
+
+// retentionPolicyFromConfig parses the duration/size strings in cfg into a
+// retention.Policy, the same way validateLogRotationFlags parses
+// --log-rotate-size.
+func retentionPolicyFromConfig(cfg config.RetentionConfig) (retention.Policy, error) {
+	p := retention.Policy{
+		MaxFiles:   cfg.MaxFiles,
+		KeepLatest: cfg.KeepLatest,
+	}
+	if cfg.MaxAge != "" {
+		d, err := time.ParseDuration(cfg.MaxAge)
+		if err != nil {
+			return p, fmt.Errorf("invalid defaults.retention.max_age %q: %w", cfg.MaxAge, err)
+		}
+		p.MaxAge = d
+	}
+	if cfg.MaxTotalBytes != "" {
+		mb, err := parseSize(cfg.MaxTotalBytes)
+		if err != nil {
+			return p, fmt.Errorf("invalid defaults.retention.max_total_bytes %q: %w", cfg.MaxTotalBytes, err)
+		}
+		p.MaxTotalBytes = int64(mb) * 1024 * 1024
+	}
+	return p, nil
+}
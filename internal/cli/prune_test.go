@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vburojevic/xcw/internal/config"
+)
+
+func writeSessionFile(t *testing.T, dir string, n int, age time.Duration) string {
+	t.Helper()
+	path := filepath.Join(dir, fmt.Sprintf("session-%d.tar.gz", n))
+	require.NoError(t, os.WriteFile(path, []byte("fake tarball"), 0o644))
+	when := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, when, when))
+	return path
+}
+
+func TestPruneCmdDryRunReportsWithoutDeleting(t *testing.T) {
+	dir := t.TempDir()
+	old := writeSessionFile(t, dir, 1, 48*time.Hour)
+	recent := writeSessionFile(t, dir, 2, time.Minute)
+
+	t.Setenv("HOME", t.TempDir()) // keep the default ~/.xcw/resume scan out of the real home dir
+
+	globals, stdout, _ := testGlobals("ndjson")
+	globals.Config.Defaults.Retention = config.RetentionConfig{MaxFiles: 1}
+
+	cmd := &PruneCmd{RecordDir: dir}
+	require.NoError(t, cmd.Run(globals))
+
+	require.FileExists(t, old, "dry run must not delete the file it reports")
+	require.FileExists(t, recent)
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 1)
+	var v map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &v))
+	require.Equal(t, "prune_candidate", v["type"])
+	require.Equal(t, old, v["path"])
+	require.Equal(t, false, v["deleted"])
+}
+
+func TestPruneCmdApplyDeletesCandidates(t *testing.T) {
+	dir := t.TempDir()
+	old := writeSessionFile(t, dir, 1, 48*time.Hour)
+	recent := writeSessionFile(t, dir, 2, time.Minute)
+
+	t.Setenv("HOME", t.TempDir()) // keep the default ~/.xcw/resume scan out of the real home dir
+
+	globals, _, _ := testGlobals("ndjson")
+	globals.Config.Defaults.Retention = config.RetentionConfig{MaxFiles: 1}
+
+	cmd := &PruneCmd{RecordDir: dir, Apply: true}
+	require.NoError(t, cmd.Run(globals))
+
+	_, err := os.Stat(old)
+	require.True(t, os.IsNotExist(err), "expected the oldest session file to be deleted")
+	require.FileExists(t, recent, "expected the kept session file to survive")
+}
+
+func TestPruneCmdStaleResumeStateByUpdatedAt(t *testing.T) {
+	resumeDir := t.TempDir()
+	stalePath := filepath.Join(resumeDir, "com.example.stale.json")
+	freshPath := filepath.Join(resumeDir, "com.example.fresh.json")
+
+	stale := &resumeState{Type: "resume_state", App: "com.example.stale", UpdatedAt: time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)}
+	fresh := &resumeState{Type: "resume_state", App: "com.example.fresh", UpdatedAt: time.Now().Format(time.RFC3339)}
+	require.NoError(t, saveResumeState(stalePath, stale))
+	require.NoError(t, saveResumeState(freshPath, fresh))
+
+	globals, _, _ := testGlobals("ndjson")
+	globals.Config.Defaults.Retention = config.RetentionConfig{MaxAge: "1h"}
+
+	cmd := &PruneCmd{ResumeDir: resumeDir, Apply: true}
+	require.NoError(t, cmd.Run(globals))
+
+	_, err := os.Stat(stalePath)
+	require.True(t, os.IsNotExist(err), "expected the stale resume-state file to be deleted")
+	require.FileExists(t, freshPath)
+}
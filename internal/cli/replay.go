@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/vburojevic/xcw/internal/domain"
+	"github.com/vburojevic/xcw/internal/filter"
+	"github.com/vburojevic/xcw/internal/output"
+	"github.com/vburojevic/xcw/internal/session"
+)
+
+// ReplayCmd replays a previously captured NDJSON log file - or a live
+// NDJSON stream piped over stdin - through the same filter/session/sink
+// machinery as live tailing, without touching a simulator. This makes the
+// files `watch --log-file`/`--record` produce, and anything a CI job
+// captured, directly replayable with the same flags used for live tails.
+type ReplayCmd struct {
+	File string `arg:"" optional:"" help:"Path to an NDJSON log file to replay; omit or pass - to read from stdin"`
+
+	App           string   `help:"Bundle ID to attribute session-boundary tracking to (default: untracked)"`
+	FilterRegex   string   `help:"Only replay entries whose message matches this regex"`
+	FilterLevel   string   `help:"Only replay entries at or above this level"`
+	FilterProcess []string `help:"Only replay entries from these process names (repeatable)"`
+	Grep          string   `help:"Additional regex the message must match"`
+	Exclude       string   `help:"Regex; entries matching it are dropped"`
+	Speed         float64  `default:"0" help:"Pace emission at this multiplier of the entries' original timestamp spacing (0 = as fast as possible, 1.0 = real-time)"`
+
+	Sink []string `help:"Additional output destination as kind:path (human:/ndjson:/stdout:), repeatable"`
+}
+
+// Run executes the replay command.
+func (c *ReplayCmd) Run(globals *Globals) error {
+	source := "stdin"
+	var r io.Reader = os.Stdin
+	if c.File != "" && c.File != "-" {
+		source = c.File
+		f, err := os.Open(c.File)
+		if err != nil {
+			return outputErrorCommon(globals, "REPLAY_FAILED", err.Error())
+		}
+		defer f.Close()
+		r = f
+	}
+
+	entries, skipped, err := readNDJSONEntries(r)
+	if err != nil {
+		return outputErrorCommon(globals, "REPLAY_FAILED", err.Error())
+	}
+	if skipped > 0 && !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "Skipped %d non-JSON line(s) while replaying %s\n", skipped, source)
+	}
+
+	chain, err := c.buildFilterChain()
+	if err != nil {
+		return outputErrorCommon(globals, "INVALID_FLAGS", err.Error())
+	}
+
+	pipeline := output.NewPipeline(chain)
+	pipeline.OnSinkError = func(sink string, sinkErr error) {
+		if !globals.Quiet {
+			output.NewNDJSONWriter(globals.Stderr).WriteReconnect(
+				fmt.Sprintf("sink %s failed: %s", sink, sinkErr.Error()), "", "warn")
+		}
+	}
+
+	// Always replay to the primary output (stdout), plus any additional sinks.
+	pipeline.AddSink(output.NewStdoutSink(globals.Format, globals.Stdout))
+	for _, spec := range c.Sink {
+		sink, err := output.ParseSinkSpec(spec, globals.Stdout)
+		if err != nil {
+			return outputErrorCommon(globals, "INVALID_SINK", err.Error())
+		}
+		pipeline.AddSink(sink)
+	}
+	defer pipeline.Close()
+
+	// Tracked the same way `watch --record` tracks a live stream, so a
+	// replayed session's launches/crashes line up the same way - just
+	// without a Recorder, since there's no new tarball to archive this into.
+	tracker := session.NewTracker(c.App, "", "", "", "", "")
+
+	if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "Replaying logs from %s\n", source)
+	}
+
+	count := 0
+	var prev time.Time
+	for i := range entries {
+		if c.Speed > 0 && i > 0 {
+			if gap := entries[i].Timestamp.Sub(prev); gap > 0 {
+				time.Sleep(time.Duration(float64(gap) / c.Speed))
+			}
+		}
+		tracker.CheckEntry(&entries[i])
+		if err := pipeline.Write(&entries[i]); err != nil {
+			return err
+		}
+		if chain.Match(&entries[i]) {
+			count++
+		}
+		prev = entries[i].Timestamp
+	}
+
+	if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "Replayed %d entries\n", count)
+	}
+	return nil
+}
+
+// readNDJSONEntries reads NDJSON log entries line-by-line from r. A final
+// line with no trailing newline (e.g. the writer on the other end of a pipe
+// was killed mid-write) is still scanned and attempted rather than dropped,
+// and a line that doesn't parse as a domain.LogEntry - a stray shell banner,
+// a print statement that snuck into the capture - is counted and skipped
+// rather than aborting the whole replay.
+func readNDJSONEntries(r io.Reader) (entries []domain.LogEntry, skipped int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry domain.LogEntry
+		if jsonErr := json.Unmarshal(line, &entry); jsonErr != nil {
+			skipped++
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if serr := scanner.Err(); serr != nil {
+		return entries, skipped, serr
+	}
+	return entries, skipped, nil
+}
+
+func (c *ReplayCmd) buildFilterChain() (*filter.FilterChain, error) {
+	var opts []filter.ChainOption
+
+	if c.FilterRegex != "" {
+		re, err := regexp.Compile(c.FilterRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --filter-regex: %w", err)
+		}
+		opts = append(opts, filter.WithRegex(re))
+	}
+	if c.FilterLevel != "" {
+		opts = append(opts, filter.WithMinLevel(domain.ParseLogLevel(c.FilterLevel)))
+	}
+	if len(c.FilterProcess) > 0 {
+		opts = append(opts, filter.WithProcesses(c.FilterProcess))
+	}
+	if c.Grep != "" {
+		re, err := regexp.Compile(c.Grep)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --grep: %w", err)
+		}
+		opts = append(opts, filter.WithGrep(re))
+	}
+	if c.Exclude != "" {
+		re, err := regexp.Compile(c.Exclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exclude: %w", err)
+		}
+		opts = append(opts, filter.WithExclude(re))
+	}
+
+	if len(opts) == 0 {
+		return nil, nil
+	}
+	return filter.NewFilterChain(opts...), nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadNDJSONEntriesSkipsNonJSONLines(t *testing.T) {
+	input := strings.Join([]string{
+		`{"timestamp":"2025-12-14T22:00:00Z","level":"info","message":"one"}`,
+		`not json at all`,
+		``,
+		`{"timestamp":"2025-12-14T22:00:01Z","level":"info","message":"two"}`,
+	}, "\n")
+
+	entries, skipped, err := readNDJSONEntries(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, 1, skipped)
+	require.Equal(t, "one", entries[0].Message)
+	require.Equal(t, "two", entries[1].Message)
+}
+
+func TestReadNDJSONEntriesToleratesTornFinalLine(t *testing.T) {
+	// The writer on the other end of a pipe died mid-line: the last line
+	// has no trailing newline and isn't valid JSON.
+	input := `{"timestamp":"2025-12-14T22:00:00Z","level":"info","message":"one"}` + "\n" + `{"timestamp":"2025-12-14T22:00:01`
+
+	entries, skipped, err := readNDJSONEntries(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, 1, skipped)
+}
+
+func TestReplayCmdReadsFromStdinByDefault(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = origStdin }()
+
+	go func() {
+		w.WriteString(`{"timestamp":"2025-12-14T22:00:00Z","level":"info","message":"from stdin"}` + "\n")
+		w.Close()
+	}()
+
+	globals, stdout, _ := testGlobals("text")
+	globals.Quiet = true
+	cmd := &ReplayCmd{}
+
+	require.NoError(t, cmd.Run(globals))
+	require.Contains(t, stdout.String(), "from stdin")
+}
+
+func TestReplayCmdDashAlsoReadsFromStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = origStdin }()
+
+	go func() {
+		w.WriteString(`{"timestamp":"2025-12-14T22:00:00Z","level":"info","message":"dash stdin"}` + "\n")
+		w.Close()
+	}()
+
+	globals, stdout, _ := testGlobals("text")
+	globals.Quiet = true
+	cmd := &ReplayCmd{File: "-"}
+
+	require.NoError(t, cmd.Run(globals))
+	require.Contains(t, stdout.String(), "dash stdin")
+}
+
+func TestReplayCmdReportsSkippedLines(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = origStdin }()
+
+	go func() {
+		w.WriteString("garbage line\n")
+		w.WriteString(`{"timestamp":"2025-12-14T22:00:00Z","level":"info","message":"ok"}` + "\n")
+		w.Close()
+	}()
+
+	globals, _, stderr := testGlobals("text")
+	globals.Quiet = false
+	cmd := &ReplayCmd{}
+
+	require.NoError(t, cmd.Run(globals))
+	require.Contains(t, stderr.String(), "Skipped 1 non-JSON line")
+}
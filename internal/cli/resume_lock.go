@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// resumeLock is an OS file lock (flock) held on <resume path>.lock for the
+// duration of a tail session, so two "xcw tail -a com.example.myapp"
+// processes can't race on the same app's resume state.
+type resumeLock struct {
+	file *os.File
+	path string
+}
+
+// errResumeLocked is returned by acquireResumeLock when another live
+// process already holds the lock. Callers surface it as RESUME_LOCKED via
+// outputErrorCommon.
+type errResumeLocked struct {
+	path string
+	pid  int
+}
+
+func (e *errResumeLocked) Error() string {
+	if e.pid > 0 {
+		return fmt.Sprintf("resume state %s is locked by pid %d", e.path, e.pid)
+	}
+	return fmt.Sprintf("resume state %s is locked by another process", e.path)
+}
+
+// outputResumeLockedError reports a RESUME_LOCKED failure the way every
+// other command reports fatal errors. Callers that take a resume lock
+// (e.g. TailCmd.Run) should route acquireResumeLock's *errResumeLocked
+// through here instead of returning it raw.
+func outputResumeLockedError(globals *Globals, err error) error {
+	return outputErrorCommon(globals, "RESUME_LOCKED", err.Error(),
+		"pass --resume-force to break a stale lock, or stop the other xcw tail process")
+}
+
+// acquireResumeLock takes an exclusive, non-blocking flock on
+// <resumeStatePath>.lock. If the lock is already held by a process that is
+// no longer alive, it is treated as stale and reclaimed automatically. If
+// it's held by a live process, acquireResumeLock fails fast with
+// *errResumeLocked unless force is true, in which case the stale-or-not
+// lock is broken anyway.
+func acquireResumeLock(resumeStatePath string, force bool) (*resumeLock, error) {
+	lockPath := resumeStatePath + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", lockPath, err)
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if err != syscall.EWOULDBLOCK {
+			f.Close()
+			return nil, fmt.Errorf("locking %s: %w", lockPath, err)
+		}
+
+		pid := readLockPID(f)
+		if !force && pid > 0 && processAlive(pid) {
+			f.Close()
+			return nil, &errResumeLocked{path: lockPath, pid: pid}
+		}
+
+		// Either the holder's pid is no longer alive (stale lock left
+		// behind by a crash) or the caller passed --resume-force: break
+		// it by recreating the lock file and locking the fresh handle.
+		f.Close()
+		if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("removing stale lock %s: %w", lockPath, err)
+		}
+		f, err = os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", lockPath, err)
+		}
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("locking %s: %w", lockPath, err)
+		}
+	}
+
+	if err := f.Truncate(0); err == nil {
+		f.WriteString(strconv.Itoa(os.Getpid()))
+		f.Sync()
+	}
+
+	return &resumeLock{file: f, path: lockPath}, nil
+}
+
+// Release unlocks and closes the lock file. The lock file itself is left
+// on disk (its pid is stale until the next acquire, which is harmless).
+func (l *resumeLock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	_ = syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	return l.file.Close()
+}
+
+// readLockPID reads whatever pid was last written to an open lock file.
+func readLockPID(f *os.File) int {
+	buf := make([]byte, 32)
+	n, err := f.ReadAt(buf, 0)
+	if err != nil && n == 0 {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+// processAlive reports whether pid refers to a running process, using the
+// signal-0 idiom: ESRCH means it's gone, EPERM means it exists but we lack
+// permission to signal it (still alive as far as we're concerned).
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireResumeLockExclusive(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "resume.json")
+
+	lock, err := acquireResumeLock(path, false)
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+
+	_, err = acquireResumeLock(path, false)
+	require.Error(t, err)
+	var locked *errResumeLocked
+	require.ErrorAs(t, err, &locked)
+	require.Equal(t, os.Getpid(), locked.pid)
+
+	require.NoError(t, lock.Release())
+
+	lock2, err := acquireResumeLock(path, false)
+	require.NoError(t, err)
+	require.NoError(t, lock2.Release())
+}
+
+func TestAcquireResumeLockForceBreaksLiveLock(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "resume.json")
+
+	lock, err := acquireResumeLock(path, false)
+	require.NoError(t, err)
+	defer lock.Release()
+
+	// Without --resume-force a second tail on the same app fails fast...
+	_, err = acquireResumeLock(path, false)
+	require.Error(t, err)
+
+	// ...but --resume-force breaks the lock even though our own process
+	// (the recorded pid) is still very much alive.
+	forced, err := acquireResumeLock(path, true)
+	require.NoError(t, err)
+	require.NoError(t, forced.Release())
+}
+
+func TestProcessAlive(t *testing.T) {
+	require.True(t, processAlive(os.Getpid()))
+	require.False(t, processAlive(0))
+}
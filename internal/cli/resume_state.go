@@ -3,12 +3,23 @@ package cli
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 )
 
+// currentResumeStateSchemaVersion is the newest resumeState shape this
+// binary knows how to write. saveResumeState refuses to overwrite a file
+// stamped with a newer version - e.g. after a downgrade - rather than
+// silently discarding fields it doesn't understand.
+const currentResumeStateSchemaVersion = 1
+
+// errResumeStateTooNew is returned by saveResumeState when the file on
+// disk was written by a newer xcw than this one.
+var errResumeStateTooNew = errors.New("resume state schema version is newer than this binary supports")
+
 type resumeState struct {
 	Type              string `json:"type"` // "resume_state"
 	SchemaVersion     int    `json:"schemaVersion"`
@@ -17,6 +28,10 @@ type resumeState struct {
 	LastSeenTimestamp string `json:"last_seen_timestamp,omitempty"`
 	LastLogTimestamp  string `json:"last_log_timestamp,omitempty"`
 	UpdatedAt         string `json:"updated_at,omitempty"`
+	// Generation increments on every successful save. It lets a reader
+	// tell a fully-written file apart from a sibling .tmp left behind by
+	// a process that crashed mid-write (see loadResumeState).
+	Generation uint64 `json:"generation,omitempty"`
 }
 
 func defaultResumeStatePath(app string) (string, error) {
@@ -29,18 +44,26 @@ func defaultResumeStatePath(app string) (string, error) {
 		return "", err
 	}
 	dir := filepath.Join(home, ".xcw", "resume")
-	if err := os.MkdirAll(dir, 0o755); err != nil {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
 		return "", err
 	}
 	filename := app + ".json"
 	return filepath.Join(dir, filename), nil
 }
 
+// loadResumeState reads and parses path. A missing file is not an error -
+// it means "start from scratch" - and neither is a corrupt one: rather
+// than aborting the tail that depends on it, the corrupt file is moved
+// aside to "<path>.corrupt-<unix timestamp>" for later inspection and
+// loadResumeState returns (nil, nil) so the caller resumes from scratch.
 func loadResumeState(path string) (*resumeState, error) {
 	path = strings.TrimSpace(path)
 	if path == "" {
 		return nil, errors.New("resume state path is required")
 	}
+
+	repairStaleTmp(path)
+
 	b, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -50,11 +73,61 @@ func loadResumeState(path string) (*resumeState, error) {
 	}
 	var st resumeState
 	if err := json.Unmarshal(b, &st); err != nil {
-		return nil, err
+		quarantinePath := fmt.Sprintf("%s.corrupt-%d", path, time.Now().Unix())
+		if rerr := os.Rename(path, quarantinePath); rerr != nil {
+			return nil, fmt.Errorf("resume state %s is corrupt and could not be quarantined: %w", path, rerr)
+		}
+		fmt.Fprintf(os.Stderr, "xcw: resume state %s is corrupt, moved aside to %s; resuming from scratch\n", path, quarantinePath)
+		return nil, nil
 	}
 	return &st, nil
 }
 
+// repairStaleTmp recovers from a process that crashed between writing
+// path+".tmp" and renaming it over path: if the tmp file is newer than
+// path (or path doesn't exist at all), still parses as a valid
+// resumeState, and carries a higher Generation than a parseable path, it
+// is promoted in place of path. A tmp file that fails to parse is a torn
+// write and is simply discarded, as is one whose Generation is not newer.
+func repairStaleTmp(path string) {
+	tmpPath := path + ".tmp"
+	tmpInfo, err := os.Stat(tmpPath)
+	if err != nil {
+		return
+	}
+
+	if info, err := os.Stat(path); err == nil && !tmpInfo.ModTime().After(info.ModTime()) {
+		os.Remove(tmpPath)
+		return
+	}
+
+	b, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return
+	}
+	var st resumeState
+	if err := json.Unmarshal(b, &st); err != nil {
+		os.Remove(tmpPath)
+		return
+	}
+	if cur, err := os.ReadFile(path); err == nil {
+		var curSt resumeState
+		if json.Unmarshal(cur, &curSt) == nil && curSt.Generation >= st.Generation {
+			os.Remove(tmpPath)
+			return
+		}
+	}
+	_ = os.Rename(tmpPath, path)
+}
+
+// saveResumeState writes st to path atomically (write to a sibling
+// tempfile, fsync, then rename over path) so a crash or full disk mid-write
+// can never leave a truncated resume file, and bumps st.Generation past
+// whatever is currently on disk so two racing writers can be told apart.
+// The parent dir and the final file are hardened to 0700/0600 - resume
+// state records which app/bundle the user is debugging, which other local
+// users have no business reading - mirroring the agent-socket dir/listener
+// hardening pattern.
 func saveResumeState(path string, st *resumeState) error {
 	path = strings.TrimSpace(path)
 	if path == "" {
@@ -63,15 +136,65 @@ func saveResumeState(path string, st *resumeState) error {
 	if st == nil {
 		return errors.New("resume state is required")
 	}
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
 		return err
 	}
+
+	existing, loadErr := loadResumeState(path)
+	if loadErr != nil {
+		return loadErr
+	}
+	if existing != nil {
+		if existing.SchemaVersion > currentResumeStateSchemaVersion {
+			fmt.Fprintf(os.Stderr, "xcw: resume state %s has schema version %d, newer than %d supported by this binary; refusing to overwrite\n",
+				path, existing.SchemaVersion, currentResumeStateSchemaVersion)
+			return errResumeStateTooNew
+		}
+		if existing.Generation >= st.Generation {
+			st.Generation = existing.Generation + 1
+		}
+	}
+	if st.Generation == 0 {
+		st.Generation = 1
+	}
+	if st.SchemaVersion == 0 {
+		st.SchemaVersion = currentResumeStateSchemaVersion
+	}
+
 	b, err := json.MarshalIndent(st, "", "  ")
 	if err != nil {
 		return err
 	}
 	b = append(b, '\n')
-	return os.WriteFile(path, b, 0o644)
+
+	tmpPath := path + ".tmp"
+	if err := writeResumeStateTempFile(tmpPath, b); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// writeResumeStateTempFile creates tmpPath at mode 0600, writes b, and
+// fsyncs before returning so the caller's rename always publishes a
+// complete file. It is a package variable rather than a plain function so
+// tests can substitute a writer that fails partway through a write,
+// proving saveResumeState never renames a half-written tempfile over a
+// good resume-state file.
+var writeResumeStateTempFile = func(tmpPath string, b []byte) error {
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", tmpPath, err)
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", tmpPath, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing %s: %w", tmpPath, err)
+	}
+	return f.Close()
 }
 
 func parseRFC3339Any(s string) (time.Time, error) {
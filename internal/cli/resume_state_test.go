@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -45,6 +47,7 @@ func TestSaveAndLoadResumeStateRoundTrip(t *testing.T) {
 		UpdatedAt:         "2025-12-14T22:00:02Z",
 	}
 	require.NoError(t, saveResumeState(path, st))
+	require.EqualValues(t, 1, st.Generation)
 
 	loaded, err := loadResumeState(path)
 	require.NoError(t, err)
@@ -52,6 +55,144 @@ func TestSaveAndLoadResumeStateRoundTrip(t *testing.T) {
 	require.Equal(t, st, loaded)
 }
 
+func TestSaveResumeStateIncrementsGeneration(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "resume.json")
+
+	st := &resumeState{Type: "resume_state", App: "com.example.myapp"}
+	require.NoError(t, saveResumeState(path, st))
+	require.EqualValues(t, 1, st.Generation)
+
+	require.NoError(t, saveResumeState(path, st))
+	require.EqualValues(t, 2, st.Generation)
+
+	loaded, err := loadResumeState(path)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, loaded.Generation)
+}
+
+func TestLoadResumeStateRepairsStaleTmp(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "resume.json")
+
+	st := &resumeState{Type: "resume_state", App: "com.example.myapp", Generation: 3}
+	b, err := json.Marshal(st)
+	require.NoError(t, err)
+
+	// Simulate a crash right after the tmp file was written but before the
+	// rename landed: only path+".tmp" exists.
+	require.NoError(t, os.WriteFile(path+".tmp", b, 0o644))
+
+	loaded, err := loadResumeState(path)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	require.EqualValues(t, 3, loaded.Generation)
+
+	_, err = os.Stat(path + ".tmp")
+	require.True(t, os.IsNotExist(err), "expected the tmp file to be promoted over path")
+}
+
+func TestLoadResumeStateDiscardsTornTmp(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "resume.json")
+
+	st := &resumeState{Type: "resume_state", App: "com.example.myapp", Generation: 1}
+	require.NoError(t, saveResumeState(path, st))
+
+	// A truncated write: valid path, garbage tmp left behind.
+	require.NoError(t, os.WriteFile(path+".tmp", []byte(`{"app":"com.example`), 0o644))
+
+	loaded, err := loadResumeState(path)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, loaded.Generation)
+
+	_, err = os.Stat(path + ".tmp")
+	require.True(t, os.IsNotExist(err), "expected the torn tmp file to be discarded")
+}
+
+func TestSaveResumeStateHardensPermissions(t *testing.T) {
+	tmp := t.TempDir()
+	dir := filepath.Join(tmp, "resume")
+	path := filepath.Join(dir, "resume.json")
+
+	st := &resumeState{Type: "resume_state", App: "com.example.myapp"}
+	require.NoError(t, saveResumeState(path, st))
+
+	dirInfo, err := os.Stat(dir)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o700), dirInfo.Mode().Perm())
+
+	fileInfo, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), fileInfo.Mode().Perm())
+}
+
+func TestSaveResumeStateRefusesNewerSchemaVersion(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "resume.json")
+
+	require.NoError(t, saveResumeState(path, &resumeState{Type: "resume_state", App: "com.example.myapp", SchemaVersion: currentResumeStateSchemaVersion + 1}))
+
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	err = saveResumeState(path, &resumeState{Type: "resume_state", App: "com.example.myapp"})
+	require.ErrorIs(t, err, errResumeStateTooNew)
+
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, before, after, "a too-new file on disk must not be overwritten")
+}
+
+func TestSaveResumeStatePartialWriteNeverReplacesGoodFile(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "resume.json")
+
+	st := &resumeState{Type: "resume_state", App: "com.example.myapp"}
+	require.NoError(t, saveResumeState(path, st))
+	good, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	original := writeResumeStateTempFile
+	defer func() { writeResumeStateTempFile = original }()
+	writeResumeStateTempFile = func(tmpPath string, b []byte) error {
+		if len(b) > 4 {
+			b = b[:4]
+		}
+		if err := os.WriteFile(tmpPath, b, 0o600); err != nil {
+			return err
+		}
+		return errors.New("simulated disk-full mid-write")
+	}
+
+	err = saveResumeState(path, st)
+	require.Error(t, err)
+
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, good, after, "a failed write must never replace the good file")
+
+	_, err = os.Stat(path + ".tmp")
+	require.True(t, os.IsNotExist(err), "the partial tempfile must be cleaned up")
+}
+
+func TestLoadResumeStateQuarantinesCorruptFile(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "resume.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"app": not-json`), 0o600))
+
+	loaded, err := loadResumeState(path)
+	require.NoError(t, err)
+	require.Nil(t, loaded)
+
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err), "corrupt file must be moved aside, not left at path")
+
+	matches, err := filepath.Glob(path + ".corrupt-*")
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+}
+
 func TestParseRFC3339Any(t *testing.T) {
 	t.Run("empty", func(t *testing.T) {
 		got, err := parseRFC3339Any("")
@@ -4,19 +4,32 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vburojevic/xcw/internal/retention"
 )
 
 // rotation manages per-session file rotation for tail.
 type rotation struct {
-	pathBuilder    func(int) (string, error)
-	outputFile     *os.File
-	bufferedWriter *bufio.Writer
+	pathBuilder     func(int) (string, error)
+	retentionPolicy retention.Policy
+	outputFile      *os.File
+	bufferedWriter  *bufio.Writer
 }
 
 func newRotation(pb func(int) (string, error)) *rotation {
 	return &rotation{pathBuilder: pb}
 }
 
+// SetRetentionPolicy arms rotation to prune sibling files in the same
+// directory as the path pathBuilder produces every time Open rolls to a
+// new session. The zero value (the default if this is never called) keeps
+// every rotated file, matching rotation's pre-retention behavior.
+func (r *rotation) SetRetentionPolicy(p retention.Policy) {
+	r.retentionPolicy = p
+}
+
 func (r *rotation) Open(session int) (writer *bufio.Writer, file *os.File, path string, err error) {
 	if r.pathBuilder == nil {
 		return nil, nil, "", nil
@@ -39,9 +52,65 @@ func (r *rotation) Open(session int) (writer *bufio.Writer, file *os.File, path
 		return nil, nil, "", fmt.Errorf("failed to create output file: %w", err)
 	}
 	r.bufferedWriter = bufio.NewWriter(r.outputFile)
+
+	r.pruneSiblings(path)
+
 	return r.bufferedWriter, r.outputFile, path, nil
 }
 
+// pruneSiblings applies retentionPolicy to just's rotated siblings (just is
+// always kept - it's the file Open just created for the session currently
+// being written, never a deletion candidate). Candidates are restricted to
+// files sharing just's base-name prefix, the same way
+// output.RotatingFileSink.pruneLocked scopes its own directory scan - so
+// pointing --log-file at a directory that also holds unrelated files never
+// puts those files at risk of being pruned.
+func (r *rotation) pruneSiblings(just string) {
+	if r.retentionPolicy == (retention.Policy{}) {
+		return
+	}
+
+	dir := filepath.Dir(just)
+	prefix := rotationPrefix(filepath.Base(just))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var candidates []retention.Candidate
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		p := filepath.Join(dir, name)
+		if p == just {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, retention.Candidate{Path: p, Size: info.Size(), ModTime: info.ModTime()})
+	}
+
+	for _, c := range retention.Select(r.retentionPolicy, candidates) {
+		os.Remove(c.Path)
+	}
+}
+
+// rotationPrefix derives the shared base-name prefix for name's log family:
+// its extension and any trailing session-number suffix (digits, dashes, and
+// underscores) are stripped, so "session-3.log" and "session.log" both
+// yield "session" and match each other as siblings of the same rotation.
+func rotationPrefix(name string) string {
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.TrimRight(stem, "0123456789-_")
+}
+
 func (r *rotation) Close() {
 	if r.bufferedWriter != nil {
 		r.bufferedWriter.Flush()
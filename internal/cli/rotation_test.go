@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vburojevic/xcw/internal/retention"
+)
+
+func TestRotationPruneSiblingsKeepsJustOpenedFile(t *testing.T) {
+	dir := t.TempDir()
+
+	for i := 1; i <= 3; i++ {
+		p := filepath.Join(dir, fmt.Sprintf("session-%d.log", i))
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		old := time.Now().Add(-time.Hour * time.Duration(i))
+		if err := os.Chtimes(p, old, old); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	r := newRotation(func(session int) (string, error) {
+		return filepath.Join(dir, "session.log"), nil
+	})
+	r.SetRetentionPolicy(retention.Policy{MaxFiles: 1})
+
+	_, _, path, err := r.Open(1)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	// MaxFiles bounds the sibling candidates pruneSiblings considers, not
+	// the just-opened file itself, so one sibling (the most recently
+	// modified) survives alongside it.
+	if len(entries) != 2 {
+		t.Fatalf("expected the just-opened file plus one surviving sibling, got %v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "session-1.log")); err != nil {
+		t.Fatalf("expected the most recently modified sibling to survive: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected the just-opened file to survive: %v", err)
+	}
+}
+
+func TestRotationPruneSiblingsIgnoresUnrelatedFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	unrelated := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(unrelated, []byte("keep me"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(unrelated, old, old); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	r := newRotation(func(session int) (string, error) {
+		return filepath.Join(dir, "session.log"), nil
+	})
+	r.SetRetentionPolicy(retention.Policy{MaxFiles: 1})
+
+	if _, _, _, err := r.Open(1); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Fatalf("expected unrelated file to survive pruning, got: %v", err)
+	}
+}
+
+func TestRotationWithoutRetentionPolicyKeepsEverything(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "old.log"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	r := newRotation(func(session int) (string, error) {
+		return filepath.Join(dir, "session.log"), nil
+	})
+
+	if _, _, _, err := r.Open(1); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected both files to survive with no retention policy set, got %v", entries)
+	}
+}
@@ -3,39 +3,55 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 )
 
-// SchemaCmd outputs JSON Schema for xcw output types
+// schemaAllTypes lists every schema type --type can select, and what a bare
+// `xcw schema` with no --type outputs.
+var schemaAllTypes = []string{
+	"log", "summary", "heartbeat", "session_heartbeat", "config_reload",
+	"prune_candidate", "config_validation_issue", "error", "tmux", "service",
+	"trigger",
+}
+
+// asyncAPIChannelTypes is the fixed set of NDJSON types `--format asyncapi`
+// documents on the xcw/ndjson channel. Other types xcw emits - watch's
+// session_heartbeat, service and config_reload, and one-off CLI output like
+// config_validation_issue or prune_candidate - are not in the channel's
+// message list.
+var asyncAPIChannelTypes = []string{"log", "summary", "heartbeat", "error", "tmux", "trigger"}
+
+// SchemaCmd outputs JSON Schema (or an AsyncAPI/CloudEvents/TypeScript
+// rendering of it) for xcw's NDJSON output types.
 type SchemaCmd struct {
-	Type []string `short:"t" help:"Output types to include (log,summary,heartbeat,error,tmux). Default: all"`
+	Type   []string `short:"t" help:"Output types to include (log,summary,heartbeat,session_heartbeat,config_reload,prune_candidate,config_validation_issue,error,tmux,service,trigger). Default: all"`
+	Format string   `short:"f" default:"jsonschema" enum:"jsonschema,asyncapi,cloudevents,typescript" help:"Output as raw JSON Schema fragments (default), a single AsyncAPI 2.6 document, CloudEvents-wrapped JSON Schema, or generated TypeScript types"`
 }
 
 // Run executes the schema command
 func (c *SchemaCmd) Run(globals *Globals) error {
 	schemas := map[string]interface{}{
-		"log":       logSchema(),
-		"summary":   summarySchema(),
-		"heartbeat": heartbeatSchema(),
-		"error":     errorSchema(),
-		"tmux":      tmuxSchema(),
+		"log":                     logSchema(),
+		"summary":                 summarySchema(),
+		"heartbeat":               heartbeatSchema(),
+		"session_heartbeat":       sessionHeartbeatSchema(),
+		"config_reload":           configReloadSchema(),
+		"prune_candidate":         pruneCandidateSchema(),
+		"config_validation_issue": configValidationIssueSchema(),
+		"error":                   errorSchema(),
+		"tmux":                    tmuxSchema(),
+		"service":                 serviceSchema(),
+		"trigger":                 triggerSchema(),
 	}
 
 	// Determine which schemas to output
 	typesToOutput := c.Type
 	if len(typesToOutput) == 0 {
-		typesToOutput = []string{"log", "summary", "heartbeat", "error", "tmux"}
+		typesToOutput = schemaAllTypes
 	}
 
-	// Build output
-	output := map[string]interface{}{
-		"$schema":     "http://json-schema.org/draft-07/schema#",
-		"title":       "XcodeConsoleWatcher Output Schemas",
-		"description": "JSON Schema definitions for all xcw NDJSON output types",
-		"definitions": map[string]interface{}{},
-	}
-
-	defs := output["definitions"].(map[string]interface{})
+	defs := map[string]interface{}{}
 	for _, t := range typesToOutput {
 		t = strings.ToLower(strings.TrimSpace(t))
 		if schema, ok := schemas[t]; ok {
@@ -43,10 +59,251 @@ func (c *SchemaCmd) Run(globals *Globals) error {
 		}
 	}
 
-	// Output as JSON
+	switch strings.ToLower(strings.TrimSpace(c.Format)) {
+	case "", "jsonschema":
+		return c.outputJSON(globals, jsonSchemaDocument(defs))
+	case "asyncapi":
+		return c.outputJSON(globals, asyncAPIDocument(defs))
+	case "cloudevents":
+		return c.outputJSON(globals, cloudEventsDocument(defs))
+	case "typescript":
+		fmt.Fprint(globals.Stdout, typeScriptDocument(defs))
+		return nil
+	default:
+		return outputErrorCommon(globals, "INVALID_SCHEMA_FORMAT", fmt.Sprintf("unknown --format %q (want jsonschema, asyncapi, cloudevents, or typescript)", c.Format))
+	}
+}
+
+// outputJSON indent-encodes doc to globals.Stdout, the same rendering
+// every --format has always used for its JSON-shaped output.
+func (c *SchemaCmd) outputJSON(globals *Globals, doc interface{}) error {
 	encoder := json.NewEncoder(globals.Stdout)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(output)
+	return encoder.Encode(doc)
+}
+
+// jsonSchemaDocument is the default `--format jsonschema` rendering: the
+// raw per-type JSON Schema fragments, keyed by type name.
+func jsonSchemaDocument(defs map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       "XcodeConsoleWatcher Output Schemas",
+		"description": "JSON Schema definitions for all xcw NDJSON output types",
+		"definitions": defs,
+	}
+}
+
+// asyncAPIDocument wraps defs into a single AsyncAPI 2.6 document declaring
+// one channel ("xcw/ndjson") whose message is a oneOf over whichever of
+// asyncAPIChannelTypes are present in defs (i.e. respecting --type).
+func asyncAPIDocument(defs map[string]interface{}) map[string]interface{} {
+	messages := map[string]interface{}{}
+	var oneOf []interface{}
+	for _, name := range asyncAPIChannelTypes {
+		schema, ok := defs[name]
+		if !ok {
+			continue
+		}
+		messages[name] = map[string]interface{}{
+			"name":    name,
+			"title":   name,
+			"payload": schema,
+		}
+		oneOf = append(oneOf, map[string]interface{}{"$ref": "#/components/messages/" + name})
+	}
+
+	return map[string]interface{}{
+		"asyncapi": "2.6.0",
+		"info": map[string]interface{}{
+			"title":       "XcodeConsoleWatcher NDJSON Stream",
+			"version":     "1.0.0",
+			"description": "NDJSON events emitted by `xcw watch`/`xcw replay` to stdout",
+		},
+		"channels": map[string]interface{}{
+			"xcw/ndjson": map[string]interface{}{
+				"subscribe": map[string]interface{}{
+					"summary": "Consume xcw's NDJSON output stream",
+					"message": map[string]interface{}{
+						"oneOf": oneOf,
+					},
+				},
+			},
+		},
+		"components": map[string]interface{}{
+			"messages": messages,
+		},
+	}
+}
+
+// cloudEventsDocument re-emits each schema in defs wrapped in a CloudEvents
+// 1.0 envelope, matching what `watch --cloudevents` actually puts on the
+// wire for that type.
+func cloudEventsDocument(defs map[string]interface{}) map[string]interface{} {
+	wrapped := map[string]interface{}{}
+	for name, schema := range defs {
+		wrapped[name] = cloudEventEnvelopeSchema(name, schema)
+	}
+	return map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       "XcodeConsoleWatcher CloudEvents Envelopes",
+		"description": "Each xcw NDJSON output type wrapped as a CloudEvents 1.0 envelope, as emitted by `watch --cloudevents`",
+		"definitions": wrapped,
+	}
+}
+
+// cloudEventEnvelopeSchema builds the envelope schema for a single type -
+// CloudEvents' required attributes plus a "data" property holding dataSchema
+// verbatim.
+func cloudEventEnvelopeSchema(name string, dataSchema interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":  "object",
+		"title": fmt.Sprintf("%s (CloudEvents envelope)", name),
+		"properties": map[string]interface{}{
+			"specversion": map[string]interface{}{
+				"type":        "string",
+				"const":       "1.0",
+				"description": "CloudEvents spec version",
+			},
+			"type": map[string]interface{}{
+				"type":        "string",
+				"const":       "dev.xcw." + name,
+				"description": "CloudEvents event type",
+			},
+			"source": map[string]interface{}{
+				"type":        "string",
+				"description": "URI identifying the xcw process that emitted this event, e.g. xcw://<hostname>/watch",
+			},
+			"id": map[string]interface{}{
+				"type":        "string",
+				"description": "Unique event identifier",
+			},
+			"time": map[string]interface{}{
+				"type":        "string",
+				"format":      "date-time",
+				"description": "ISO8601 timestamp the event was emitted",
+			},
+			"datacontenttype": map[string]interface{}{
+				"type":        "string",
+				"const":       "application/json",
+				"description": "Media type of data",
+			},
+			"data": dataSchema,
+		},
+		"required": []string{"specversion", "type", "source", "id", "time", "datacontenttype", "data"},
+	}
+}
+
+// typeScriptDocument generates a discriminated-union TypeScript rendering
+// of defs: one interface per type plus a union type over all of them, so
+// schema, docs, and generated bindings are derived from the same
+// definitions map rather than hand-maintained separately.
+func typeScriptDocument(defs map[string]interface{}) string {
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("// Generated by `xcw schema --format typescript`. Do not edit by hand.\n\n")
+
+	var unionNames []string
+	for _, name := range names {
+		schema, ok := defs[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ifaceName := tsInterfaceName(name)
+		unionNames = append(unionNames, ifaceName)
+		b.WriteString(tsInterface(ifaceName, schema))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("export type XcwEvent =\n")
+	for i, name := range unionNames {
+		sep := " |"
+		if i == len(unionNames)-1 {
+			sep = ";"
+		}
+		fmt.Fprintf(&b, "  %s%s\n", name, sep)
+	}
+	return b.String()
+}
+
+// tsInterface renders one JSON Schema object fragment as a TS interface,
+// marking every property not listed in "required" as optional.
+func tsInterface(name string, schema map[string]interface{}) string {
+	props, _ := schema["properties"].(map[string]interface{})
+	required, _ := schema["required"].([]string)
+	isRequired := make(map[string]bool, len(required))
+	for _, r := range required {
+		isRequired[r] = true
+	}
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "export interface %s {\n", name)
+	for _, k := range keys {
+		prop, _ := props[k].(map[string]interface{})
+		optional := ""
+		if !isRequired[k] {
+			optional = "?"
+		}
+		fmt.Fprintf(&b, "  %s%s: %s;\n", k, optional, tsType(prop))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// tsType maps a single JSON Schema property fragment to a TypeScript type,
+// preferring a literal type over "const"/"enum" so a discriminant field
+// (e.g. "type") narrows the union the way a real discriminated union needs.
+func tsType(prop map[string]interface{}) string {
+	if c, ok := prop["const"].(string); ok {
+		return fmt.Sprintf("%q", c)
+	}
+	if enum, ok := prop["enum"].([]string); ok && len(enum) > 0 {
+		quoted := make([]string, len(enum))
+		for i, v := range enum {
+			quoted[i] = fmt.Sprintf("%q", v)
+		}
+		return strings.Join(quoted, " | ")
+	}
+
+	switch prop["type"] {
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		items, _ := prop["items"].(map[string]interface{})
+		return tsType(items) + "[]"
+	case "object":
+		return "Record<string, unknown>"
+	case "string":
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// tsInterfaceName converts a snake_case schema type name (e.g.
+// "session_heartbeat") to a PascalCase TS interface name
+// ("SessionHeartbeat").
+func tsInterfaceName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		parts[i] = strings.ToUpper(p[:1]) + p[1:]
+	}
+	return strings.Join(parts, "")
 }
 
 func logSchema() map[string]interface{} {
@@ -179,6 +436,141 @@ func heartbeatSchema() map[string]interface{} {
 	}
 }
 
+func sessionHeartbeatSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "object",
+		"title":       "Session Heartbeat",
+		"description": "Periodic snapshot of the currently tracked app session, emitted by `watch --heartbeat`",
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type":  "string",
+				"const": "session_heartbeat",
+			},
+			"session": map[string]interface{}{
+				"type":        "integer",
+				"description": "Session number this heartbeat belongs to",
+			},
+			"pid": map[string]interface{}{
+				"type":        "integer",
+				"description": "Current process ID",
+			},
+			"elapsed_seconds": map[string]interface{}{
+				"type":        "integer",
+				"description": "Seconds since the session started",
+			},
+			"total_logs": map[string]interface{}{
+				"type":        "integer",
+				"description": "Logs seen so far this session",
+			},
+			"errors": map[string]interface{}{
+				"type":        "integer",
+				"description": "Error-level logs seen so far this session",
+			},
+			"faults": map[string]interface{}{
+				"type":        "integer",
+				"description": "Fault-level logs seen so far this session",
+			},
+			"logs_per_second": map[string]interface{}{
+				"type":        "number",
+				"description": "EWMA-smoothed logs/sec rate",
+			},
+			"timestamp": map[string]interface{}{
+				"type":        "string",
+				"format":      "date-time",
+				"description": "ISO8601 timestamp of the heartbeat",
+			},
+		},
+		"required": []string{"type", "session", "pid", "elapsed_seconds", "total_logs", "errors", "faults", "logs_per_second", "timestamp"},
+	}
+}
+
+func configReloadSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "object",
+		"title":       "Config Reload",
+		"description": "Emitted when a running command picks up a live xcw.yaml change",
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type":  "string",
+				"const": "config_reload",
+			},
+			"changed": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Dotted field paths that changed, e.g. defaults.heartbeat",
+			},
+			"timestamp": map[string]interface{}{
+				"type":        "string",
+				"format":      "date-time",
+				"description": "ISO8601 timestamp of the reload",
+			},
+		},
+		"required": []string{"type", "changed", "timestamp"},
+	}
+}
+
+func pruneCandidateSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "object",
+		"title":       "Prune Candidate",
+		"description": "A rotated file `xcw prune` reported (and, with --apply, deleted) for exceeding the retention policy",
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type":  "string",
+				"const": "prune_candidate",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the candidate file",
+			},
+			"size": map[string]interface{}{
+				"type":        "integer",
+				"description": "File size in bytes",
+			},
+			"age_seconds": map[string]interface{}{
+				"type":        "integer",
+				"description": "Seconds since the file (or resume-state's updated_at) was last modified",
+			},
+			"deleted": map[string]interface{}{
+				"type":        "boolean",
+				"description": "True if --apply actually removed this file",
+			},
+		},
+		"required": []string{"type", "path", "size", "age_seconds", "deleted"},
+	}
+}
+
+func configValidationIssueSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "object",
+		"title":       "Config Validation Issue",
+		"description": "A semantic mistake `xcw config validate` found in a config file",
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type":  "string",
+				"const": "config_validation_issue",
+			},
+			"field_path": map[string]interface{}{
+				"type":        "string",
+				"description": "Dotted path to the offending field, e.g. defaults.level",
+			},
+			"value": map[string]interface{}{
+				"type":        "string",
+				"description": "The value that failed validation",
+			},
+			"reason": map[string]interface{}{
+				"type":        "string",
+				"description": "Why the value is invalid",
+			},
+			"suggestion": map[string]interface{}{
+				"type":        "string",
+				"description": "A fuzzy-matched suggested replacement, if one scored close enough",
+			},
+		},
+		"required": []string{"type", "field_path", "value", "reason"},
+	}
+}
+
 func errorSchema() map[string]interface{} {
 	return map[string]interface{}{
 		"type":        "object",
@@ -236,15 +628,73 @@ func tmuxSchema() map[string]interface{} {
 	}
 }
 
+func serviceSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "object",
+		"title":       "Service",
+		"description": "Emitted by `watch` when its systemd/launchd readiness state changes",
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type":  "string",
+				"const": "service",
+			},
+			"state": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"ready", "stopping"},
+				"description": "The new readiness state",
+			},
+			"timestamp": map[string]interface{}{
+				"type":        "string",
+				"format":      "date-time",
+				"description": "ISO8601 timestamp of the state change",
+			},
+		},
+		"required": []string{"type", "state", "timestamp"},
+	}
+}
+
+func triggerSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "object",
+		"title":       "Trigger",
+		"description": "Emitted when a `watch --trigger` rule fires and runs its sink",
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type":  "string",
+				"const": "trigger",
+			},
+			"trigger": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the trigger rule that fired",
+			},
+			"command": map[string]interface{}{
+				"type":        "string",
+				"description": "Human-readable label for what the trigger ran, e.g. the exec command or sink target",
+			},
+			"message": map[string]interface{}{
+				"type":        "string",
+				"description": "The log message that caused the trigger to fire",
+			},
+		},
+		"required": []string{"type", "trigger", "command", "message"},
+	}
+}
+
 // Helper to output a quick reference
 func (c *SchemaCmd) outputTextHelp(globals *Globals) {
 	fmt.Fprintln(globals.Stdout, "XcodeConsoleWatcher Output Types:")
 	fmt.Fprintln(globals.Stdout, "")
-	fmt.Fprintln(globals.Stdout, "  log       - Log entry from simulator")
-	fmt.Fprintln(globals.Stdout, "  summary   - Periodic log statistics")
-	fmt.Fprintln(globals.Stdout, "  heartbeat - Keepalive message")
-	fmt.Fprintln(globals.Stdout, "  error     - Error from xcw")
-	fmt.Fprintln(globals.Stdout, "  tmux      - Tmux session info")
+	fmt.Fprintln(globals.Stdout, "  log               - Log entry from simulator")
+	fmt.Fprintln(globals.Stdout, "  summary           - Periodic log statistics")
+	fmt.Fprintln(globals.Stdout, "  heartbeat         - Keepalive message")
+	fmt.Fprintln(globals.Stdout, "  session_heartbeat - Periodic session snapshot (watch --heartbeat)")
+	fmt.Fprintln(globals.Stdout, "  config_reload     - Live xcw.yaml reload (watch --app, picked up automatically)")
+	fmt.Fprintln(globals.Stdout, "  prune_candidate   - Rotated file xcw prune reported or deleted")
+	fmt.Fprintln(globals.Stdout, "  error             - Error from xcw")
+	fmt.Fprintln(globals.Stdout, "  tmux              - Tmux session info")
+	fmt.Fprintln(globals.Stdout, "  service           - systemd/launchd readiness state change (watch)")
+	fmt.Fprintln(globals.Stdout, "  trigger           - watch --trigger rule fired")
 	fmt.Fprintln(globals.Stdout, "")
 	fmt.Fprintln(globals.Stdout, "Use --type to filter: xcw schema --type log,error")
+	fmt.Fprintln(globals.Stdout, "Use --format to render as asyncapi, cloudevents, or typescript instead of raw jsonschema")
 }
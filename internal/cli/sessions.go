@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/vburojevic/xcw/internal/domain"
+	"github.com/vburojevic/xcw/internal/output"
+)
+
+// SessionsCmd groups subcommands for working with recordings produced by
+// `xcw watch --record`.
+type SessionsCmd struct {
+	Replay SessionsReplayCmd `cmd:"" help:"Replay a recorded session tarball"`
+}
+
+// SessionsReplayCmd replays a session-<n>.tar.gz produced by `xcw watch
+// --record` through the same text/NDJSON writers used for live tailing,
+// without touching a simulator.
+type SessionsReplayCmd struct {
+	Tarball string  `arg:"" help:"Path to a session-<n>.tar.gz produced by 'watch --record'"`
+	Speed   float64 `default:"0" help:"Pace output to the recording's original wall-clock spacing at this multiplier (0 = as fast as possible)"`
+}
+
+// Run executes the sessions replay command.
+func (c *SessionsReplayCmd) Run(globals *Globals) error {
+	entries, err := extractSessionEntries(c.Tarball)
+	if err != nil {
+		return outputErrorCommon(globals, "SESSION_REPLAY_FAILED", err.Error())
+	}
+
+	var writer interface {
+		Write(entry *domain.LogEntry) error
+	}
+	if globals.Format == "ndjson" {
+		writer = output.NewNDJSONWriter(globals.Stdout)
+	} else {
+		writer = output.NewTextWriter(globals.Stdout)
+	}
+
+	if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "Replaying %d entries from %s\n", len(entries), c.Tarball)
+	}
+
+	var prev time.Time
+	for i := range entries {
+		if c.Speed > 0 && i > 0 {
+			gap := entries[i].Timestamp.Sub(prev)
+			if gap > 0 {
+				time.Sleep(time.Duration(float64(gap) / c.Speed))
+			}
+		}
+		if err := writer.Write(&entries[i]); err != nil {
+			return err
+		}
+		prev = entries[i].Timestamp
+	}
+
+	if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "Replayed %d entries\n", len(entries))
+	}
+	return nil
+}
+
+// extractSessionEntries reads entries.ndjson out of a recorded session
+// tarball without extracting the rest of the archive to disk.
+func extractSessionEntries(tarballPath string) ([]domain.LogEntry, error) {
+	f, err := os.Open(tarballPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", tarballPath, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", tarballPath, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", tarballPath, err)
+		}
+		if filepath.Base(hdr.Name) != "entries.ndjson" {
+			continue
+		}
+		return decodeNDJSONEntries(tr)
+	}
+	return nil, fmt.Errorf("entries.ndjson not found in %s", tarballPath)
+}
+
+func decodeNDJSONEntries(r io.Reader) ([]domain.LogEntry, error) {
+	var entries []domain.LogEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry domain.LogEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseSize parses a human-friendly size string (e.g. "10", "10MB", "1GB")
+// into a whole number of megabytes. Fractional inputs (e.g. "100KB") round
+// up to a 1 MB minimum so rotation thresholds are never silently disabled.
+func parseSize(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("size is required")
+	}
+
+	upper := strings.ToUpper(s)
+	var unit string
+	var numPart string
+	switch {
+	case strings.HasSuffix(upper, "GB"):
+		unit, numPart = "GB", s[:len(s)-2]
+	case strings.HasSuffix(upper, "MB"):
+		unit, numPart = "MB", s[:len(s)-2]
+	case strings.HasSuffix(upper, "KB"):
+		unit, numPart = "KB", s[:len(s)-2]
+	case strings.HasSuffix(upper, "G"):
+		unit, numPart = "GB", s[:len(s)-1]
+	case strings.HasSuffix(upper, "M"):
+		unit, numPart = "MB", s[:len(s)-1]
+	case strings.HasSuffix(upper, "K"):
+		unit, numPart = "KB", s[:len(s)-1]
+	default:
+		unit, numPart = "MB", s
+	}
+
+	numPart = strings.TrimSpace(numPart)
+	if numPart == "" {
+		return 0, fmt.Errorf("invalid size %q: missing numeric value", s)
+	}
+
+	n, err := strconv.Atoi(numPart)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("invalid size %q: must not be negative", s)
+	}
+
+	switch unit {
+	case "GB":
+		return n * 1024, nil
+	case "KB":
+		if n == 0 {
+			return 0, nil
+		}
+		mb := n / 1024
+		if n%1024 != 0 {
+			mb++
+		}
+		if mb < 1 {
+			mb = 1
+		}
+		return mb, nil
+	default: // MB
+		return n, nil
+	}
+}
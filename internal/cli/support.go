@@ -0,0 +1,391 @@
+package cli
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vburojevic/xcw/internal/session"
+)
+
+// SupportCmd groups commands that help a user produce a diagnostic
+// artifact to attach to a bug report instead of hand-copying terminal
+// output and simulator state.
+type SupportCmd struct {
+	Dump SupportDumpCmd `cmd:"" help:"Collect config, recent sessions, tmux/simulator state, and logs into one bundle"`
+}
+
+// SupportDumpCmd collects a reproducible snapshot of xcw's environment:
+// resolved config, the most recent recorded session summaries, a tmux
+// inventory plus scrollback of the active pane, `xcrun simctl list
+// devices`, environment/version info, and recent agent-logger debug
+// lines.
+type SupportDumpCmd struct {
+	Output      string   `arg:"" optional:"" help:"Path to write the bundle to (default: xcw-support-<timestamp>.zip); '-' writes to stdout"`
+	RecordDir   string   `help:"Directory of session-<n>.tar.gz recordings to pull summaries from (default: ~/.xcw/sessions)"`
+	Sessions    int      `help:"Maximum number of recent session summaries to include" default:"5"`
+	DebugLines  int      `help:"Maximum number of trailing agent-logger debug lines to include" default:"500"`
+	TmuxSession string   `help:"tmux session name to capture scrollback from" default:"xcw"`
+	Redact      bool     `help:"Scrub bundle IDs and UDIDs from the bundle before writing it"`
+	RedactAllow []string `help:"Bundle IDs/UDIDs exempt from --redact scrubbing"`
+
+	// foundUDIDs/foundBundleIDs accumulate the actual identifiers seen
+	// while collecting simctl-devices.json and sessions.json, so --redact
+	// scrubs the UDIDs/bundle IDs this specific bundle contains rather
+	// than only the config's static defaults. Run-scoped state, not a
+	// flag - kong only maps exported fields, so it's invisible to CLI
+	// parsing.
+	foundUDIDs     []string
+	foundBundleIDs []string
+}
+
+// Run executes the support dump command.
+func (c *SupportDumpCmd) Run(globals *Globals) error {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+
+	c.addConfig(globals, zw)
+	c.addSessions(zw)
+	c.addTmuxInventory(zw)
+	c.addSimulators(zw)
+	c.addEnvironment(zw)
+	c.addDebugLog(zw)
+
+	if err := zw.Close(); err != nil {
+		return outputErrorCommon(globals, "SUPPORT_DUMP_FAILED", fmt.Sprintf("finalizing bundle: %v", err))
+	}
+
+	out := buf.Bytes()
+	if c.Redact {
+		out = redactZip(out, c.redactTerms(globals))
+	}
+
+	if c.Output == "-" {
+		_, err := globals.Stdout.Write(out)
+		return err
+	}
+
+	path := c.Output
+	if path == "" {
+		path = fmt.Sprintf("xcw-support-%s.zip", time.Now().UTC().Format("20060102T150405Z"))
+	}
+	if err := os.WriteFile(path, out, 0o600); err != nil {
+		return outputErrorCommon(globals, "SUPPORT_DUMP_FAILED", fmt.Sprintf("writing %s: %v", path, err))
+	}
+	if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "Wrote support bundle to %s\n", path)
+	}
+	return nil
+}
+
+// addConfig writes the resolved config (defaults merged with any
+// xcw.yaml/env overrides) as config.json.
+func (c *SupportDumpCmd) addConfig(globals *Globals, zw *zip.Writer) {
+	b, err := json.MarshalIndent(globals.Config, "", "  ")
+	if err != nil {
+		writeZipError(zw, "config.json", err)
+		return
+	}
+	writeZipFile(zw, "config.json", b)
+}
+
+// addSessions pulls summary.json out of the c.Sessions most recently
+// modified session-<n>.tar.gz files under RecordDir (or ~/.xcw/sessions)
+// into sessions.json - the same SessionStart/SessionEnd-shaped data `xcw
+// watch --record` already writes per session, just gathered up.
+func (c *SupportDumpCmd) addSessions(zw *zip.Writer) {
+	dir := c.RecordDir
+	if dir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, ".xcw", "sessions")
+		}
+	}
+
+	tarballs, err := recentSessionTarballs(dir, c.Sessions)
+	if err != nil {
+		writeZipError(zw, "sessions.json", err)
+		return
+	}
+
+	var summaries []json.RawMessage
+	for _, path := range tarballs {
+		b, err := session.ReadSummary(path)
+		if err != nil {
+			continue
+		}
+		summaries = append(summaries, b)
+
+		var parsed session.RecordSummary
+		if err := json.Unmarshal(b, &parsed); err == nil {
+			if parsed.App != "" {
+				c.foundBundleIDs = append(c.foundBundleIDs, parsed.App)
+			}
+			if parsed.UDID != "" {
+				c.foundUDIDs = append(c.foundUDIDs, parsed.UDID)
+			}
+		}
+	}
+
+	b, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		writeZipError(zw, "sessions.json", err)
+		return
+	}
+	writeZipFile(zw, "sessions.json", b)
+}
+
+// recentSessionTarballs returns up to limit session-<n>.tar.gz paths
+// under dir, newest first.
+func recentSessionTarballs(dir string, limit int) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	type candidate struct {
+		path    string
+		modTime time.Time
+	}
+	var candidates []candidate
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "session-") || !strings.HasSuffix(e.Name(), ".tar.gz") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{filepath.Join(dir, e.Name()), info.ModTime()})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].modTime.After(candidates[j].modTime) })
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	paths := make([]string, len(candidates))
+	for i, c := range candidates {
+		paths[i] = c.path
+	}
+	return paths, nil
+}
+
+// addTmuxInventory captures `tmux list-sessions`/`list-windows`/
+// `list-panes` and a full-scrollback `capture-pane` of TmuxSession's
+// active pane. It shells out to the tmux binary directly rather than
+// going through tmux.Manager, since a support dump only needs read-only
+// introspection, not the pane-writing Manager exists for.
+func (c *SupportDumpCmd) addTmuxInventory(zw *zip.Writer) {
+	var b strings.Builder
+
+	for _, args := range [][]string{
+		{"list-sessions"},
+		{"list-windows", "-a"},
+		{"list-panes", "-a"},
+	} {
+		out, err := exec.Command("tmux", args...).CombinedOutput()
+		fmt.Fprintf(&b, "$ tmux %s\n", strings.Join(args, " "))
+		if err != nil {
+			fmt.Fprintf(&b, "(error: %v)\n\n", err)
+			continue
+		}
+		b.Write(out)
+		b.WriteString("\n")
+	}
+	writeZipFile(zw, "tmux-inventory.txt", []byte(b.String()))
+
+	// A bare session name targets that session's active pane.
+	paneTarget := c.TmuxSession
+	scrollback, err := exec.Command("tmux", "capture-pane", "-p", "-S", "-", "-t", paneTarget).Output()
+	if err != nil {
+		writeZipError(zw, "tmux-pane-scrollback.txt", err)
+		return
+	}
+	writeZipFile(zw, "tmux-pane-scrollback.txt", scrollback)
+}
+
+// addSimulators captures `xcrun simctl list devices --json` verbatim.
+func (c *SupportDumpCmd) addSimulators(zw *zip.Writer) {
+	out, err := exec.Command("xcrun", "simctl", "list", "devices", "--json").Output()
+	if err != nil {
+		writeZipError(zw, "simctl-devices.json", err)
+		return
+	}
+	writeZipFile(zw, "simctl-devices.json", out)
+
+	var parsed struct {
+		Devices map[string][]struct {
+			UDID string `json:"udid"`
+		} `json:"devices"`
+	}
+	if err := json.Unmarshal(out, &parsed); err == nil {
+		for _, devices := range parsed.Devices {
+			for _, d := range devices {
+				if d.UDID != "" {
+					c.foundUDIDs = append(c.foundUDIDs, d.UDID)
+				}
+			}
+		}
+	}
+}
+
+// addEnvironment captures GOOS/GOARCH, xcw's own version/commit, and the
+// installed tmux version.
+func (c *SupportDumpCmd) addEnvironment(zw *zip.Writer) {
+	env := struct {
+		GOOS        string `json:"goos"`
+		GOARCH      string `json:"goarch"`
+		XcwVersion  string `json:"xcw_version"`
+		XcwCommit   string `json:"xcw_commit"`
+		TmuxVersion string `json:"tmux_version"`
+	}{
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		XcwVersion: Version,
+		XcwCommit:  Commit,
+	}
+	if out, err := exec.Command("tmux", "-V").Output(); err == nil {
+		env.TmuxVersion = strings.TrimSpace(string(out))
+	}
+
+	b, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		writeZipError(zw, "environment.json", err)
+		return
+	}
+	writeZipFile(zw, "environment.json", b)
+}
+
+// addDebugLog copies the trailing DebugLines lines of ~/.xcw/debug.log
+// (the file agentLogger tees its verbose output to), if any exist yet.
+func (c *SupportDumpCmd) addDebugLog(zw *zip.Writer) {
+	path := defaultDebugLogPath()
+	if path == "" {
+		return
+	}
+	lines, err := tailLines(path, c.DebugLines)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			writeZipError(zw, "debug.log", err)
+		}
+		return
+	}
+	writeZipFile(zw, "debug.log", []byte(strings.Join(lines, "\n")))
+}
+
+// tailLines reads path and returns its last n lines (all of them if n<=0).
+func tailLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// redactTerms is every value --redact should scrub: the resolved config's
+// bundle ID/UDID defaults, plus every UDID and bundle ID actually
+// encountered while building this bundle (simctl-devices.json and
+// sessions.json) - so --redact still scrubs real device identifiers for a
+// user invoking xcw with -s/-a flags rather than config-file defaults -
+// minus whatever --redact-allow exempted.
+func (c *SupportDumpCmd) redactTerms(globals *Globals) []string {
+	allow := make(map[string]bool, len(c.RedactAllow))
+	for _, a := range c.RedactAllow {
+		allow[a] = true
+	}
+
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(s string) {
+		if s == "" || allow[s] || seen[s] {
+			return
+		}
+		seen[s] = true
+		terms = append(terms, s)
+	}
+	if globals != nil && globals.Config != nil {
+		add(globals.Config.Defaults.Simulator)
+		add(globals.Config.Defaults.App)
+	}
+	for _, udid := range c.foundUDIDs {
+		add(udid)
+	}
+	for _, bundleID := range c.foundBundleIDs {
+		add(bundleID)
+	}
+	return terms
+}
+
+// redactZip rewrites every file in the zip archive data, replacing each
+// occurrence of any term with "[REDACTED]", and returns the rewritten
+// archive. Binary files get the same byte replacement; an archive that
+// fails to parse is returned as-is, and a member that can't be read is left
+// out of the result.
+func redactZip(data []byte, terms []string) []byte {
+	if len(terms) == 0 {
+		return data
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return data
+	}
+
+	out := &bytes.Buffer{}
+	zw := zip.NewWriter(out)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		for _, term := range terms {
+			content = bytes.ReplaceAll(content, []byte(term), []byte("[REDACTED]"))
+		}
+		writeZipFile(zw, f.Name, content)
+	}
+	zw.Close()
+	return out.Bytes()
+}
+
+func writeZipFile(zw *zip.Writer, name string, content []byte) {
+	w, err := zw.Create(name)
+	if err != nil {
+		return
+	}
+	_, _ = w.Write(content)
+}
+
+func writeZipError(zw *zip.Writer, name string, err error) {
+	writeZipFile(zw, name, []byte(fmt.Sprintf("error collecting %s: %v\n", name, err)))
+}
@@ -3,12 +3,17 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"regexp"
+	"sync"
 	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/vburojevic/xcw/internal/config"
+	"github.com/vburojevic/xcw/internal/filter"
 	"github.com/vedranburojevic/xcw/internal/domain"
 	"github.com/vedranburojevic/xcw/internal/simulator"
 	"github.com/vedranburojevic/xcw/internal/tui"
@@ -24,6 +29,10 @@ type UICmd struct {
 	Subsystem        []string `help:"Filter by subsystem (can be repeated)"`
 	Category         []string `help:"Filter by category (can be repeated)"`
 	BufferSize       int      `default:"1000" help:"Number of recent logs to buffer"`
+
+	FromFile  string  `help:"Replay previously captured NDJSON from this file instead of tailing a live simulator"`
+	FromStdin bool    `help:"Replay previously captured NDJSON from stdin instead of tailing a live simulator"`
+	Speed     float64 `default:"0" help:"With --from-file/--from-stdin, pace emission at this multiplier of the entries' original timestamp spacing (0 = as fast as possible, 1.0 = real-time)"`
 }
 
 // Run executes the UI command
@@ -39,6 +48,10 @@ func (c *UICmd) Run(globals *Globals) error {
 		cancel()
 	}()
 
+	if c.FromFile != "" || c.FromStdin {
+		return c.runFromCapture(ctx, globals)
+	}
+
 	// Find the simulator
 	globals.Debug("Finding simulator: %s", c.Simulator)
 	mgr := simulator.NewManager()
@@ -47,6 +60,8 @@ func (c *UICmd) Run(globals *Globals) error {
 		return fmt.Errorf("device not found: %w", err)
 	}
 	globals.Debug("Found device: %s (UDID: %s)", device.Name, device.UDID)
+	_ = RecordCompletionValue("simulator", device.UDID)
+	_ = RecordCompletionValue("app", c.App)
 
 	// Compile pattern regex if provided
 	var pattern *regexp.Regexp
@@ -57,10 +72,11 @@ func (c *UICmd) Run(globals *Globals) error {
 		}
 	}
 
-	// Compile exclude pattern regex if provided
+	// Compile exclude pattern regex, falling back to defaults.exclude_pattern
+	// when --exclude wasn't given.
 	var excludePattern *regexp.Regexp
-	if c.Exclude != "" {
-		excludePattern, err = regexp.Compile(c.Exclude)
+	if exclude := effectiveExclude(c.Exclude, globals.Config); exclude != "" {
+		excludePattern, err = regexp.Compile(exclude)
 		if err != nil {
 			return fmt.Errorf("invalid exclude regex pattern: %w", err)
 		}
@@ -75,7 +91,7 @@ func (c *UICmd) Run(globals *Globals) error {
 		MinLevel:          domain.ParseLogLevel(globals.Level),
 		Pattern:           pattern,
 		ExcludePattern:    excludePattern,
-		ExcludeSubsystems: c.ExcludeSubsystem,
+		ExcludeSubsystems: effectiveExcludeSubsystems(c.ExcludeSubsystem, globals.Config),
 		BufferSize:        c.BufferSize,
 	}
 
@@ -85,8 +101,38 @@ func (c *UICmd) Run(globals *Globals) error {
 	}
 	defer streamer.Stop()
 
+	// liveFilter re-checks whatever defaults.exclude_pattern/
+	// defaults.exclude_subsystems a config.Watch reload picks up, the same
+	// flag-wins-over-config fallback opts above was built with - Pattern/
+	// Subsystem/Category/BufferSize are baked into opts and the streamer
+	// can't be swapped without tearing the stream down, so only the
+	// exclude side reacts live. relayFilteredLogs feeds the TUI through it
+	// instead of streamer.Logs() directly.
+	liveFilter := newUIConfigFilter(c.Exclude, c.ExcludeSubsystem, globals.Config)
+	logsCh, errsCh := relayFilteredLogs(ctx, streamer.Logs(), streamer.Errors(), liveFilter)
+
+	configUpdates, configErrs := config.Watch(ctx)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case newConfig, ok := <-configUpdates:
+				if !ok {
+					return
+				}
+				liveFilter.update(c.Exclude, c.ExcludeSubsystem, newConfig)
+			case cerr, ok := <-configErrs:
+				if !ok {
+					return
+				}
+				globals.Debug("config watch: %v", cerr)
+			}
+		}
+	}()
+
 	// Create TUI model
-	model := tui.New(c.App, device.Name, streamer.Logs(), streamer.Errors())
+	model := tui.New(c.App, device.Name, logsCh, errsCh)
 
 	// Run the TUI
 	p := tea.NewProgram(model, tea.WithAltScreen())
@@ -103,3 +149,216 @@ func (c *UICmd) Run(globals *Globals) error {
 
 	return nil
 }
+
+// runFromCapture drives the same tui.Model a live simulator tail would, but
+// from previously captured NDJSON (--from-file, or stdin with --from-stdin)
+// instead of a simulator.Streamer. It replays onto plain channels so the TUI
+// itself doesn't need to know its entries came from a file rather than a
+// live device. Pattern/Exclude/Subsystem/Category/ExcludeSubsystem are
+// applied exactly as they would be via simulator.StreamOptions for a live
+// tail, so a captured file and a live device produce the same TUI contents
+// for the same flags.
+func (c *UICmd) runFromCapture(ctx context.Context, globals *Globals) error {
+	source := "stdin"
+	var r io.Reader = os.Stdin
+	if c.FromFile != "" {
+		source = c.FromFile
+		f, err := os.Open(c.FromFile)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", c.FromFile, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	entries, skipped, err := readNDJSONEntries(r)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	if skipped > 0 {
+		globals.Debug("Skipped %d non-JSON line(s) while replaying %s", skipped, source)
+	}
+
+	chain, err := c.buildFilterChain(globals)
+	if err != nil {
+		return err
+	}
+
+	logsCh := make(chan domain.LogEntry, c.BufferSize)
+	errsCh := make(chan error)
+
+	go func() {
+		defer close(logsCh)
+		var prev time.Time
+		for i, entry := range entries {
+			if ctx.Err() != nil {
+				return
+			}
+			if !c.matchesEntry(chain, globals, &entry) {
+				continue
+			}
+			if c.Speed > 0 && i > 0 {
+				if gap := entry.Timestamp.Sub(prev); gap > 0 {
+					time.Sleep(time.Duration(float64(gap) / c.Speed))
+				}
+			}
+			select {
+			case logsCh <- entry:
+			case <-ctx.Done():
+				return
+			}
+			prev = entry.Timestamp
+		}
+	}()
+
+	model := tui.New(c.App, source, logsCh, errsCh)
+	p := tea.NewProgram(model, tea.WithAltScreen())
+
+	go func() {
+		<-ctx.Done()
+		p.Quit()
+	}()
+
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("TUI error: %w", err)
+	}
+
+	return nil
+}
+
+// buildFilterChain compiles --pattern, and --exclude falling back to
+// defaults.exclude_pattern when --exclude wasn't given, into a
+// filter.FilterChain - the same way ReplayCmd.buildFilterChain does for
+// its own filter flags. nil is a no-op since filter.FilterChain.Match
+// treats a nil receiver as "match everything".
+func (c *UICmd) buildFilterChain(globals *Globals) (*filter.FilterChain, error) {
+	var opts []filter.ChainOption
+	if c.Pattern != "" {
+		re, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex pattern: %w", err)
+		}
+		opts = append(opts, filter.WithRegex(re))
+	}
+	if exclude := effectiveExclude(c.Exclude, globals.Config); exclude != "" {
+		re, err := regexp.Compile(exclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude regex pattern: %w", err)
+		}
+		opts = append(opts, filter.WithExclude(re))
+	}
+	if len(opts) == 0 {
+		return nil, nil
+	}
+	return filter.NewFilterChain(opts...), nil
+}
+
+// matchesEntry applies chain plus the Subsystem/Category allow-lists and
+// ExcludeSubsystem deny-list, mirroring what simulator.StreamOptions
+// enforces for a live tail.
+func (c *UICmd) matchesEntry(chain *filter.FilterChain, globals *Globals, entry *domain.LogEntry) bool {
+	if chain != nil && !chain.Match(entry) {
+		return false
+	}
+	if len(c.Subsystem) > 0 && !matchesAnyWildcard(entry.Subsystem, c.Subsystem) {
+		return false
+	}
+	if len(c.Category) > 0 && !matchesAnyWildcard(entry.Category, c.Category) {
+		return false
+	}
+	if matchesAnyWildcard(entry.Subsystem, effectiveExcludeSubsystems(c.ExcludeSubsystem, globals.Config)) {
+		return false
+	}
+	return true
+}
+
+// uiConfigFilter holds the exclude-pattern/exclude-subsystem filter a live
+// `xcw ui` re-checks on every entry, so a config.Watch reload picking up a
+// new defaults.exclude_pattern/defaults.exclude_subsystems value (when
+// --exclude/--exclude-subsystem weren't given explicitly) can swap it in
+// without tearing down the simulator stream. Guarded by mu since reloads
+// arrive on config.Watch's own goroutine while relayFilteredLogs reads it
+// from another.
+type uiConfigFilter struct {
+	mu                sync.RWMutex
+	excludePattern    *regexp.Regexp
+	excludeSubsystems []string
+}
+
+func newUIConfigFilter(exclude string, excludeSubsystems []string, cfg *config.Config) *uiConfigFilter {
+	f := &uiConfigFilter{}
+	f.update(exclude, excludeSubsystems, cfg)
+	return f
+}
+
+func (f *uiConfigFilter) update(exclude string, excludeSubsystems []string, cfg *config.Config) {
+	var re *regexp.Regexp
+	if resolved := effectiveExclude(exclude, cfg); resolved != "" {
+		re, _ = regexp.Compile(resolved)
+	}
+	f.mu.Lock()
+	f.excludePattern = re
+	f.excludeSubsystems = effectiveExcludeSubsystems(excludeSubsystems, cfg)
+	f.mu.Unlock()
+}
+
+func (f *uiConfigFilter) match(entry *domain.LogEntry) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.excludePattern != nil && f.excludePattern.MatchString(entry.Message) {
+		return false
+	}
+	return !matchesAnyWildcard(entry.Subsystem, f.excludeSubsystems)
+}
+
+// relayFilteredLogs copies logs/errs onto freshly made channels, dropping
+// any entry liveFilter no longer matches, so a config.Watch reload takes
+// effect without the streamer (or its caller) needing to know the TUI is
+// reading from a relay rather than the stream directly.
+func relayFilteredLogs(ctx context.Context, logs <-chan domain.LogEntry, errs <-chan error, liveFilter *uiConfigFilter) (<-chan domain.LogEntry, <-chan error) {
+	outLogs := make(chan domain.LogEntry, cap(logs))
+	outErrs := make(chan error)
+
+	go func() {
+		defer close(outLogs)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case entry, ok := <-logs:
+				if !ok {
+					return
+				}
+				if !liveFilter.match(&entry) {
+					continue
+				}
+				select {
+				case outLogs <- entry:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	go func() {
+		defer close(outErrs)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err, ok := <-errs:
+				if !ok {
+					return
+				}
+				select {
+				case outErrs <- err:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return outLogs, outErrs
+}
@@ -1,14 +1,34 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"runtime"
+	"time"
 
 	"github.com/vburojevic/xcw/internal/output"
+	"github.com/vburojevic/xcw/internal/updater"
 )
 
-// UpdateCmd shows how to upgrade xcw
-type UpdateCmd struct{}
+// UpdateCmd shows how to upgrade xcw, and - with --apply or --check - runs
+// or probes xcw's own self-updater.
+type UpdateCmd struct {
+	Apply    bool   `help:"Download, verify, and install the latest release over the running binary"`
+	Check    bool   `help:"Check whether a newer release is available without downloading it"`
+	Channel  string `help:"Release channel to consider" enum:"stable,prerelease" default:"stable"`
+	Rollback bool   `help:"Restore the binary replaced by the most recent --apply"`
+}
+
+// updateProgressEvent is emitted on globals.Stdout when format=ndjson
+// during --apply, so an agent driving xcw through its self-update can
+// follow along instead of staring at a silent download.
+type updateProgressEvent struct {
+	Type  string `json:"type"` // "update_progress"
+	Stage string `json:"stage"`
+	Bytes int64  `json:"bytes,omitempty"`
+}
 
 // UpdateOutput represents the NDJSON output for update instructions
 type UpdateOutput struct {
@@ -27,14 +47,195 @@ const (
 	releasesURL  = "https://github.com/vburojevic/xcw/releases"
 )
 
-// Run executes the update command
+// Run executes the update command: with no flags it prints upgrade
+// instructions; --check probes for a newer release; --apply runs the
+// self-updater; --rollback undoes the most recent --apply.
 func (c *UpdateCmd) Run(globals *Globals) error {
+	switch {
+	case c.Rollback:
+		return c.runRollback(globals)
+	case c.Check:
+		return c.runCheck(globals)
+	case c.Apply:
+		return c.runApply(globals)
+	}
+
 	if globals.Format == "ndjson" {
 		return c.outputNDJSON(globals)
 	}
 	return c.outputText(globals)
 }
 
+func (c *UpdateCmd) channel() updater.Channel {
+	if c.Channel == string(updater.ChannelPrerelease) {
+		return updater.ChannelPrerelease
+	}
+	return updater.ChannelStable
+}
+
+// runCheck queries the release channel and reports whether its version
+// differs from Version (any difference counts as an available update),
+// without downloading anything.
+func (c *UpdateCmd) runCheck(globals *Globals) error {
+	rel, err := updater.FetchRelease(context.Background(), c.channel())
+	if err != nil {
+		return outputErrorCommon(globals, "UPDATE_CHECK_FAILED", err.Error())
+	}
+
+	available := rel.Version() != Version
+
+	if globals.Format == "ndjson" {
+		encoder := json.NewEncoder(globals.Stdout)
+		return encoder.Encode(struct {
+			Type            string `json:"type"`
+			SchemaVersion   int    `json:"schemaVersion"`
+			CurrentVersion  string `json:"current_version"`
+			LatestVersion   string `json:"latest_version"`
+			UpdateAvailable bool   `json:"update_available"`
+			Channel         string `json:"channel"`
+		}{
+			Type:            "update_check",
+			SchemaVersion:   output.SchemaVersion,
+			CurrentVersion:  Version,
+			LatestVersion:   rel.Version(),
+			UpdateAvailable: available,
+			Channel:         string(c.channel()),
+		})
+	}
+
+	if available {
+		fmt.Fprintf(globals.Stdout, "Update available: %s -> %s (channel: %s)\n", Version, rel.Version(), c.channel())
+		fmt.Fprintln(globals.Stdout, "Run `xcw update --apply` to install it.")
+	} else {
+		fmt.Fprintf(globals.Stdout, "xcw %s is up to date (channel: %s)\n", Version, c.channel())
+	}
+	return nil
+}
+
+// runApply downloads, verifies, and installs the latest release over the
+// running binary, unless it's Homebrew-managed, in which case it falls
+// back to printing the brew upgrade command rather than fighting brew's
+// own bookkeeping.
+func (c *UpdateCmd) runApply(globals *Globals) error {
+	execPath, err := os.Executable()
+	if err != nil {
+		return outputErrorCommon(globals, "UPDATE_APPLY_FAILED", fmt.Sprintf("locating running binary: %v", err))
+	}
+
+	if updater.IsHomebrewInstall(execPath) {
+		if globals.Format == "ndjson" {
+			return outputErrorCommon(globals, "UPDATE_HOMEBREW_MANAGED",
+				fmt.Sprintf("%s is managed by Homebrew; run: %s", execPath, homebrewCmd))
+		}
+		fmt.Fprintf(globals.Stdout, "%s is managed by Homebrew. Run this instead:\n", execPath)
+		fmt.Fprintf(globals.Stdout, "  %s\n", homebrewCmd)
+		return nil
+	}
+
+	ctx := context.Background()
+	emit := c.progressEmitter(globals)
+
+	emit("check", 0)
+	rel, err := updater.FetchRelease(ctx, c.channel())
+	if err != nil {
+		return outputErrorCommon(globals, "UPDATE_APPLY_FAILED", err.Error())
+	}
+	if rel.Version() == Version {
+		fmt.Fprintf(globals.Stdout, "xcw %s is already up to date (channel: %s)\n", Version, c.channel())
+		return nil
+	}
+
+	asset, err := updater.SelectAsset(rel, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return outputErrorCommon(globals, "UPDATE_APPLY_FAILED", err.Error())
+	}
+
+	checksumURL := asset.BrowserDownloadURL + ".sha256"
+	wantSum, err := updater.FetchChecksum(ctx, checksumURL)
+	if err != nil {
+		return outputErrorCommon(globals, "UPDATE_APPLY_FAILED", fmt.Sprintf("fetching checksum: %v", err))
+	}
+
+	archivePath := execPath + ".download"
+	emit("download", 0)
+	if err := updater.Download(ctx, asset.BrowserDownloadURL, archivePath, func(n int64) { emit("download", n) }); err != nil {
+		os.Remove(archivePath)
+		return outputErrorCommon(globals, "UPDATE_APPLY_FAILED", err.Error())
+	}
+	defer os.Remove(archivePath)
+
+	emit("verify", 0)
+	if err := updater.VerifyChecksum(archivePath, wantSum); err != nil {
+		return outputErrorCommon(globals, "UPDATE_VERIFY_FAILED", err.Error())
+	}
+
+	emit("extract", 0)
+	tmpPath := execPath + ".new"
+	if err := updater.ExtractBinary(archivePath, tmpPath); err != nil {
+		return outputErrorCommon(globals, "UPDATE_EXTRACT_FAILED", err.Error())
+	}
+
+	emit("install", 0)
+	oldPath, err := updater.Apply(tmpPath, execPath)
+	if err != nil {
+		return outputErrorCommon(globals, "UPDATE_APPLY_FAILED", err.Error())
+	}
+
+	if path, err := updater.DefaultHistoryPath(); err == nil {
+		hist, _ := updater.LoadHistory(path)
+		if hist == nil {
+			hist = &updater.History{}
+		}
+		hist.Last = &updater.HistoryEntry{
+			PreviousVersion: Version,
+			NewVersion:      rel.Version(),
+			RollbackPath:    oldPath,
+			UpdatedAt:       time.Now().UTC(),
+		}
+		_ = updater.SaveHistory(path, hist)
+	}
+
+	emit("done", 0)
+	fmt.Fprintf(globals.Stdout, "xcw updated: %s -> %s\n", Version, rel.Version())
+	return nil
+}
+
+// runRollback restores the binary the most recent --apply replaced.
+func (c *UpdateCmd) runRollback(globals *Globals) error {
+	execPath, err := os.Executable()
+	if err != nil {
+		return outputErrorCommon(globals, "UPDATE_ROLLBACK_FAILED", fmt.Sprintf("locating running binary: %v", err))
+	}
+
+	if err := updater.Rollback(execPath); err != nil {
+		return outputErrorCommon(globals, "UPDATE_ROLLBACK_FAILED", err.Error())
+	}
+
+	if path, err := updater.DefaultHistoryPath(); err == nil {
+		if hist, lerr := updater.LoadHistory(path); lerr == nil && hist.Last != nil {
+			fmt.Fprintf(globals.Stdout, "xcw rolled back: %s -> %s\n", hist.Last.NewVersion, hist.Last.PreviousVersion)
+			hist.Last = nil
+			_ = updater.SaveHistory(path, hist)
+			return nil
+		}
+	}
+	fmt.Fprintln(globals.Stdout, "xcw rolled back to the previous binary")
+	return nil
+}
+
+// progressEmitter returns a function that writes an update_progress
+// NDJSON event per stage/byte-count when format=ndjson, and a no-op
+// otherwise - text mode relies on the final summary line instead.
+func (c *UpdateCmd) progressEmitter(globals *Globals) func(stage string, bytes int64) {
+	if globals.Format != "ndjson" {
+		return func(string, int64) {}
+	}
+	encoder := json.NewEncoder(globals.Stdout)
+	return func(stage string, bytes int64) {
+		_ = encoder.Encode(updateProgressEvent{Type: "update_progress", Stage: stage, Bytes: bytes})
+	}
+}
+
 func (c *UpdateCmd) outputNDJSON(globals *Globals) error {
 	out := UpdateOutput{
 		Type:          "update",
@@ -1,24 +1,38 @@
 package cli
 
 import (
-	"errors"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
-	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/vburojevic/xcw/internal/config"
+	"github.com/vburojevic/xcw/internal/filter"
+	"github.com/vburojevic/xcw/internal/rpc"
+	"github.com/vburojevic/xcw/internal/service"
+	"github.com/vburojevic/xcw/internal/trigger"
 	"github.com/vedranburojevic/xcw/internal/domain"
 	"github.com/vedranburojevic/xcw/internal/output"
+	"github.com/vedranburojevic/xcw/internal/session"
 	"github.com/vedranburojevic/xcw/internal/simulator"
 	"github.com/vedranburojevic/xcw/internal/tmux"
 )
 
+// defaultServiceStatusInterval is how often a STATUS=/--status-file update
+// is sent while systemd/launchd integration is active (NOTIFY_SOCKET set,
+// or --status-file given) but --heartbeat wasn't, so liveness reporting
+// doesn't require also enabling session heartbeats.
+const defaultServiceStatusInterval = 15 * time.Second
+
 // WatchCmd watches logs and triggers commands on specific patterns
 type WatchCmd struct {
 	Simulator        string   `short:"s" default:"booted" help:"Simulator name, UDID, or 'booted' for auto-detect"`
@@ -32,6 +46,37 @@ type WatchCmd struct {
 	Cooldown         string   `default:"5s" help:"Minimum time between trigger executions"`
 	Tmux             bool     `help:"Output to tmux session"`
 	Session          string   `help:"Custom tmux session name (default: xcw-<simulator>)"`
+
+	Sink        []string `help:"Additional trigger delivery sink: webhook:<url>, syslog:<network>://<host:port>, file:<path>, or exec:<command> - can be repeated, delivers every fired trigger"`
+	SinksConfig string   `help:"YAML file describing trigger sinks in more detail (HMAC secrets, headers, syslog facility, file rotation) - see --sink for the shorthand form"`
+
+	LogFile       string `help:"Also write NDJSON events to this file, independent of --format"`
+	LogRotateSize string `default:"50MB" help:"Rotate --log-file once it exceeds this size (e.g. 10MB, 1GB)"`
+	LogKeep       int    `default:"5" help:"Number of rotated --log-file segments to keep, gzip-compressed (0 = keep all)"`
+
+	Record             string `help:"Record each detected session (entries, simulator/app metadata, triggers, summary) as session-<n>.tar.gz under this directory"`
+	SessionIdleTimeout string `help:"Close a recorded session if no matching log entry arrives for this long (e.g. 2m); only applies with --record"`
+
+	Heartbeat string `help:"Emit a session_heartbeat event (logs/errors/faults, elapsed time, logs/sec) on this interval while a session is tracked, e.g. --heartbeat=15s (default: off)"`
+
+	StatusFile string `help:"Mirror systemd sd_notify readiness/status (READY=1, STATUS=, WATCHDOG=1, STOPPING=1) to this JSON file too, for supervisors without NOTIFY_SOCKET (e.g. launchd on macOS)"`
+
+	CloudEvents bool `help:"Wrap every NDJSON event in a CloudEvents 1.0 envelope (specversion/type/source/data) - see xcw schema --format cloudevents for the wrapped shapes; requires --format ndjson"`
+
+	Serve      string `help:"Start a gRPC FollowLogService server (see internal/rpc/followlog.proto) streaming this session's NDJSON events to remote followers, e.g. --serve grpc://0.0.0.0:9090 - requires --format ndjson, not supported with --from-file/--from-stdin"`
+	ServeToken string `help:"Shared-secret token remote followers must present as 'authorization' gRPC metadata to --serve; required unless --serve binds to loopback (127.0.0.1/::1/localhost). No TLS is set up here - put --serve behind a TLS-terminating proxy if followers connect over an untrusted network"`
+
+	FromFile  string  `help:"Replay previously captured NDJSON from this file instead of tailing a live simulator (triggers, --record, and --log-file all still apply)"`
+	FromStdin bool    `help:"Replay previously captured NDJSON from stdin instead of tailing a live simulator"`
+	Speed     float64 `default:"0" help:"With --from-file/--from-stdin, pace emission at this multiplier of the entries' original timestamp spacing (0 = as fast as possible, 1.0 = real-time)"`
+
+	Where     string `help:"Filter entries by a legacy 'key OP value' clause (e.g. 'level>=error'), or a full expression if it looks like one - see --where-expr"`
+	WhereExpr string `help:"Filter entries with a boolean expression (e.g. 'level in [\"Error\",\"Fault\"] and subsystem startsWith \"com.apple.\"'); always parsed as a full expression, unlike --where"`
+
+	// registry fans out this run's NDJSON events to --serve's connected
+	// followers. It's run-scoped state set by Run, not a flag - kong only
+	// maps exported fields, so it's invisible to CLI parsing.
+	registry *rpc.Registry
 }
 
 // triggerConfig holds parsed trigger configuration
@@ -40,6 +85,23 @@ type triggerConfig struct {
 	command string
 }
 
+// tmuxInfo is the "tmux" NDJSON event reporting the session a --tmux watch
+// is writing output to.
+type tmuxInfo struct {
+	Type    string `json:"type"`
+	Session string `json:"session"`
+	Attach  string `json:"attach"`
+}
+
+// watchInfo is the "info" NDJSON event watch emits once at startup to
+// describe what it's watching.
+type watchInfo struct {
+	Type      string `json:"type"`
+	Message   string `json:"message"`
+	Simulator string `json:"simulator"`
+	Mode      string `json:"mode"`
+}
+
 // Run executes the watch command
 func (c *WatchCmd) Run(globals *Globals) error {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -73,17 +135,118 @@ func (c *WatchCmd) Run(globals *Globals) error {
 		triggers = append(triggers, triggerConfig{pattern: re, command: parts[1]})
 	}
 
+	where, err := c.buildWherePredicate()
+	if err != nil {
+		return c.outputError(globals, "INVALID_WHERE_EXPR", err.Error())
+	}
+
+	// Pattern/Exclude/ExcludeSubsystem are compiled once here, ahead of the
+	// --from-file/--from-stdin branch below, so a replay is filtered by
+	// exactly the same filter.FilterChain/exclude-subsystem check as a live
+	// stream instead of only honoring --where.
+	pattern, excludePattern, err := c.compileMessageFilters(globals)
+	if err != nil {
+		return err
+	}
+	chain := buildMessageFilterChain(pattern, excludePattern)
+
+	if c.CloudEvents && globals.Format != "ndjson" {
+		return c.outputError(globals, "INVALID_CLOUDEVENTS", "--cloudevents requires --format ndjson")
+	}
+
+	var serveAddr string
+	if c.Serve != "" {
+		if c.FromFile != "" || c.FromStdin {
+			return c.outputError(globals, "INVALID_SERVE", "--serve is not supported with --from-file/--from-stdin replay")
+		}
+		if globals.Format != "ndjson" {
+			return c.outputError(globals, "INVALID_SERVE", "--serve requires --format ndjson")
+		}
+		if !strings.HasPrefix(c.Serve, "grpc://") {
+			return c.outputError(globals, "INVALID_SERVE", "--serve must be grpc://host:port")
+		}
+		serveAddr = strings.TrimPrefix(c.Serve, "grpc://")
+		if c.ServeToken == "" && !isLoopbackServeAddr(serveAddr) {
+			return c.outputError(globals, "INVALID_SERVE", "--serve-token is required when --serve binds to a non-loopback address")
+		}
+	}
+
+	if c.FromFile != "" || c.FromStdin {
+		return c.runFromCapture(ctx, globals, cooldown, triggers, where, chain)
+	}
+
 	// Find the simulator
 	mgr := simulator.NewManager()
 	device, err := mgr.FindDevice(ctx, c.Simulator)
 	if err != nil {
 		return c.outputError(globals, "DEVICE_NOT_FOUND", err.Error())
 	}
+	_ = RecordCompletionValue("simulator", device.UDID)
+	_ = RecordCompletionValue("app", c.App)
+
+	// --record detects session boundaries (PID changes) and archives each
+	// session's raw entries plus simulator/app metadata into a tarball, so
+	// a crash can be replayed later with `xcw sessions replay` instead of
+	// only existing as whatever scrollback happened to survive.
+	var tracker *session.Tracker
+	var recorder *session.Recorder
+	var idleCheckInterval time.Duration
+	if c.Record != "" {
+		if err := os.MkdirAll(c.Record, 0o755); err != nil {
+			return c.outputError(globals, "RECORD_DIR_FAILED", err.Error())
+		}
+		tracker = c.newTracker(device)
+		recorder = session.NewRecorder(c.Record)
+
+		if c.SessionIdleTimeout != "" {
+			idleTimeout, ierr := time.ParseDuration(c.SessionIdleTimeout)
+			if ierr != nil {
+				return c.outputError(globals, "INVALID_SESSION_IDLE_TIMEOUT", ierr.Error())
+			}
+			tracker.SetIdleTimeout(idleTimeout)
+			idleCheckInterval = idleTimeout / 4
+			if idleCheckInterval < time.Second {
+				idleCheckInterval = time.Second
+			}
+		}
+	}
+
+	// --heartbeat works even without --record: it only needs a Tracker to
+	// snapshot, not a Recorder to archive sessions to disk.
+	var heartbeatInterval time.Duration
+	if c.Heartbeat != "" {
+		heartbeatInterval, err = time.ParseDuration(c.Heartbeat)
+		if err != nil {
+			return c.outputError(globals, "INVALID_HEARTBEAT", fmt.Sprintf("invalid heartbeat duration: %s", err))
+		}
+		if tracker == nil {
+			tracker = c.newTracker(device)
+		}
+	}
 
 	// Determine output destination
 	var outputWriter io.Writer = globals.Stdout
 	var tmuxMgr *tmux.Manager
 
+	// --log-file tees log entries and control events (heartbeat, service)
+	// to a rotating, gzip-compressed NDJSON file regardless of --format, so
+	// agents can leave xcw watch running for days without relying on
+	// stdout buffering/retention. Built ahead of where it's first needed so
+	// it's in scope for every emitHeartbeat/emitServiceEvent call below.
+	var fileWriter *output.NDJSONWriter
+	if c.LogFile != "" {
+		maxBytes, verr := validateLogRotationFlags(globals, c.LogFile, c.LogRotateSize, c.LogKeep)
+		if verr != nil {
+			return verr
+		}
+		rfs, ferr := output.NewRotatingFileSink(c.LogFile, maxBytes, 0, c.LogKeep)
+		if ferr != nil {
+			return c.outputError(globals, "LOG_FILE_FAILED", ferr.Error())
+		}
+		fileWriter = output.NewNDJSONWriterWithSinks(rfs)
+		defer fileWriter.Close()
+	}
+
 	if c.Tmux {
 		sessionName := c.Session
 		if sessionName == "" {
@@ -104,8 +267,7 @@ func (c *WatchCmd) Run(globals *Globals) error {
 					tmuxMgr.ClearPaneWithBanner(fmt.Sprintf("Watching: %s (%s) [TRIGGER MODE]", device.Name, c.App))
 
 					if globals.Format == "ndjson" {
-						fmt.Fprintf(globals.Stdout, `{"type":"tmux","session":"%s","attach":"%s"}`+"\n",
-							sessionName, tmuxMgr.AttachCommand())
+						c.emitEvent(globals, "tmux", tmuxInfo{Type: "tmux", Session: sessionName, Attach: tmuxMgr.AttachCommand()})
 					} else {
 						fmt.Fprintf(globals.Stdout, "Tmux session: %s\n", sessionName)
 						fmt.Fprintf(globals.Stdout, "Attach with: %s\n", tmuxMgr.AttachCommand())
@@ -122,8 +284,7 @@ func (c *WatchCmd) Run(globals *Globals) error {
 	// Output watch info
 	if !globals.Quiet && tmuxMgr == nil {
 		if globals.Format == "ndjson" {
-			fmt.Fprintf(globals.Stdout, `{"type":"info","message":"Watching logs from %s","simulator":"%s","mode":"trigger"}`+"\n",
-				device.Name, device.UDID)
+			c.emitEvent(globals, "info", watchInfo{Type: "info", Message: fmt.Sprintf("Watching logs from %s", device.Name), Simulator: device.UDID, Mode: "trigger"})
 		} else {
 			fmt.Fprintf(globals.Stderr, "Watching logs from %s (%s)\n", device.Name, device.UDID)
 			fmt.Fprintf(globals.Stderr, "App: %s\n", c.App)
@@ -137,28 +298,13 @@ func (c *WatchCmd) Run(globals *Globals) error {
 				fmt.Fprintf(globals.Stderr, "On pattern '%s': %s\n", t.pattern.String(), t.command)
 			}
 			fmt.Fprintf(globals.Stderr, "Cooldown: %s\n", c.Cooldown)
+			if c.Heartbeat != "" {
+				fmt.Fprintf(globals.Stderr, "Heartbeat: every %s\n", c.Heartbeat)
+			}
 			fmt.Fprintln(globals.Stderr, "Press Ctrl+C to stop")
 		}
 	}
 
-	// Compile pattern regex if provided
-	var pattern *regexp.Regexp
-	if c.Pattern != "" {
-		pattern, err = regexp.Compile(c.Pattern)
-		if err != nil {
-			return c.outputError(globals, "INVALID_PATTERN", fmt.Sprintf("invalid regex pattern: %s", err))
-		}
-	}
-
-	// Compile exclude pattern
-	var excludePattern *regexp.Regexp
-	if c.Exclude != "" {
-		excludePattern, err = regexp.Compile(c.Exclude)
-		if err != nil {
-			return c.outputError(globals, "INVALID_EXCLUDE_PATTERN", fmt.Sprintf("invalid exclude pattern: %s", err))
-		}
-	}
-
 	// Create streamer
 	streamer := simulator.NewStreamer(mgr)
 	opts := simulator.StreamOptions{
@@ -175,10 +321,44 @@ func (c *WatchCmd) Run(globals *Globals) error {
 	}
 	defer streamer.Stop()
 
-	// Track last trigger times for cooldown
-	lastErrorTrigger := time.Time{}
-	lastFaultTrigger := time.Time{}
-	lastPatternTriggers := make(map[int]time.Time)
+	// tail_id ("") is left blank: WatchCmd has no concept of one, unlike the
+	// tail_id-scoped command NDJSONWriter's ready/agent_hints/clear_buffer
+	// contract was designed for.
+	if fileWriter != nil {
+		fileWriter.WriteReady(time.Now().Format(time.RFC3339Nano), device.Name, device.UDID, c.App, "", 0)
+	}
+
+	// notifier reports this watch's liveness to whatever supervisor is
+	// running it - sd_notify over NOTIFY_SOCKET (systemd) and/or
+	// --status-file (launchd) - now that the streamer has successfully
+	// attached, so a supervisor configured with Type=notify doesn't
+	// consider the unit up before it can actually see simulator logs.
+	notifier := service.New(c.StatusFile)
+	defer notifier.Close()
+	runStart := time.Now()
+	if notifier.Active() {
+		_ = notifier.Ready(service.Counts{})
+		c.emitServiceEvent(globals, fileWriter, "ready")
+	}
+
+	// --serve starts a FollowLogService server fanning this run's NDJSON
+	// events out to remote followers, alongside the usual stdout/--log-file
+	// writers - a follower joining mid-run gets only live events unless it
+	// asks for from_session, in which case the last known session_start is
+	// replayed first.
+	if serveAddr != "" {
+		c.registry = rpc.NewRegistry()
+		rpcServer, serr := rpc.NewServer(serveAddr, c.ServeToken, c.registry)
+		if serr != nil {
+			return c.outputError(globals, "SERVE_FAILED", serr.Error())
+		}
+		go func() {
+			if err := rpcServer.Serve(); err != nil {
+				globals.Debug("rpc serve: %v", err)
+			}
+		}()
+		defer rpcServer.GracefulStop()
+	}
 
 	// Create output writer
 	var writer interface {
@@ -186,49 +366,157 @@ func (c *WatchCmd) Run(globals *Globals) error {
 	}
 
 	if globals.Format == "ndjson" {
-		writer = output.NewNDJSONWriter(outputWriter)
+		ndw := output.NewNDJSONWriter(outputWriter)
+		if c.CloudEvents {
+			ndw.EnableCloudEvents(c.cloudEventSource())
+		}
+		if c.registry != nil {
+			ndw.AddSink(rpc.NewEventSink(c.registry))
+		}
+		writer = ndw
 	} else {
 		writer = output.NewTextWriter(outputWriter)
 	}
+	if c.CloudEvents && fileWriter != nil {
+		fileWriter.EnableCloudEvents(c.cloudEventSource())
+	}
+
+	dispatcher, err := c.buildDispatcher(ctx, globals, recorder, cooldown, triggers)
+	if err != nil {
+		return c.outputError(globals, "INVALID_SINK", err.Error())
+	}
+	defer dispatcher.Close()
+
+	entryState := &watchEntryState{
+		writer:            writer,
+		fileWriter:        fileWriter,
+		tracker:           tracker,
+		recorder:          recorder,
+		triggers:          triggers,
+		dispatcher:        dispatcher,
+		where:             where,
+		chain:             chain,
+		excludeSubsystems: effectiveExcludeSubsystems(c.ExcludeSubsystem, globals.Config),
+	}
+
+	// idleTicker periodically gives the tracker a chance to close out a
+	// session that's gone quiet without ever producing a new-PID entry
+	// (e.g. the app hung rather than crashed). It's only armed when
+	// --session-idle-timeout is set.
+	var idleTicker *time.Ticker
+	var idleTickerC <-chan time.Time
+	if idleCheckInterval > 0 {
+		idleTicker = time.NewTicker(idleCheckInterval)
+		defer idleTicker.Stop()
+		idleTickerC = idleTicker.C
+	}
+
+	// heartbeatTicker drives Tracker.Snapshot independently of log volume, so
+	// a quiet session still reports in at the configured cadence.
+	var heartbeatTicker *time.Ticker
+	var heartbeatTickerC <-chan time.Time
+	// Deferred once, by reference, rather than alongside the ticker's
+	// creation below: applyConfigReload can replace heartbeatTicker with a
+	// fresh one later if a live config reload turns heartbeat on mid-run,
+	// and that replacement needs stopping too.
+	defer func() {
+		if heartbeatTicker != nil {
+			heartbeatTicker.Stop()
+		}
+	}()
+	if heartbeatInterval > 0 {
+		heartbeatTicker = time.NewTicker(heartbeatInterval)
+		heartbeatTickerC = heartbeatTicker.C
+	}
+
+	// serviceStatusTicker drives notifier.Heartbeat at --heartbeat's cadence
+	// if set, or defaultServiceStatusInterval otherwise, so STATUS=/
+	// --status-file updates don't require session heartbeats to be enabled.
+	// watchdogTicker drives notifier.Watchdog at whatever cadence systemd
+	// asked for via WATCHDOG_USEC; neither ticker runs unless notifier has
+	// somewhere to report to.
+	var serviceStatusTicker, watchdogTicker *time.Ticker
+	var serviceStatusTickerC, watchdogTickerC <-chan time.Time
+	if notifier.Active() {
+		statusInterval := defaultServiceStatusInterval
+		if heartbeatInterval > 0 {
+			statusInterval = heartbeatInterval
+		}
+		serviceStatusTicker = time.NewTicker(statusInterval)
+		defer serviceStatusTicker.Stop()
+		serviceStatusTickerC = serviceStatusTicker.C
+
+		if watchdogInterval, ok := notifier.WatchdogInterval(); ok {
+			watchdogTicker = time.NewTicker(watchdogInterval)
+			defer watchdogTicker.Stop()
+			watchdogTickerC = watchdogTicker.C
+		}
+	}
+
+	// configUpdates/configErrs follow xcw.yaml for the life of the watch, so
+	// a long-running `xcw watch` doesn't need to be restarted to pick up a
+	// new --heartbeat cadence or a new defaults.exclude_pattern/
+	// defaults.exclude_subsystems value (when --exclude/--exclude-subsystem
+	// weren't given explicitly - see applyConfigReload). --pattern has no
+	// config.Defaults equivalent to fall back to, and Subsystem/Category/
+	// BufferSize stay fixed for the life of a stream: the simulator
+	// streamer only reads them at Start, and swapping them live would mean
+	// rebuilding the streamer mid-stream rather than just reacting to a
+	// config value.
+	configUpdates, configErrs := config.Watch(ctx)
+	liveConfig := globals.Config
 
 	// Process logs
 	for {
 		select {
 		case <-ctx.Done():
+			if tracker != nil {
+				c.finishRecording(globals, tracker, recorder, session.ExitReasonShutdown)
+			}
+			if notifier.Active() {
+				_ = notifier.Stopping(c.serviceCounts(runStart, entryState))
+				c.emitServiceEvent(globals, fileWriter, "stopping")
+			}
 			return nil
 
-		case entry := <-streamer.Logs():
-			// Output the log entry
-			if err := writer.Write(&entry); err != nil {
-				return err
+		case now := <-idleTickerC:
+			if change := tracker.CheckIdle(now); change != nil {
+				c.handleSessionChange(globals, fileWriter, recorder, change)
 			}
 
-			now := time.Now()
+		case now := <-heartbeatTickerC:
+			if hb := tracker.Snapshot(now); hb != nil {
+				c.emitHeartbeat(globals, fileWriter, hb)
+			}
 
-			// Check error trigger
-			if c.OnError != "" && entry.Level == domain.LogLevelError {
-				if now.Sub(lastErrorTrigger) >= cooldown {
-					c.runTrigger(globals, "error", c.OnError, &entry)
-					lastErrorTrigger = now
-				}
+		case <-serviceStatusTickerC:
+			_ = notifier.Heartbeat(c.serviceCounts(runStart, entryState))
+
+		case <-watchdogTickerC:
+			_ = notifier.Watchdog()
+
+		case newConfig, ok := <-configUpdates:
+			if !ok {
+				configUpdates = nil
+				continue
+			}
+			changed := config.DiffFields(liveConfig, newConfig)
+			liveConfig = newConfig
+			c.applyConfigReload(globals, newConfig, entryState, pattern, &heartbeatTicker, &heartbeatTickerC)
+			if applied := appliedConfigReloadFields(changed); len(applied) > 0 {
+				c.emitConfigReload(globals, applied)
 			}
 
-			// Check fault trigger
-			if c.OnFault != "" && entry.Level == domain.LogLevelFault {
-				if now.Sub(lastFaultTrigger) >= cooldown {
-					c.runTrigger(globals, "fault", c.OnFault, &entry)
-					lastFaultTrigger = now
-				}
+		case cerr, ok := <-configErrs:
+			if !ok {
+				configErrs = nil
+				continue
 			}
+			globals.Debug("config watch: %v", cerr)
 
-			// Check pattern triggers
-			for i, t := range triggers {
-				if t.pattern.MatchString(entry.Message) {
-					if now.Sub(lastPatternTriggers[i]) >= cooldown {
-						c.runTrigger(globals, "pattern:"+t.pattern.String(), t.command, &entry)
-						lastPatternTriggers[i] = now
-					}
-				}
+		case entry := <-streamer.Logs():
+			if err := c.processLogEntry(globals, entryState, &entry); err != nil {
+				return err
 			}
 
 		case err := <-streamer.Errors():
@@ -239,42 +527,710 @@ func (c *WatchCmd) Run(globals *Globals) error {
 					fmt.Fprintf(globals.Stderr, "Warning: %s\n", err.Error())
 				}
 			}
+			if fileWriter != nil {
+				fileWriter.WriteError("STREAM_WARNING", err.Error())
+			}
+		}
+	}
+}
+
+// handleSessionChange reacts to a session.Tracker detecting a PID change:
+// it finalizes the recording for the session that just ended (if any) and
+// starts a new one for the session that just began. A new session also
+// gets fileWriter a clear_buffer/agent_hints pair (when --log-file is set),
+// telling an agent reading the file to drop whatever it had accumulated
+// for the session that just ended.
+func (c *WatchCmd) handleSessionChange(globals *Globals, fileWriter *output.NDJSONWriter, recorder *session.Recorder, change *session.SessionChange) {
+	if change.EndSession != nil {
+		if recorder != nil {
+			if err := recorder.EndSession(change.EndSession.Summary, session.ExitReasonPIDChange); err != nil {
+				globals.Debug("recording: failed to finalize session %d: %v", change.EndSession.Session, err)
+			}
+		}
+		if globals.Format == "ndjson" {
+			c.emitEvent(globals, "session_end", change.EndSession)
+		}
+	}
+	if change.StartSession != nil {
+		if recorder != nil {
+			if err := recorder.StartSession(change.StartSession.Session, change.StartSession.PID, c.App, change.StartSession.Simulator, change.StartSession.UDID); err != nil {
+				globals.Debug("recording: failed to start session %d: %v", change.StartSession.Session, err)
+			}
+		}
+		if globals.Format == "ndjson" {
+			c.emitEvent(globals, "session_start", change.StartSession)
+		}
+		if fileWriter != nil {
+			fileWriter.WriteClearBuffer("session_start", "", change.StartSession.Session)
+			fileWriter.WriteAgentHints("", change.StartSession.Session, defaultHints())
 		}
 	}
 }
 
-// runTrigger executes a trigger command
-func (c *WatchCmd) runTrigger(globals *Globals, triggerType, command string, entry *domain.LogEntry) {
-	// Output trigger notification
+// defaultHints accompanies the agent_hints event a new session emits,
+// giving an agent reading the stream a starting point for how to reason
+// about it without having to infer xcw's event model from scratch.
+func defaultHints() []string {
+	return []string{
+		"a session covers one app process lifetime; a new session means the app restarted",
+		"errors/faults are reported per session via session_heartbeat and the final session_end summary",
+	}
+}
+
+// emitHeartbeat writes a session_heartbeat event into the same stream
+// session_start/session_end events go to: ndjson to stdout, a one-line
+// summary to stderr otherwise. fileWriter also gets the event (when
+// --log-file is set), the same way log entries already tee to both.
+func (c *WatchCmd) emitHeartbeat(globals *Globals, fileWriter *output.NDJSONWriter, hb *domain.SessionHeartbeat) {
+	if fileWriter != nil {
+		fileWriter.WriteEvent("session_heartbeat", hb)
+	}
 	if globals.Format == "ndjson" {
-		fmt.Fprintf(globals.Stdout, `{"type":"trigger","trigger":"%s","command":"%s","message":"%s"}`+"\n",
-			triggerType, command, escapeJSON(entry.Message))
-	} else if !globals.Quiet {
-		fmt.Fprintf(globals.Stderr, "[TRIGGER:%s] Running: %s\n", triggerType, command)
-	}
-
-	// Set environment variables for the command
-	cmd := exec.Command("sh", "-c", command)
-	cmd.Env = append(os.Environ(),
-		"XCW_TRIGGER="+triggerType,
-		"XCW_LEVEL="+string(entry.Level),
-		"XCW_MESSAGE="+entry.Message,
-		"XCW_SUBSYSTEM="+entry.Subsystem,
-		"XCW_PROCESS="+entry.Process,
-		"XCW_TIMESTAMP="+entry.Timestamp.Format(time.RFC3339),
+		c.emitEvent(globals, "session_heartbeat", hb)
+		return
+	}
+	if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "[heartbeat] session=%d pid=%d elapsed=%ds logs=%d errors=%d faults=%d rate=%.1f/s\n",
+			hb.Session, hb.PID, hb.ElapsedSeconds, hb.TotalLogs, hb.Errors, hb.Faults, hb.LogsPerSecond)
+	}
+}
+
+// isLoopbackServeAddr reports whether a --serve host:port only binds a
+// loopback interface, the one case --serve-token can be safely omitted for
+// since the stream can't be reached from outside this machine.
+func isLoopbackServeAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "" {
+		// "" (e.g. ":9090") means "all interfaces", not loopback.
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	return net.ParseIP(host).IsLoopback()
+}
+
+// cloudEventSource identifies this process as the CloudEvents "source"
+// attribute when --cloudevents is set, e.g. "xcw://my-mac.local/watch".
+func (c *WatchCmd) cloudEventSource() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("xcw://%s/watch", hostname)
+}
+
+// emitEvent writes event as an NDJSON "kind" line, wrapping it in a
+// CloudEvents 1.0 envelope first when --cloudevents is set. It covers the
+// handful of watch control events (session changes, trigger firings,
+// service/config-reload notices, tmux info) that are constructed ad hoc
+// here rather than through an output.NDJSONWriter.
+func (c *WatchCmd) emitEvent(globals *Globals, kind string, event interface{}) {
+	var payload interface{} = event
+	if c.CloudEvents {
+		payload = output.WrapCloudEvent(kind, c.cloudEventSource(), event)
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(globals.Stdout, string(b))
+
+	if c.registry != nil {
+		c.registry.Broadcast(kind, b)
+		if start, ok := event.(*domain.SessionStart); ok {
+			c.registry.RecordSessionStart(start.Session, b)
+		}
+	}
+}
+
+// serviceCounts builds the service.Counts notifier.Heartbeat/Stopping
+// report from entryState's running totals and the elapsed time since start.
+func (c *WatchCmd) serviceCounts(start time.Time, st *watchEntryState) service.Counts {
+	return service.Counts{
+		Logs:          st.serviceLogs,
+		Errors:        st.serviceErrors,
+		UptimeSeconds: int(time.Since(start).Seconds()),
+	}
+}
+
+// emitServiceEvent writes a "service" NDJSON event when the systemd/
+// launchd readiness state changes (ready after attaching to the simulator,
+// stopping on graceful shutdown), so an orchestrator can gate dependent
+// steps on it without parsing sd_notify or polling --status-file itself.
+// fileWriter also gets the event (when --log-file is set) so a supervisor
+// tailing the log file sees the same readiness transitions stdout does.
+func (c *WatchCmd) emitServiceEvent(globals *Globals, fileWriter *output.NDJSONWriter, state string) {
+	event := domain.NewServiceEvent(state)
+	if fileWriter != nil {
+		fileWriter.WriteEvent("service", event)
+	}
+	if globals.Format == "ndjson" {
+		c.emitEvent(globals, "service", event)
+		return
+	}
+	if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "[SERVICE] %s\n", state)
+	}
+}
+
+// watchFieldsAppliedLive lists the config.DiffFields paths a running `xcw
+// watch` actually re-applies today via applyConfigReload: the heartbeat
+// cadence, and (when --exclude/--exclude-subsystem weren't given
+// explicitly) the exclude pattern/subsystem list. Format/Level/Quiet and
+// --pattern/Subsystem/Category/BufferSize are real file changes too, but
+// they're baked into the writer/streamer set up once before the loop
+// starts, so reporting them in config_reload would tell an NDJSON consumer
+// a change took effect when it didn't.
+var watchFieldsAppliedLive = map[string]bool{
+	"defaults.heartbeat":          true,
+	"defaults.exclude_pattern":    true,
+	"defaults.exclude_subsystems": true,
+}
+
+// appliedConfigReloadFields narrows a config.DiffFields result down to the
+// subset WatchCmd's applyConfigReload actually acts on.
+func appliedConfigReloadFields(changed []string) []string {
+	var applied []string
+	for _, f := range changed {
+		if watchFieldsAppliedLive[f] {
+			applied = append(applied, f)
+		}
+	}
+	return applied
+}
+
+// applyConfigReload re-arms the heartbeat ticker after a live config.Watch
+// reload picks up a new defaults.heartbeat value, and rebuilds st.chain/
+// st.excludeSubsystems from newConfig.Defaults.exclude_pattern/
+// exclude_subsystems when --exclude/--exclude-subsystem weren't given
+// explicitly (config is only ever a fallback source for those two, same as
+// at startup - an explicit flag is never overridden by a later reload).
+// It's still narrower than the full field list a live xcw.yaml touches:
+// --pattern has no config.Defaults equivalent to fall back to, and
+// Subsystem/Category/BufferSize are baked into the simulator.StreamOptions
+// the streamer was Start-ed with and can't be swapped without tearing the
+// stream down - only what st.chain/st.excludeSubsystems re-check per entry
+// in processLogEntry reacts live, and a session isn't auto-enabled by a
+// config reload any more than it is by the config at startup.
+func (c *WatchCmd) applyConfigReload(globals *Globals, newConfig *config.Config, st *watchEntryState, pattern *regexp.Regexp, ticker **time.Ticker, tickerC *<-chan time.Time) {
+	var excludePattern *regexp.Regexp
+	if exclude := effectiveExclude(c.Exclude, newConfig); exclude != "" {
+		re, err := regexp.Compile(exclude)
+		if err != nil {
+			globals.Debug("config reload: invalid defaults.exclude_pattern %q: %v", exclude, err)
+		} else {
+			excludePattern = re
+		}
+	}
+	st.chain = buildMessageFilterChain(pattern, excludePattern)
+	st.excludeSubsystems = effectiveExcludeSubsystems(c.ExcludeSubsystem, newConfig)
+
+	if st.tracker == nil {
+		return
+	}
+
+	interval, err := time.ParseDuration(newConfig.Defaults.Heartbeat)
+	if newConfig.Defaults.Heartbeat == "" || err != nil {
+		if *ticker != nil {
+			(*ticker).Stop()
+			*ticker = nil
+			*tickerC = nil
+		}
+		return
+	}
+
+	if *ticker == nil {
+		*ticker = time.NewTicker(interval)
+		*tickerC = (*ticker).C
+		return
+	}
+	(*ticker).Reset(interval)
+}
+
+// emitConfigReload reports the field paths a live config.Watch reload
+// changed, so NDJSON consumers (IDE integrations) can react to exactly
+// what's different instead of re-reading the whole file.
+func (c *WatchCmd) emitConfigReload(globals *Globals, changed []string) {
+	if globals.Format == "ndjson" {
+		c.emitEvent(globals, "config_reload", domain.NewConfigReload(changed))
+		return
+	}
+	if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "[config reload] changed: %s\n", strings.Join(changed, ", "))
+	}
+}
+
+// finishRecording closes out whatever session is still open when the watch
+// loop exits, so a Ctrl+C never leaves a half-written recording directory
+// behind instead of a finished tarball.
+func (c *WatchCmd) finishRecording(globals *Globals, tracker *session.Tracker, recorder *session.Recorder, reason string) {
+	final := tracker.GetFinalSummary()
+	if final == nil || recorder == nil {
+		return
+	}
+	if err := recorder.EndSession(final.Summary, reason); err != nil {
+		globals.Debug("recording: failed to finalize session %d: %v", final.Session, err)
+	}
+}
+
+// buildDispatcher assembles the trigger.Dispatcher shared by --on-error,
+// --on-fault, --on-pattern, and any --sink/--sinks-config destinations: the
+// exec commands are registered scoped to their own kind (so --on-error never
+// fires --on-fault's command), while --sink/--sinks-config sinks are
+// registered globally, so they receive every trigger kind that fires.
+func (c *WatchCmd) buildDispatcher(ctx context.Context, globals *Globals, recorder *session.Recorder, cooldown time.Duration, triggers []triggerConfig) (*trigger.Dispatcher, error) {
+	d := trigger.NewDispatcher(ctx, cooldown,
+		func(sinkName string) { c.emitSinkDropped(globals, sinkName) },
+		func(sinkName string, err error) { c.emitTriggerDeliveryError(globals, sinkName, err) },
 	)
 
-	// Run command in background (don't block log processing)
-	go func() {
-		if err := cmd.Run(); err != nil {
-			if globals.Format == "ndjson" {
-				fmt.Fprintf(globals.Stdout, `{"type":"trigger_error","command":"%s","error":"%s"}`+"\n",
-					command, escapeJSON(err.Error()))
-			} else if !globals.Quiet {
-				fmt.Fprintf(globals.Stderr, "[TRIGGER ERROR] %s: %s\n", command, err.Error())
+	var record func(description, output string)
+	if recorder != nil {
+		record = func(description, output string) { _ = recorder.RecordTrigger(description, output) }
+	}
+
+	if c.OnError != "" {
+		d.Register(trigger.NewExecSink(c.OnError, record), "error")
+	}
+	if c.OnFault != "" {
+		d.Register(trigger.NewExecSink(c.OnFault, record), "fault")
+	}
+	for _, t := range triggers {
+		d.Register(trigger.NewExecSink(t.command, record), "pattern:"+t.pattern.String())
+	}
+
+	for _, spec := range c.Sink {
+		sink, err := trigger.ParseSinkSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		d.Register(sink)
+	}
+	if c.SinksConfig != "" {
+		sinks, err := trigger.LoadSinksConfig(c.SinksConfig)
+		if err != nil {
+			return nil, err
+		}
+		for _, sink := range sinks {
+			d.Register(sink)
+		}
+	}
+
+	return d, nil
+}
+
+// fireTrigger asks dispatcher to deliver entry under kind, subject to its
+// cooldown, and - only once it actually fires - reports a "trigger"
+// notification (an NDJSON event, or a stderr line in text mode). commandLabel is the
+// --on-error/--on-fault/--on-pattern command behind kind, blank for kinds
+// with no single exec command to echo.
+func (c *WatchCmd) fireTrigger(globals *Globals, dispatcher *trigger.Dispatcher, kind, commandLabel string, entry *domain.LogEntry) {
+	if !dispatcher.Fire(kind, trigger.Event{
+		Trigger:   kind,
+		Level:     string(entry.Level),
+		Message:   entry.Message,
+		Subsystem: entry.Subsystem,
+		Process:   entry.Process,
+		PID:       entry.PID,
+		Timestamp: entry.Timestamp,
+	}) {
+		return
+	}
+
+	if globals.Format == "ndjson" {
+		c.emitEvent(globals, "trigger", triggerNotification{
+			Type:    "trigger",
+			Trigger: kind,
+			Command: commandLabel,
+			Message: entry.Message,
+		})
+	} else if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "[TRIGGER:%s] Running: %s\n", kind, commandLabel)
+	}
+}
+
+// triggerNotification is the "trigger" NDJSON event fireTrigger reports
+// once a trigger.Dispatcher.Fire call actually fires (i.e. wasn't
+// suppressed by cooldown).
+type triggerNotification struct {
+	Type    string `json:"type"`
+	Trigger string `json:"trigger"`
+	Command string `json:"command"`
+	Message string `json:"message"`
+}
+
+// emitSinkDropped reports a trigger.Dispatcher sink whose bounded queue was
+// saturated and had to discard its oldest queued event.
+func (c *WatchCmd) emitSinkDropped(globals *Globals, sinkName string) {
+	if globals.Format == "ndjson" {
+		c.emitEvent(globals, "sink_dropped", domain.NewSinkDropped(sinkName))
+		return
+	}
+	if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "[TRIGGER] sink %s saturated, dropped oldest queued event\n", sinkName)
+	}
+}
+
+// triggerDeliveryError is the "trigger_error" NDJSON event
+// emitTriggerDeliveryError reports when a sink exhausts its retry budget.
+type triggerDeliveryError struct {
+	Type  string `json:"type"`
+	Sink  string `json:"sink"`
+	Error string `json:"error"`
+}
+
+// emitTriggerDeliveryError reports a trigger.Dispatcher sink that exhausted
+// its retry budget delivering one event.
+func (c *WatchCmd) emitTriggerDeliveryError(globals *Globals, sinkName string, err error) {
+	if globals.Format == "ndjson" {
+		c.emitEvent(globals, "trigger_error", triggerDeliveryError{
+			Type:  "trigger_error",
+			Sink:  sinkName,
+			Error: err.Error(),
+		})
+	} else if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "[TRIGGER ERROR] %s: %s\n", sinkName, err.Error())
+	}
+}
+
+// watchEntryState bundles everything processLogEntry needs to handle one log
+// entry - output, file logging, session tracking/recording, and trigger
+// delivery - so that logic is identical whether the entry came from a live
+// simulator stream or a --from-file/--from-stdin replay.
+type watchEntryState struct {
+	writer interface {
+		Write(entry *domain.LogEntry) error
+	}
+	fileWriter *output.NDJSONWriter
+	tracker    *session.Tracker
+	recorder   *session.Recorder
+	triggers   []triggerConfig
+	dispatcher *trigger.Dispatcher
+	where      func(entry *domain.LogEntry) bool
+
+	// chain/excludeSubsystems apply the same --pattern/--exclude/
+	// --exclude-subsystem filtering a live stream gets from
+	// simulator.StreamOptions, so a --from-file/--from-stdin replay (which
+	// never goes through the streamer) sees an identical set of entries.
+	// Re-checking them for the live path too is redundant but harmless,
+	// since the streamer already enforced them before the entry got here -
+	// it keeps processLogEntry the one place both paths are filtered.
+	chain             *filter.FilterChain
+	excludeSubsystems []string
+
+	// serviceLogs/serviceErrors back the counts reported to
+	// notifier.Heartbeat/Stopping - independent of tracker's own
+	// per-session counts, since service liveness reporting shouldn't
+	// require --record or --heartbeat to be in use.
+	serviceLogs   int
+	serviceErrors int
+}
+
+// processLogEntry writes entry to st.writer (and, if configured, the
+// --log-file sink), feeds it to the session tracker/recorder, and fires any
+// trigger whose cooldown has elapsed.
+func (c *WatchCmd) processLogEntry(globals *Globals, st *watchEntryState, entry *domain.LogEntry) error {
+	if st.where != nil && !st.where(entry) {
+		return nil
+	}
+	if st.chain != nil && !st.chain.Match(entry) {
+		return nil
+	}
+	if matchesAnyWildcard(entry.Subsystem, st.excludeSubsystems) {
+		return nil
+	}
+
+	if err := st.writer.Write(entry); err != nil {
+		return err
+	}
+	if st.fileWriter != nil {
+		st.fileWriter.Write(entry)
+	}
+
+	st.serviceLogs++
+	if entry.Level == domain.LogLevelError || entry.Level == domain.LogLevelFault {
+		st.serviceErrors++
+	}
+
+	if st.tracker != nil {
+		if kind, ok := session.ClassifyLaunchdEvent(entry.Subsystem, entry.Message, c.App); ok {
+			if change := st.tracker.OnProcessEvent(kind, entry.PID, entry.Timestamp); change != nil {
+				c.handleSessionChange(globals, st.fileWriter, st.recorder, change)
 			}
 		}
-	}()
+		if change := st.tracker.CheckEntry(entry); change != nil {
+			c.handleSessionChange(globals, st.fileWriter, st.recorder, change)
+		}
+		if st.recorder != nil {
+			st.recorder.RecordEntry(entry)
+		}
+	}
+
+	if c.OnError != "" && entry.Level == domain.LogLevelError {
+		c.fireTrigger(globals, st.dispatcher, "error", c.OnError, entry)
+	}
+
+	if c.OnFault != "" && entry.Level == domain.LogLevelFault {
+		c.fireTrigger(globals, st.dispatcher, "fault", c.OnFault, entry)
+	}
+
+	for _, t := range st.triggers {
+		if t.pattern.MatchString(entry.Message) {
+			c.fireTrigger(globals, st.dispatcher, "pattern:"+t.pattern.String(), t.command, entry)
+		}
+	}
+
+	return nil
+}
+
+// runFromCapture replays previously captured NDJSON (--from-file, or stdin
+// with --from-stdin) through the exact same writer/tracker/recorder/trigger
+// path runFromCapture's caller otherwise feeds from a live simulator stream.
+// It makes the rotated files `rotation` and `--log-file` produce, and
+// whatever NDJSON a CI job captured, replayable with the triggers
+// and --record flag a user already knows from live `xcw watch`. Input is
+// read as plain NDJSON; gzip-compressed rotated --log-file segments are not
+// decompressed. --heartbeat
+// only turns on session tracking here (session_start/session_end events);
+// no session_heartbeat events are emitted during a replay.
+func (c *WatchCmd) runFromCapture(ctx context.Context, globals *Globals, cooldown time.Duration, triggers []triggerConfig, where func(entry *domain.LogEntry) bool, chain *filter.FilterChain) error {
+	source := "stdin"
+	var r io.Reader = os.Stdin
+	if c.FromFile != "" {
+		source = c.FromFile
+		f, err := os.Open(c.FromFile)
+		if err != nil {
+			return c.outputError(globals, "REPLAY_FAILED", err.Error())
+		}
+		defer f.Close()
+		r = f
+	}
+
+	entries, skipped, err := readNDJSONEntries(r)
+	if err != nil {
+		return c.outputError(globals, "REPLAY_FAILED", err.Error())
+	}
+	if skipped > 0 && !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "Skipped %d non-JSON line(s) while replaying %s\n", skipped, source)
+	}
+
+	var tracker *session.Tracker
+	var recorder *session.Recorder
+	if c.Record != "" {
+		if err := os.MkdirAll(c.Record, 0o755); err != nil {
+			return c.outputError(globals, "RECORD_DIR_FAILED", err.Error())
+		}
+		tracker = session.NewTracker(c.App, "replay", "", "", "", "")
+		recorder = session.NewRecorder(c.Record)
+	}
+	if c.Heartbeat != "" && tracker == nil {
+		tracker = session.NewTracker(c.App, "replay", "", "", "", "")
+	}
+
+	var fileWriter *output.NDJSONWriter
+	if c.LogFile != "" {
+		maxBytes, verr := validateLogRotationFlags(globals, c.LogFile, c.LogRotateSize, c.LogKeep)
+		if verr != nil {
+			return verr
+		}
+		rfs, ferr := output.NewRotatingFileSink(c.LogFile, maxBytes, 0, c.LogKeep)
+		if ferr != nil {
+			return c.outputError(globals, "LOG_FILE_FAILED", ferr.Error())
+		}
+		fileWriter = output.NewNDJSONWriterWithSinks(rfs)
+		defer fileWriter.Close()
+	}
+
+	var writer interface {
+		Write(entry *domain.LogEntry) error
+	}
+	if globals.Format == "ndjson" {
+		ndw := output.NewNDJSONWriter(globals.Stdout)
+		if c.CloudEvents {
+			ndw.EnableCloudEvents(c.cloudEventSource())
+		}
+		writer = ndw
+	} else {
+		writer = output.NewTextWriter(globals.Stdout)
+	}
+	if c.CloudEvents && fileWriter != nil {
+		fileWriter.EnableCloudEvents(c.cloudEventSource())
+	}
+
+	if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "Replaying logs from %s\n", source)
+	}
+
+	dispatcher, err := c.buildDispatcher(ctx, globals, recorder, cooldown, triggers)
+	if err != nil {
+		return c.outputError(globals, "INVALID_SINK", err.Error())
+	}
+	defer dispatcher.Close()
+
+	st := &watchEntryState{
+		writer:            writer,
+		fileWriter:        fileWriter,
+		tracker:           tracker,
+		recorder:          recorder,
+		triggers:          triggers,
+		dispatcher:        dispatcher,
+		where:             where,
+		chain:             chain,
+		excludeSubsystems: effectiveExcludeSubsystems(c.ExcludeSubsystem, globals.Config),
+	}
+
+	var prev time.Time
+	for i := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		if c.Speed > 0 && i > 0 {
+			if gap := entries[i].Timestamp.Sub(prev); gap > 0 {
+				time.Sleep(time.Duration(float64(gap) / c.Speed))
+			}
+		}
+		if err := c.processLogEntry(globals, st, &entries[i]); err != nil {
+			return err
+		}
+		prev = entries[i].Timestamp
+	}
+
+	if tracker != nil {
+		c.finishRecording(globals, tracker, recorder, session.ExitReasonShutdown)
+	}
+	if !globals.Quiet {
+		fmt.Fprintf(globals.Stderr, "Replayed %d entries\n", len(entries))
+	}
+	return nil
+}
+
+// newTracker builds the session.Tracker shared by --record and --heartbeat;
+// neither path has a tailID/appVersion/buildNumber handy, so both leave them
+// blank like the rest of `xcw watch`'s tracker wiring.
+func (c *WatchCmd) newTracker(device *simulator.Device) *session.Tracker {
+	return session.NewTracker(c.App, device.Name, device.UDID, "", "", "")
+}
+
+// compileMessageFilters compiles --pattern, and --exclude falling back to
+// defaults.exclude_pattern when --exclude wasn't given. Shared by the live
+// simulator loop (baked into simulator.StreamOptions) and --from-file/
+// --from-stdin replay (which has no streamer to bake them into, so
+// processLogEntry applies the resulting filter.FilterChain itself via
+// buildMessageFilterChain).
+func (c *WatchCmd) compileMessageFilters(globals *Globals) (pattern, excludePattern *regexp.Regexp, err error) {
+	if c.Pattern != "" {
+		pattern, err = regexp.Compile(c.Pattern)
+		if err != nil {
+			return nil, nil, c.outputError(globals, "INVALID_PATTERN", fmt.Sprintf("invalid regex pattern: %s", err))
+		}
+	}
+	if exclude := effectiveExclude(c.Exclude, globals.Config); exclude != "" {
+		excludePattern, err = regexp.Compile(exclude)
+		if err != nil {
+			return nil, nil, c.outputError(globals, "INVALID_EXCLUDE_PATTERN", fmt.Sprintf("invalid exclude pattern: %s", err))
+		}
+	}
+	return pattern, excludePattern, nil
+}
+
+// effectiveExclude returns exclude, falling back to cfg's
+// defaults.exclude_pattern when exclude wasn't given - the same
+// flag-wins-over-config precedent as --simulator/--app falling back to
+// defaults.simulator/defaults.app elsewhere. Shared by WatchCmd and UICmd,
+// both of which expose an --exclude flag with this same fallback.
+func effectiveExclude(exclude string, cfg *config.Config) string {
+	if exclude != "" {
+		return exclude
+	}
+	return cfg.Defaults.ExcludePattern
+}
+
+// effectiveExcludeSubsystems returns excludeSubsystems, falling back to
+// cfg's defaults.exclude_subsystems when excludeSubsystems is empty.
+// Shared by WatchCmd and UICmd.
+func effectiveExcludeSubsystems(excludeSubsystems []string, cfg *config.Config) []string {
+	if len(excludeSubsystems) > 0 {
+		return excludeSubsystems
+	}
+	return cfg.Defaults.ExcludeSubsystems
+}
+
+// buildMessageFilterChain wraps pattern/excludePattern in a filter.FilterChain,
+// or returns nil if neither was set - nil is a no-op filter.FilterChain.Match
+// already treats a nil receiver as "match everything".
+func buildMessageFilterChain(pattern, excludePattern *regexp.Regexp) *filter.FilterChain {
+	var opts []filter.ChainOption
+	if pattern != nil {
+		opts = append(opts, filter.WithRegex(pattern))
+	}
+	if excludePattern != nil {
+		opts = append(opts, filter.WithExclude(excludePattern))
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+	return filter.NewFilterChain(opts...)
+}
+
+// matchesAnyWildcard reports whether value matches any of patterns, each
+// interpreted as a filepath.Match wildcard (so a literal subsystem name
+// matches itself, and "com.example.*" matches every subsystem under it) -
+// the same wildcard syntax xcw.yaml's subsystem lists use elsewhere.
+func matchesAnyWildcard(value string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// buildWherePredicate compiles --where and --where-expr into a single
+// predicate shared by the live simulator loop and --from-file/--from-stdin
+// replay. --where-expr is always parsed as a full filter.Expression;
+// --where auto-detects between the legacy filter.ParseWhereClause shorthand
+// and a full expression via filter.LooksLikeExpression, so existing
+// 'key OP value' usage keeps working unchanged. Both may be set at once, in
+// which case an entry must satisfy both.
+func (c *WatchCmd) buildWherePredicate() (func(entry *domain.LogEntry) bool, error) {
+	var preds []func(entry *domain.LogEntry) bool
+
+	if c.Where != "" {
+		if filter.LooksLikeExpression(c.Where) {
+			expr, err := filter.Compile(c.Where)
+			if err != nil {
+				return nil, fmt.Errorf("--where: %w", err)
+			}
+			preds = append(preds, expr.Match)
+		} else {
+			wc, err := filter.ParseWhereClause(c.Where)
+			if err != nil {
+				return nil, fmt.Errorf("--where: %w", err)
+			}
+			preds = append(preds, wc.Match)
+		}
+	}
+
+	if c.WhereExpr != "" {
+		expr, err := filter.Compile(c.WhereExpr)
+		if err != nil {
+			return nil, fmt.Errorf("--where-expr: %w", err)
+		}
+		preds = append(preds, expr.Match)
+	}
+
+	if len(preds) == 0 {
+		return nil, nil
+	}
+	return func(entry *domain.LogEntry) bool {
+		for _, p := range preds {
+			if !p(entry) {
+				return false
+			}
+		}
+		return true
+	}, nil
 }
 
 func (c *WatchCmd) outputError(globals *Globals, code, message string) error {
@@ -286,13 +1242,3 @@ func (c *WatchCmd) outputError(globals *Globals, code, message string) error {
 	}
 	return errors.New(message)
 }
-
-// escapeJSON escapes special characters for JSON string
-func escapeJSON(s string) string {
-	s = strings.ReplaceAll(s, `\`, `\\`)
-	s = strings.ReplaceAll(s, `"`, `\"`)
-	s = strings.ReplaceAll(s, "\n", `\n`)
-	s = strings.ReplaceAll(s, "\r", `\r`)
-	s = strings.ReplaceAll(s, "\t", `\t`)
-	return s
-}
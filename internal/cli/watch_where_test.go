@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCapture(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "capture.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+	return path
+}
+
+func TestWatchCmd_WhereFiltersReplayedEntries(t *testing.T) {
+	path := writeCapture(t,
+		`{"timestamp":"2025-12-14T22:00:00Z","level":"info","message":"one"}`,
+		`{"timestamp":"2025-12-14T22:00:01Z","level":"error","message":"two"}`,
+	)
+
+	globals, stdout, _ := testGlobals("ndjson")
+	globals.Quiet = true
+	cmd := &WatchCmd{App: "com.example.app", Cooldown: "5s", FromFile: path, Where: "level=error"}
+
+	require.NoError(t, cmd.Run(globals))
+	assert.NotContains(t, stdout.String(), `"message":"one"`)
+	assert.Contains(t, stdout.String(), `"message":"two"`)
+}
+
+func TestWatchCmd_WhereExprFiltersReplayedEntries(t *testing.T) {
+	path := writeCapture(t,
+		`{"timestamp":"2025-12-14T22:00:00Z","level":"info","message":"one","pid":100}`,
+		`{"timestamp":"2025-12-14T22:00:01Z","level":"error","message":"two","pid":200}`,
+	)
+
+	globals, stdout, _ := testGlobals("ndjson")
+	globals.Quiet = true
+	cmd := &WatchCmd{App: "com.example.app", Cooldown: "5s", FromFile: path, WhereExpr: `level == "Error" and pid == 200`}
+
+	require.NoError(t, cmd.Run(globals))
+	assert.NotContains(t, stdout.String(), `"message":"one"`)
+	assert.Contains(t, stdout.String(), `"message":"two"`)
+}
+
+func TestWatchCmd_InvalidWhereExprReturnsError(t *testing.T) {
+	globals, _, _ := testGlobals("text")
+	cmd := &WatchCmd{App: "com.example.app", Cooldown: "5s", FromStdin: true, WhereExpr: `level ==`}
+
+	err := cmd.Run(globals)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--where-expr")
+}
@@ -38,6 +38,23 @@ type DefaultsConfig struct {
 	// Exclusion filters
 	ExcludeSubsystems []string `mapstructure:"exclude_subsystems"`
 	ExcludePattern    string   `mapstructure:"exclude_pattern"`
+
+	// Retention policy for rotated session/log files and resume-state
+	// snapshots, applied by `xcw prune` and by rotation on every rollover
+	Retention RetentionConfig `mapstructure:"retention"`
+}
+
+// RetentionConfig bounds how many rotated files accumulate on disk before
+// `xcw prune` (or rotation, as it rolls to a new session) reports or
+// deletes the oldest ones. Each field is optional: a zero value disables
+// that constraint. MaxAge and MaxTotalBytes are strings (e.g. "168h",
+// "500MB") rather than time.Duration/int64 so they round-trip through YAML
+// the same way Heartbeat and LogRotateSize already do.
+type RetentionConfig struct {
+	MaxFiles      int    `mapstructure:"max_files"`
+	MaxAge        string `mapstructure:"max_age"`
+	MaxTotalBytes string `mapstructure:"max_total_bytes"`
+	KeepLatest    int    `mapstructure:"keep_latest"`
 }
 
 // Default returns a Config with default values
@@ -124,11 +141,31 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
 // LoadFromFile loads configuration from a specific file
 func LoadFromFile(path string) (*Config, error) {
+	cfg, err := LoadFromFileUnvalidated(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadFromFileUnvalidated parses path the same way LoadFromFile does but
+// skips the Validate call, so a caller that wants to report every issue in a
+// bad config - rather than fail on the first - can still get the parsed
+// Config back. `xcw config validate` is the only caller; everything else
+// should use LoadFromFile.
+func LoadFromFileUnvalidated(path string) (*Config, error) {
 	v := viper.New()
 
 	v.SetConfigFile(path)
@@ -145,6 +182,56 @@ func LoadFromFile(path string) (*Config, error) {
 	return cfg, nil
 }
 
+// configFileCandidates lists the basenames findConfigFile checks in the
+// current directory, in precedence order: .xcw.yaml wins over .xcw.yml
+// when both exist.
+var configFileCandidates = []string{".xcw.yaml", ".xcw.yml"}
+
+// findConfigFile resolves the file Watch should follow for live reloads:
+// the first candidate that exists in the current directory, or "" if none
+// do. Unlike ConfigFile (which defers to viper's own search path across
+// /etc, the user config dir, and $HOME), this only looks in cwd, since
+// that's the file a developer is actually editing during a watch session.
+func findConfigFile() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for _, name := range configFileCandidates {
+		path := filepath.Join(cwd, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// applyEnvOverrides re-applies the handful of XCW_* environment variables
+// Load binds through viper, directly onto an already-parsed cfg. Watch uses
+// this after re-reading the file via LoadFromFile (which has no viper env
+// binding of its own) so a reload doesn't silently drop env overrides that
+// won at startup.
+func applyEnvOverrides(cfg *Config) {
+	if v := os.Getenv("XCW_FORMAT"); v != "" {
+		cfg.Format = v
+	}
+	if v := os.Getenv("XCW_LEVEL"); v != "" {
+		cfg.Level = v
+	}
+	if v := os.Getenv("XCW_QUIET"); v == "true" || v == "1" {
+		cfg.Quiet = true
+	}
+	if v := os.Getenv("XCW_VERBOSE"); v == "true" || v == "1" {
+		cfg.Verbose = true
+	}
+	if v := os.Getenv("XCW_APP"); v != "" {
+		cfg.Defaults.App = v
+	}
+	if v := os.Getenv("XCW_SIMULATOR"); v != "" {
+		cfg.Defaults.Simulator = v
+	}
+}
+
 // ConfigFile returns the path to the config file that was loaded
 func ConfigFile() string {
 	v := viper.New()
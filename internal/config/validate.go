@@ -0,0 +1,311 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationIssue pinpoints a single semantic mistake Validate found: which
+// dotted field path it's at, what value was there, why it's wrong, and (when
+// a fuzzy match against the field's known-good values scores well enough) a
+// suggested fix. FieldPath/Value/Reason/Suggestion are plain strings rather
+// than an error so an NDJSON consumer (an editor/LSP) can render each one as
+// its own diagnostic without parsing an error message.
+type ValidationIssue struct {
+	FieldPath  string
+	Value      string
+	Reason     string
+	Suggestion string
+}
+
+// String renders the issue the way `xcw config validate`'s text output does:
+// "field: reason; did you mean X?".
+func (i ValidationIssue) String() string {
+	s := fmt.Sprintf("%s: %s", i.FieldPath, i.Reason)
+	if i.Suggestion != "" {
+		s += fmt.Sprintf("; did you mean %q?", i.Suggestion)
+	}
+	return s
+}
+
+// ValidationErrors carries every ValidationIssue a single Validate call
+// found, so a config with several mistakes is reported all at once instead
+// of forcing a fix-reload-fix cycle per issue.
+type ValidationErrors []ValidationIssue
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, issue := range e {
+		msgs[i] = issue.String()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+var validFormats = []string{"ndjson", "text"}
+
+var validLogLevels = []string{"debug", "info", "default", "error", "fault"}
+
+// Validate checks c for the semantic mistakes a YAML parse alone can't
+// catch: misspelled enum values (format, level), malformed durations,
+// unparseable regexes/wildcards, and negative sizes - the kind of config
+// that parses fine and then blows up deep inside the streamer. It returns
+// every issue found as a ValidationErrors, or nil if c is clean.
+func (c *Config) Validate() error {
+	var issues []ValidationIssue
+
+	if c.Format != "" && !containsFold(validFormats, c.Format) {
+		issues = append(issues, ValidationIssue{
+			FieldPath:  "format",
+			Value:      c.Format,
+			Reason:     fmt.Sprintf("%q is not a valid format", c.Format),
+			Suggestion: closestMatch(c.Format, validFormats),
+		})
+	}
+
+	if c.Level != "" && !containsFold(validLogLevels, c.Level) {
+		issues = append(issues, ValidationIssue{
+			FieldPath:  "level",
+			Value:      c.Level,
+			Reason:     fmt.Sprintf("%q is not a valid log level", c.Level),
+			Suggestion: closestMatch(c.Level, validLogLevels),
+		})
+	}
+
+	issues = append(issues, c.Defaults.validate("defaults")...)
+
+	if len(issues) == 0 {
+		return nil
+	}
+	return ValidationErrors(issues)
+}
+
+func (d *DefaultsConfig) validate(prefix string) []ValidationIssue {
+	var issues []ValidationIssue
+
+	if d.BufferSize < 0 {
+		issues = append(issues, ValidationIssue{
+			FieldPath: prefix + ".buffer_size",
+			Value:     strconv.Itoa(d.BufferSize),
+			Reason:    "buffer_size must not be negative",
+		})
+	}
+	if d.Limit < 0 {
+		issues = append(issues, ValidationIssue{
+			FieldPath: prefix + ".limit",
+			Value:     strconv.Itoa(d.Limit),
+			Reason:    "limit must not be negative",
+		})
+	}
+
+	issues = append(issues, validateDuration(prefix+".since", d.Since)...)
+	issues = append(issues, validateDuration(prefix+".summary_interval", d.SummaryInterval)...)
+	issues = append(issues, validateDuration(prefix+".heartbeat", d.Heartbeat)...)
+
+	if d.ExcludePattern != "" {
+		if _, err := regexp.Compile(d.ExcludePattern); err != nil {
+			issues = append(issues, ValidationIssue{
+				FieldPath: prefix + ".exclude_pattern",
+				Value:     d.ExcludePattern,
+				Reason:    fmt.Sprintf("not a valid regexp: %s", err),
+			})
+		}
+	}
+
+	for _, pattern := range d.ExcludeSubsystems {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			issues = append(issues, ValidationIssue{
+				FieldPath: prefix + ".exclude_subsystems",
+				Value:     pattern,
+				Reason:    fmt.Sprintf("not a valid wildcard pattern: %s", err),
+			})
+		}
+	}
+
+	issues = append(issues, d.Retention.validate(prefix+".retention")...)
+
+	return issues
+}
+
+func (r *RetentionConfig) validate(prefix string) []ValidationIssue {
+	var issues []ValidationIssue
+
+	if r.MaxFiles < 0 {
+		issues = append(issues, ValidationIssue{
+			FieldPath: prefix + ".max_files",
+			Value:     strconv.Itoa(r.MaxFiles),
+			Reason:    "max_files must not be negative",
+		})
+	}
+	if r.KeepLatest < 0 {
+		issues = append(issues, ValidationIssue{
+			FieldPath: prefix + ".keep_latest",
+			Value:     strconv.Itoa(r.KeepLatest),
+			Reason:    "keep_latest must not be negative",
+		})
+	}
+
+	issues = append(issues, validateDuration(prefix+".max_age", r.MaxAge)...)
+
+	if r.MaxTotalBytes != "" {
+		if _, err := parseSizeMB(r.MaxTotalBytes); err != nil {
+			issues = append(issues, ValidationIssue{
+				FieldPath: prefix + ".max_total_bytes",
+				Value:     r.MaxTotalBytes,
+				Reason:    err.Error(),
+			})
+		}
+	}
+
+	return issues
+}
+
+// validateDuration is shared by every *Config field that round-trips through
+// YAML as a time.ParseDuration string (since, summary_interval, heartbeat,
+// retention.max_age); an empty value means "unset" and isn't an issue.
+func validateDuration(fieldPath, value string) []ValidationIssue {
+	if value == "" {
+		return nil
+	}
+	if _, err := time.ParseDuration(value); err != nil {
+		return []ValidationIssue{{
+			FieldPath: fieldPath,
+			Value:     value,
+			Reason:    fmt.Sprintf("not a valid duration: %s", err),
+		}}
+	}
+	return nil
+}
+
+// parseSizeMB parses a human-friendly size string (e.g. "10", "10MB",
+// "1GB") into a whole number of megabytes, purely to check it's well-formed
+// - retentionPolicyFromConfig in internal/cli does the same parse to build
+// an actual retention.Policy at prune time.
+func parseSizeMB(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("size is required")
+	}
+
+	upper := strings.ToUpper(s)
+	var unit string
+	var numPart string
+	switch {
+	case strings.HasSuffix(upper, "GB"):
+		unit, numPart = "GB", s[:len(s)-2]
+	case strings.HasSuffix(upper, "MB"):
+		unit, numPart = "MB", s[:len(s)-2]
+	case strings.HasSuffix(upper, "KB"):
+		unit, numPart = "KB", s[:len(s)-2]
+	case strings.HasSuffix(upper, "G"):
+		unit, numPart = "GB", s[:len(s)-1]
+	case strings.HasSuffix(upper, "M"):
+		unit, numPart = "MB", s[:len(s)-1]
+	case strings.HasSuffix(upper, "K"):
+		unit, numPart = "KB", s[:len(s)-1]
+	default:
+		unit, numPart = "MB", s
+	}
+
+	numPart = strings.TrimSpace(numPart)
+	if numPart == "" {
+		return 0, fmt.Errorf("invalid size %q: missing numeric value", s)
+	}
+
+	n, err := strconv.Atoi(numPart)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %s", s, err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("invalid size %q: must not be negative", s)
+	}
+
+	switch unit {
+	case "GB":
+		return n * 1024, nil
+	case "KB":
+		// Rounded up to whole megabytes, as parseSize in internal/cli does.
+		if n == 0 {
+			return 0, nil
+		}
+		return (n + 1023) / 1024, nil
+	default:
+		return n, nil
+	}
+}
+
+func containsFold(set []string, v string) bool {
+	for _, s := range set {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// closestMatch returns the entry in candidates with the smallest Levenshtein
+// distance to v, or "" if nothing is close enough to be worth suggesting
+// (more than half of v's length away).
+func closestMatch(v string, candidates []string) string {
+	v = strings.ToLower(v)
+	best := ""
+	bestDist := len(v)/2 + 1
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+	for _, c := range sorted {
+		d := levenshtein(v, strings.ToLower(c))
+		if d < bestDist {
+			bestDist = d
+			best = c
+		}
+	}
+	return best
+}
+
+// levenshtein returns the Damerau-Levenshtein edit distance between a and
+// b, counting an adjacent-character transposition (e.g. "erorr" ->
+// "error") as a single edit rather than two substitutions, since that's
+// the single most common class of typo closestMatch is meant to catch.
+func levenshtein(a, b string) int {
+	if a == b {
+		return 0
+	}
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			del := d[i-1][j] + 1
+			ins := d[i][j-1] + 1
+			sub := d[i-1][j-1] + cost
+			m := del
+			if ins < m {
+				m = ins
+			}
+			if sub < m {
+				m = sub
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				if t := d[i-2][j-2] + 1; t < m {
+					m = t
+				}
+			}
+			d[i][j] = m
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
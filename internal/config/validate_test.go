@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCleanConfig(t *testing.T) {
+	cfg := Default()
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidateRejectsBadLevelWithSuggestion(t *testing.T) {
+	cfg := Default()
+	cfg.Level = "erorr"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	verrs, ok := err.(ValidationErrors)
+	require.True(t, ok)
+	require.Len(t, verrs, 1)
+	assert.Equal(t, "level", verrs[0].FieldPath)
+	assert.Equal(t, "error", verrs[0].Suggestion)
+}
+
+func TestValidateRejectsBadFormat(t *testing.T) {
+	cfg := Default()
+	cfg.Format = "json"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	verrs := err.(ValidationErrors)
+	require.Len(t, verrs, 1)
+	assert.Equal(t, "format", verrs[0].FieldPath)
+}
+
+func TestValidateRejectsNegativeBufferSize(t *testing.T) {
+	cfg := Default()
+	cfg.Defaults.BufferSize = -5
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	verrs := err.(ValidationErrors)
+	require.Len(t, verrs, 1)
+	assert.Equal(t, "defaults.buffer_size", verrs[0].FieldPath)
+}
+
+func TestValidateRejectsMalformedDurations(t *testing.T) {
+	cfg := Default()
+	cfg.Defaults.Heartbeat = "15"
+	cfg.Defaults.SummaryInterval = "not-a-duration"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	verrs := err.(ValidationErrors)
+	require.Len(t, verrs, 2)
+}
+
+func TestValidateRejectsUnparseableExcludePattern(t *testing.T) {
+	cfg := Default()
+	cfg.Defaults.ExcludePattern = "("
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	verrs := err.(ValidationErrors)
+	require.Len(t, verrs, 1)
+	assert.Equal(t, "defaults.exclude_pattern", verrs[0].FieldPath)
+}
+
+func TestValidateRejectsBadWildcard(t *testing.T) {
+	cfg := Default()
+	cfg.Defaults.ExcludeSubsystems = []string{"com.apple.*", "["}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	verrs := err.(ValidationErrors)
+	require.Len(t, verrs, 1)
+	assert.Equal(t, "[", verrs[0].Value)
+}
+
+func TestValidateRejectsNegativeRetentionFields(t *testing.T) {
+	cfg := Default()
+	cfg.Defaults.Retention.MaxFiles = -1
+	cfg.Defaults.Retention.KeepLatest = -1
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	verrs := err.(ValidationErrors)
+	require.Len(t, verrs, 2)
+}
+
+func TestValidateRejectsBadRetentionSize(t *testing.T) {
+	cfg := Default()
+	cfg.Defaults.Retention.MaxTotalBytes = "not-a-size"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	verrs := err.(ValidationErrors)
+	require.Len(t, verrs, 1)
+	assert.Equal(t, "defaults.retention.max_total_bytes", verrs[0].FieldPath)
+}
+
+func TestValidateReportsMultipleIssuesAtOnce(t *testing.T) {
+	cfg := Default()
+	cfg.Level = "erorr"
+	cfg.Format = "json"
+	cfg.Defaults.BufferSize = -1
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	verrs := err.(ValidationErrors)
+	assert.Len(t, verrs, 3)
+}
+
+func TestLoadFromFileRejectsSemanticallyInvalidConfig(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "xcw.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("level: erorr\n"), 0644))
+
+	cfg, err := LoadFromFile(path)
+	assert.Error(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestLoadFromFileUnvalidatedAcceptsInvalidConfig(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "xcw.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("level: erorr\n"), 0644))
+
+	cfg, err := LoadFromFileUnvalidated(path)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, "erorr", cfg.Level)
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("error", "error"))
+	assert.Equal(t, 1, levenshtein("erorr", "error"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// configWatchDebounce collapses the burst of events an atomic-save editor
+// (Vim, VSCode) produces for a single save - typically a temp-file create,
+// a rename, and a metadata change - into one reload.
+const configWatchDebounce = 200 * time.Millisecond
+
+// Watch follows the current directory for changes to the active config
+// file (resolved the same way findConfigFile does) and ships a freshly
+// re-parsed *Config to the returned channel every time it changes. Reload
+// failures (invalid YAML, a file that no longer parses) are reported on
+// the error channel instead, leaving whatever Config a subscriber already
+// has untouched - Watch never sends a partial or zero-value Config. Both
+// channels are closed once ctx is done.
+//
+// Watch deliberately watches the directory rather than the file itself:
+// atomic-save editors replace a config file via rename rather than writing
+// it in place, which would orphan a watch held on the old inode, and
+// findConfigFile is re-run fresh on every triggered reload so a file that
+// outranks the one currently active (e.g. .xcw.yaml appearing alongside an
+// existing .xcw.yml) is picked up without any special-casing.
+//
+// Watch does not return until the underlying fsnotify watcher exists and
+// is already watching the directory, so a caller that writes to the config
+// file immediately after Watch returns can't race the watcher's own setup
+// and have that write's event missed.
+func Watch(ctx context.Context) (<-chan *Config, <-chan error) {
+	configs := make(chan *Config)
+	errs := make(chan error)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		go func() {
+			defer close(configs)
+			defer close(errs)
+			sendErr(ctx, errs, fmt.Errorf("config watch: starting watcher: %w", err))
+		}()
+		return configs, errs
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		go func() {
+			defer close(configs)
+			defer close(errs)
+			watcher.Close()
+			sendErr(ctx, errs, fmt.Errorf("config watch: %w", err))
+		}()
+		return configs, errs
+	}
+	if err := watcher.Add(dir); err != nil {
+		go func() {
+			defer close(configs)
+			defer close(errs)
+			watcher.Close()
+			sendErr(ctx, errs, fmt.Errorf("config watch: %w", err))
+		}()
+		return configs, errs
+	}
+
+	go func() {
+		defer close(configs)
+		defer close(errs)
+		defer watcher.Close()
+
+		var debounce *time.Timer
+		reloadC := make(chan struct{}, 1)
+		scheduleReload := func() {
+			if debounce != nil {
+				debounce.Stop()
+			}
+			debounce = time.AfterFunc(configWatchDebounce, func() {
+				select {
+				case reloadC <- struct{}{}:
+				default:
+				}
+			})
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !isConfigFileEvent(event.Name) {
+					continue
+				}
+				scheduleReload()
+
+			case werr, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				sendErr(ctx, errs, werr)
+
+			case <-reloadC:
+				path := findConfigFile()
+				if path == "" {
+					continue
+				}
+				cfg, err := LoadFromFile(path)
+				if err != nil {
+					sendErr(ctx, errs, fmt.Errorf("config watch: reloading %s: %w", path, err))
+					continue
+				}
+				applyEnvOverrides(cfg)
+				select {
+				case configs <- cfg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return configs, errs
+}
+
+// isConfigFileEvent reports whether a directory event's path names one of
+// findConfigFile's candidates, so Watch ignores unrelated files (e.g. a
+// swap file or an unrelated edit) in the same directory.
+func isConfigFileEvent(name string) bool {
+	base := filepath.Base(name)
+	for _, candidate := range configFileCandidates {
+		if base == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+func sendErr(ctx context.Context, errs chan<- error, err error) {
+	select {
+	case errs <- err:
+	case <-ctx.Done():
+	}
+}
+
+// DiffFields reports which field paths differ between old and new, using
+// the same dotted paths as the YAML keys (e.g. "defaults.heartbeat"), so a
+// subscriber reacting to a Watch reload can rebuild only what actually
+// changed instead of treating every reload as a full reset.
+func DiffFields(old, new *Config) []string {
+	if old == nil || new == nil {
+		return nil
+	}
+
+	var changed []string
+	if old.Format != new.Format {
+		changed = append(changed, "format")
+	}
+	if old.Level != new.Level {
+		changed = append(changed, "level")
+	}
+	if old.Quiet != new.Quiet {
+		changed = append(changed, "quiet")
+	}
+	if old.Verbose != new.Verbose {
+		changed = append(changed, "verbose")
+	}
+	return append(changed, diffDefaults(old.Defaults, new.Defaults)...)
+}
+
+func diffDefaults(old, new DefaultsConfig) []string {
+	var changed []string
+	if old.Simulator != new.Simulator {
+		changed = append(changed, "defaults.simulator")
+	}
+	if old.App != new.App {
+		changed = append(changed, "defaults.app")
+	}
+	if old.BufferSize != new.BufferSize {
+		changed = append(changed, "defaults.buffer_size")
+	}
+	if old.SummaryInterval != new.SummaryInterval {
+		changed = append(changed, "defaults.summary_interval")
+	}
+	if old.Heartbeat != new.Heartbeat {
+		changed = append(changed, "defaults.heartbeat")
+	}
+	if !stringsEqual(old.Subsystems, new.Subsystems) {
+		changed = append(changed, "defaults.subsystems")
+	}
+	if !stringsEqual(old.Categories, new.Categories) {
+		changed = append(changed, "defaults.categories")
+	}
+	if old.Since != new.Since {
+		changed = append(changed, "defaults.since")
+	}
+	if old.Limit != new.Limit {
+		changed = append(changed, "defaults.limit")
+	}
+	if !stringsEqual(old.ExcludeSubsystems, new.ExcludeSubsystems) {
+		changed = append(changed, "defaults.exclude_subsystems")
+	}
+	if old.ExcludePattern != new.ExcludePattern {
+		changed = append(changed, "defaults.exclude_pattern")
+	}
+	changed = append(changed, diffRetention(old.Retention, new.Retention)...)
+	return changed
+}
+
+func diffRetention(old, new RetentionConfig) []string {
+	var changed []string
+	if old.MaxFiles != new.MaxFiles {
+		changed = append(changed, "defaults.retention.max_files")
+	}
+	if old.MaxAge != new.MaxAge {
+		changed = append(changed, "defaults.retention.max_age")
+	}
+	if old.MaxTotalBytes != new.MaxTotalBytes {
+		changed = append(changed, "defaults.retention.max_total_bytes")
+	}
+	if old.KeepLatest != new.KeepLatest {
+		changed = append(changed, "defaults.retention.keep_latest")
+	}
+	return changed
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmpDir))
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	return tmpDir
+}
+
+func TestWatchReloadsOnFileChange(t *testing.T) {
+	tmpDir := chdirTemp(t)
+	configPath := filepath.Join(tmpDir, ".xcw.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("format: ndjson\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	configs, errs := Watch(ctx)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("format: text\n"), 0644))
+
+	select {
+	case cfg := <-configs:
+		require.Equal(t, "text", cfg.Format)
+	case err := <-errs:
+		t.Fatalf("unexpected watch error: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+}
+
+func TestWatchPicksUpYAMLOverYMLPrecedenceChange(t *testing.T) {
+	tmpDir := chdirTemp(t)
+	ymlPath := filepath.Join(tmpDir, ".xcw.yml")
+	require.NoError(t, os.WriteFile(ymlPath, []byte("format: ndjson\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	configs, errs := Watch(ctx)
+
+	// .xcw.yaml outranks .xcw.yml once it appears, even though the watch
+	// started resolved against the .yml file.
+	yamlPath := filepath.Join(tmpDir, ".xcw.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("format: text\n"), 0644))
+
+	select {
+	case cfg := <-configs:
+		require.Equal(t, "text", cfg.Format)
+	case err := <-errs:
+		t.Fatalf("unexpected watch error: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+}
+
+func TestWatchEnvOverrideSurvivesReload(t *testing.T) {
+	tmpDir := chdirTemp(t)
+	os.Setenv("XCW_APP", "com.env.app")
+	t.Cleanup(func() { os.Unsetenv("XCW_APP") })
+
+	configPath := filepath.Join(tmpDir, ".xcw.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("format: ndjson\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	configs, errs := Watch(ctx)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("format: text\ndefaults:\n  app: com.file.app\n"), 0644))
+
+	select {
+	case cfg := <-configs:
+		require.Equal(t, "com.env.app", cfg.Defaults.App, "env override should win over the reloaded file's value")
+	case err := <-errs:
+		t.Fatalf("unexpected watch error: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+}
+
+func TestWatchRejectsInvalidYAMLWithoutSendingConfig(t *testing.T) {
+	tmpDir := chdirTemp(t)
+	configPath := filepath.Join(tmpDir, ".xcw.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("format: ndjson\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	configs, errs := Watch(ctx)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("invalid: yaml: content: ["), 0644))
+
+	select {
+	case cfg := <-configs:
+		t.Fatalf("expected no config on invalid YAML, got %+v", cfg)
+	case err := <-errs:
+		require.Error(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for reload error")
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	old := Default()
+	updated := Default()
+	updated.Format = "text"
+	updated.Defaults.Heartbeat = "15s"
+
+	changed := DiffFields(old, updated)
+	require.ElementsMatch(t, []string{"format", "defaults.heartbeat"}, changed)
+}
+
+func TestDiffFieldsNilSafe(t *testing.T) {
+	require.Nil(t, DiffFields(nil, Default()))
+	require.Nil(t, DiffFields(Default(), nil))
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// ConfigReload is emitted when a long-running command picks up a live
+// xcw.yaml change via config.Watch, carrying only the field paths that
+// actually changed so NDJSON consumers (IDE integrations) can react to the
+// diff instead of re-reading and re-diffing the whole file themselves.
+type ConfigReload struct {
+	Type          string   `json:"type"`          // "config_reload"
+	SchemaVersion int      `json:"schemaVersion"` // 1
+	Changed       []string `json:"changed"`       // dotted field paths, e.g. "defaults.heartbeat"
+	Timestamp     string   `json:"timestamp"`     // ISO8601 timestamp
+}
+
+// NewConfigReload creates a new ConfigReload event.
+func NewConfigReload(changed []string) *ConfigReload {
+	return &ConfigReload{
+		Type:          "config_reload",
+		SchemaVersion: 1,
+		Changed:       changed,
+		Timestamp:     time.Now().UTC().Format(time.RFC3339),
+	}
+}
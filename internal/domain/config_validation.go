@@ -0,0 +1,25 @@
+package domain
+
+// ConfigValidationIssue is emitted by `xcw config validate` for each semantic
+// problem found in a config file, one event per issue, so an editor/LSP can
+// render each as its own inline diagnostic.
+type ConfigValidationIssue struct {
+	Type          string `json:"type"` // "config_validation_issue"
+	SchemaVersion int    `json:"schemaVersion"`
+	FieldPath     string `json:"field_path"`
+	Value         string `json:"value"`
+	Reason        string `json:"reason"`
+	Suggestion    string `json:"suggestion,omitempty"`
+}
+
+// NewConfigValidationIssue creates a new ConfigValidationIssue event.
+func NewConfigValidationIssue(fieldPath, value, reason, suggestion string) *ConfigValidationIssue {
+	return &ConfigValidationIssue{
+		Type:          "config_validation_issue",
+		SchemaVersion: 1,
+		FieldPath:     fieldPath,
+		Value:         value,
+		Reason:        reason,
+		Suggestion:    suggestion,
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// PruneCandidate is emitted by `xcw prune` for each rotated file that
+// exceeds the configured retention policy, whether or not --apply actually
+// deleted it.
+type PruneCandidate struct {
+	Type          string `json:"type"`          // "prune_candidate"
+	SchemaVersion int    `json:"schemaVersion"` // 1
+	Path          string `json:"path"`
+	Size          int64  `json:"size"`
+	AgeSeconds    int    `json:"age_seconds"`
+	Deleted       bool   `json:"deleted"` // true once --apply has removed it
+}
+
+// NewPruneCandidate creates a new PruneCandidate event.
+func NewPruneCandidate(path string, size int64, age time.Duration, deleted bool) *PruneCandidate {
+	return &PruneCandidate{
+		Type:          "prune_candidate",
+		SchemaVersion: 1,
+		Path:          path,
+		Size:          size,
+		AgeSeconds:    int(age.Seconds()),
+		Deleted:       deleted,
+	}
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// ServiceEvent is emitted whenever `xcw watch`'s systemd/launchd readiness
+// state changes (e.g. once the streamer attaches to the simulator, and
+// again on graceful shutdown), so an orchestrator consuming stdout can gate
+// dependent steps on it without parsing sd_notify or polling --status-file
+// itself.
+type ServiceEvent struct {
+	Type          string `json:"type"`          // "service"
+	SchemaVersion int    `json:"schemaVersion"` // 1
+	State         string `json:"state"`         // "ready" or "stopping"
+	Timestamp     string `json:"timestamp"`     // ISO8601 timestamp
+}
+
+// NewServiceEvent creates a new ServiceEvent.
+func NewServiceEvent(state string) *ServiceEvent {
+	return &ServiceEvent{
+		Type:          "service",
+		SchemaVersion: 1,
+		State:         state,
+		Timestamp:     time.Now().UTC().Format(time.RFC3339),
+	}
+}
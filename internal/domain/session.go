@@ -27,10 +27,43 @@ type SessionEnd struct {
 
 // SessionSummary contains statistics about a completed session
 type SessionSummary struct {
-	TotalLogs       int `json:"total_logs"`
-	Errors          int `json:"errors"`
-	Faults          int `json:"faults"`
-	DurationSeconds int `json:"duration_seconds"`
+	TotalLogs       int    `json:"total_logs"`
+	Errors          int    `json:"errors"`
+	Faults          int    `json:"faults"`
+	DurationSeconds int    `json:"duration_seconds"`
+	ExitReason      string `json:"exit_reason,omitempty"` // pid_change, binary_changed, exited, crashed, idle_timeout
+}
+
+// SessionHeartbeat is emitted periodically while a session is being
+// tracked, so long-running captures piped into dashboards or `jq` have
+// something to observe between entries instead of only at SessionEnd.
+type SessionHeartbeat struct {
+	Type           string  `json:"type"`           // "session_heartbeat"
+	SchemaVersion  int     `json:"schemaVersion"`  // 1
+	Session        int     `json:"session"`        // Session number this heartbeat belongs to
+	PID            int     `json:"pid"`             // Current process ID
+	ElapsedSeconds int     `json:"elapsed_seconds"` // Seconds since the session started
+	TotalLogs      int     `json:"total_logs"`
+	Errors         int     `json:"errors"`
+	Faults         int     `json:"faults"`
+	LogsPerSecond  float64 `json:"logs_per_second"` // EWMA-smoothed log rate
+	Timestamp      string  `json:"timestamp"`       // ISO8601 timestamp
+}
+
+// NewSessionHeartbeat creates a new SessionHeartbeat event.
+func NewSessionHeartbeat(session, pid, elapsedSeconds, totalLogs, errors, faults int, logsPerSecond float64) *SessionHeartbeat {
+	return &SessionHeartbeat{
+		Type:           "session_heartbeat",
+		SchemaVersion:  1,
+		Session:        session,
+		PID:            pid,
+		ElapsedSeconds: elapsedSeconds,
+		TotalLogs:      totalLogs,
+		Errors:         errors,
+		Faults:         faults,
+		LogsPerSecond:  logsPerSecond,
+		Timestamp:      time.Now().UTC().Format(time.RFC3339),
+	}
 }
 
 // NewSessionStart creates a new SessionStart event
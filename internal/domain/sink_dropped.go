@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// SinkDropped is emitted when a trigger.Dispatcher sink's bounded queue was
+// already full and had to discard its oldest queued event to make room for
+// a new one, so an NDJSON consumer can tell a sink fell behind instead of
+// silently losing events.
+type SinkDropped struct {
+	Type          string `json:"type"`          // "sink_dropped"
+	SchemaVersion int    `json:"schemaVersion"` // 1
+	Sink          string `json:"sink"`          // e.g. "webhook:https://example.com/hook"
+	Timestamp     string `json:"timestamp"`     // ISO8601 timestamp
+}
+
+// NewSinkDropped creates a new SinkDropped event.
+func NewSinkDropped(sink string) *SinkDropped {
+	return &SinkDropped{
+		Type:          "sink_dropped",
+		SchemaVersion: 1,
+		Sink:          sink,
+		Timestamp:     time.Now().UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,96 @@
+package filter
+
+import (
+	"regexp"
+
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+// FilterChain is an ordered set of predicates evaluated against a log entry
+// before it reaches a sink. Unlike Pipeline (which matches a single
+// pattern/exclude/where trio), FilterChain composes the flags exposed by
+// ReplayCmd and TailCmd: --filter-regex, --filter-level, --filter-process,
+// --grep and --exclude.
+type FilterChain struct {
+	regex     *regexp.Regexp
+	minLevel  domain.LogLevel
+	hasLevel  bool
+	processes map[string]struct{}
+	grep      *regexp.Regexp
+	exclude   *regexp.Regexp
+}
+
+// ChainOption configures a FilterChain built via NewFilterChain.
+type ChainOption func(*FilterChain)
+
+// WithRegex requires the entry message to match re.
+func WithRegex(re *regexp.Regexp) ChainOption {
+	return func(c *FilterChain) { c.regex = re }
+}
+
+// WithMinLevel requires the entry level priority to be >= level.
+func WithMinLevel(level domain.LogLevel) ChainOption {
+	return func(c *FilterChain) {
+		c.minLevel = level
+		c.hasLevel = true
+	}
+}
+
+// WithProcesses restricts entries to one of the given process names.
+func WithProcesses(processes []string) ChainOption {
+	return func(c *FilterChain) {
+		if len(processes) == 0 {
+			return
+		}
+		c.processes = make(map[string]struct{}, len(processes))
+		for _, p := range processes {
+			c.processes[p] = struct{}{}
+		}
+	}
+}
+
+// WithGrep requires the entry message to match re (an alias for a second,
+// independent regex pass - handy for combining --filter-regex and --grep).
+func WithGrep(re *regexp.Regexp) ChainOption {
+	return func(c *FilterChain) { c.grep = re }
+}
+
+// WithExclude drops entries whose message matches re.
+func WithExclude(re *regexp.Regexp) ChainOption {
+	return func(c *FilterChain) { c.exclude = re }
+}
+
+// NewFilterChain builds a FilterChain from the given options. A FilterChain
+// with no options matches every entry.
+func NewFilterChain(opts ...ChainOption) *FilterChain {
+	c := &FilterChain{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Match reports whether entry satisfies every configured predicate.
+func (c *FilterChain) Match(entry *domain.LogEntry) bool {
+	if c == nil {
+		return true
+	}
+	if c.regex != nil && !c.regex.MatchString(entry.Message) {
+		return false
+	}
+	if c.grep != nil && !c.grep.MatchString(entry.Message) {
+		return false
+	}
+	if c.exclude != nil && c.exclude.MatchString(entry.Message) {
+		return false
+	}
+	if c.hasLevel && entry.Level.Priority() < c.minLevel.Priority() {
+		return false
+	}
+	if c.processes != nil {
+		if _, ok := c.processes[entry.Process]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package filter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+func TestFilterChain_NilIsAllowAll(t *testing.T) {
+	var c *FilterChain
+	entry := &domain.LogEntry{Message: "anything"}
+	if !c.Match(entry) {
+		t.Fatalf("nil FilterChain should allow all")
+	}
+}
+
+func TestFilterChain_NoOptionsIsAllowAll(t *testing.T) {
+	c := NewFilterChain()
+	entry := &domain.LogEntry{Message: "anything"}
+	if !c.Match(entry) {
+		t.Fatalf("FilterChain with no options should allow all")
+	}
+}
+
+func TestFilterChain_WithRegex(t *testing.T) {
+	c := NewFilterChain(WithRegex(regexp.MustCompile("ok")))
+
+	if !c.Match(&domain.LogEntry{Message: "ok message"}) {
+		t.Fatalf("expected matching message to pass")
+	}
+	if c.Match(&domain.LogEntry{Message: "nope"}) {
+		t.Fatalf("expected non-matching message to be dropped")
+	}
+}
+
+func TestFilterChain_WithGrep(t *testing.T) {
+	c := NewFilterChain(WithGrep(regexp.MustCompile("crash")))
+
+	if !c.Match(&domain.LogEntry{Message: "app did crash"}) {
+		t.Fatalf("expected matching message to pass")
+	}
+	if c.Match(&domain.LogEntry{Message: "all good"}) {
+		t.Fatalf("expected non-matching message to be dropped")
+	}
+}
+
+func TestFilterChain_WithExclude(t *testing.T) {
+	c := NewFilterChain(WithExclude(regexp.MustCompile("ignore")))
+
+	if !c.Match(&domain.LogEntry{Message: "keep this"}) {
+		t.Fatalf("expected non-matching message to pass")
+	}
+	if c.Match(&domain.LogEntry{Message: "please ignore this"}) {
+		t.Fatalf("expected excluded message to be dropped")
+	}
+}
+
+func TestFilterChain_WithMinLevel(t *testing.T) {
+	c := NewFilterChain(WithMinLevel(domain.LogLevelError))
+
+	if !c.Match(&domain.LogEntry{Level: domain.LogLevelFault}) {
+		t.Fatalf("expected entry above min level to pass")
+	}
+	if !c.Match(&domain.LogEntry{Level: domain.LogLevelError}) {
+		t.Fatalf("expected entry at min level to pass")
+	}
+	if c.Match(&domain.LogEntry{Level: domain.LogLevelInfo}) {
+		t.Fatalf("expected entry below min level to be dropped")
+	}
+}
+
+func TestFilterChain_WithProcesses(t *testing.T) {
+	c := NewFilterChain(WithProcesses([]string{"SpringBoard", "MyApp"}))
+
+	if !c.Match(&domain.LogEntry{Process: "MyApp"}) {
+		t.Fatalf("expected listed process to pass")
+	}
+	if c.Match(&domain.LogEntry{Process: "OtherApp"}) {
+		t.Fatalf("expected unlisted process to be dropped")
+	}
+}
+
+func TestFilterChain_WithProcessesEmptyIsAllowAll(t *testing.T) {
+	c := NewFilterChain(WithProcesses(nil))
+	if !c.Match(&domain.LogEntry{Process: "Anything"}) {
+		t.Fatalf("expected empty process list to allow all")
+	}
+}
+
+func TestFilterChain_AllPredicatesMustMatch(t *testing.T) {
+	c := NewFilterChain(
+		WithRegex(regexp.MustCompile("ok")),
+		WithExclude(regexp.MustCompile("ignore")),
+		WithMinLevel(domain.LogLevelError),
+		WithProcesses([]string{"MyApp"}),
+	)
+
+	entry := &domain.LogEntry{Message: "ok message", Level: domain.LogLevelError, Process: "MyApp"}
+	if !c.Match(entry) {
+		t.Fatalf("expected entry satisfying every predicate to pass")
+	}
+
+	wrongLevel := &domain.LogEntry{Message: "ok message", Level: domain.LogLevelInfo, Process: "MyApp"}
+	if c.Match(wrongLevel) {
+		t.Fatalf("expected entry failing one predicate to be dropped")
+	}
+}
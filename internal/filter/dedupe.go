@@ -1,43 +1,140 @@
 package filter
 
 import (
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/vburojevic/xcw/internal/domain"
+	"github.com/vburojevic/xcw/internal/output"
 )
 
-// DedupeFilter collapses repeated identical messages
+// maxDedupeVariants caps how many distinct raw messages are retained (for
+// display) per template key. Once the cap is hit, DistinctVariants keeps
+// counting but Variants stops growing.
+const maxDedupeVariants = 5
+
+// defaultDedupeQuietInterval is the quiet period Flush uses when the caller
+// hasn't called SetQuietInterval.
+const defaultDedupeQuietInterval = 30 * time.Second
+
+// DedupeMode selects how DedupeFilter keys incoming messages.
+type DedupeMode int
+
+const (
+	// DedupeModeExact keys on the raw, unmodified entry.Message (the
+	// original behavior).
+	DedupeModeExact DedupeMode = iota
+	// DedupeModeTemplate keys on a normalized template (via Templater),
+	// so messages that only differ in their variable parts collapse
+	// together.
+	DedupeModeTemplate
+	// DedupeModeCustom keys on whatever a caller-supplied KeyFunc returns.
+	DedupeModeCustom
+)
+
+// KeyFunc computes a dedupe key for an entry in DedupeModeCustom.
+type KeyFunc func(entry *domain.LogEntry) string
+
+// DedupeFilter collapses repeated messages, either by exact match or by a
+// normalized template (see DedupeMode).
 type DedupeFilter struct {
-	mu           sync.Mutex
-	window       time.Duration   // Time window for deduplication (0 = consecutive only)
-	seen         map[string]*dedupeEntry
-	lastMessage  string
-	lastEmitTime time.Time
+	mu            sync.Mutex
+	window        time.Duration // Time window for deduplication (0 = consecutive only)
+	mode          DedupeMode
+	templater     output.Templater
+	keyFunc       KeyFunc
+	quietInterval time.Duration
+	seen          map[string]*dedupeEntry
+	lastMessage   string
+	lastEmitTime  time.Time
 }
 
 type dedupeEntry struct {
 	count     int
 	firstSeen time.Time
 	lastSeen  time.Time
+	template  string
+	variants  []string
+	distinct  map[string]struct{}
+}
+
+func (e *dedupeEntry) addVariant(msg string) {
+	if e.distinct == nil {
+		e.distinct = make(map[string]struct{})
+	}
+	if _, ok := e.distinct[msg]; ok {
+		return
+	}
+	e.distinct[msg] = struct{}{}
+	if len(e.variants) < maxDedupeVariants {
+		e.variants = append(e.variants, msg)
+	}
 }
 
-// NewDedupeFilter creates a new deduplication filter
+// NewDedupeFilter creates a new deduplication filter in DedupeModeExact.
 // window=0 means only collapse consecutive identical messages
 // window>0 means collapse identical messages within the time window
 func NewDedupeFilter(window time.Duration) *DedupeFilter {
 	return &DedupeFilter{
-		window: window,
-		seen:   make(map[string]*dedupeEntry),
+		window:        window,
+		mode:          DedupeModeExact,
+		quietInterval: defaultDedupeQuietInterval,
+		seen:          make(map[string]*dedupeEntry),
 	}
 }
 
+// NewDedupeFilterWithTemplater creates a deduplication filter in
+// DedupeModeTemplate: entries are keyed on templater.Normalize(msg) rather
+// than the raw message, so "connection refused to 10.0.0.1:8080" and
+// "connection refused to 10.0.0.2:8080" collapse into one entry.
+func NewDedupeFilterWithTemplater(window time.Duration, templater output.Templater) *DedupeFilter {
+	f := NewDedupeFilter(window)
+	f.mode = DedupeModeTemplate
+	f.templater = templater
+	return f
+}
+
+// NewDedupeFilterWithKeyFunc creates a deduplication filter in
+// DedupeModeCustom, keying each entry on keyFunc(entry).
+func NewDedupeFilterWithKeyFunc(window time.Duration, keyFunc KeyFunc) *DedupeFilter {
+	f := NewDedupeFilter(window)
+	f.mode = DedupeModeCustom
+	f.keyFunc = keyFunc
+	return f
+}
+
+// SetQuietInterval overrides the quiet interval Flush requires before
+// summarizing a template (default defaultDedupeQuietInterval).
+func (f *DedupeFilter) SetQuietInterval(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.quietInterval = d
+}
+
 // DedupeResult holds the result of a dedupe check
 type DedupeResult struct {
-	ShouldEmit  bool      // Whether this entry should be emitted
-	Count       int       // Number of duplicates (1 = first occurrence)
-	FirstSeen   time.Time // First occurrence timestamp
-	LastSeen    time.Time // Last occurrence timestamp (same as FirstSeen if count=1)
+	ShouldEmit bool      // Whether this entry should be emitted
+	Count      int       // Number of duplicates (1 = first occurrence)
+	FirstSeen  time.Time // First occurrence timestamp
+	LastSeen   time.Time // Last occurrence timestamp (same as FirstSeen if count=1)
+	Template   string    // Normalized template key (Template/Custom modes only)
+	Variants   []string  // Up to maxDedupeVariants distinct raw messages collapsed so far
+}
+
+// keyFor computes the dedupe key and (if applicable) the template string
+// recorded alongside it.
+func (f *DedupeFilter) keyFor(entry *domain.LogEntry) (key, template string) {
+	switch f.mode {
+	case DedupeModeTemplate:
+		t := f.templater.Normalize(entry.Message)
+		return t, t
+	case DedupeModeCustom:
+		k := f.keyFunc(entry)
+		return k, k
+	default:
+		return entry.Message, ""
+	}
 }
 
 // Check determines if a log entry should be emitted or suppressed
@@ -45,7 +142,7 @@ func (f *DedupeFilter) Check(entry *domain.LogEntry) DedupeResult {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	key := entry.Message
+	key, template := f.keyFor(entry)
 	now := time.Now()
 
 	// Clean up old entries if using window mode
@@ -57,6 +154,9 @@ func (f *DedupeFilter) Check(entry *domain.LogEntry) DedupeResult {
 	if existing, ok := f.seen[key]; ok {
 		existing.count++
 		existing.lastSeen = now
+		if f.mode != DedupeModeExact {
+			existing.addVariant(entry.Message)
+		}
 
 		// In window mode, always suppress duplicates within window
 		if f.window > 0 {
@@ -65,6 +165,8 @@ func (f *DedupeFilter) Check(entry *domain.LogEntry) DedupeResult {
 				Count:      existing.count,
 				FirstSeen:  existing.firstSeen,
 				LastSeen:   existing.lastSeen,
+				Template:   existing.template,
+				Variants:   existing.variants,
 			}
 		}
 
@@ -75,16 +177,23 @@ func (f *DedupeFilter) Check(entry *domain.LogEntry) DedupeResult {
 				Count:      existing.count,
 				FirstSeen:  existing.firstSeen,
 				LastSeen:   existing.lastSeen,
+				Template:   existing.template,
+				Variants:   existing.variants,
 			}
 		}
 	}
 
 	// New message or different from last (in consecutive mode)
-	f.seen[key] = &dedupeEntry{
+	rec := &dedupeEntry{
 		count:     1,
 		firstSeen: now,
 		lastSeen:  now,
+		template:  template,
 	}
+	if f.mode != DedupeModeExact {
+		rec.addVariant(entry.Message)
+	}
+	f.seen[key] = rec
 	f.lastMessage = key
 	f.lastEmitTime = now
 
@@ -93,7 +202,50 @@ func (f *DedupeFilter) Check(entry *domain.LogEntry) DedupeResult {
 		Count:      1,
 		FirstSeen:  now,
 		LastSeen:   now,
+		Template:   template,
+		Variants:   rec.variants,
+	}
+}
+
+// DedupeSummary describes the duplicates collapsed under a single template
+// since the last Flush, suitable for a "dedupe_summary" NDJSON event.
+type DedupeSummary struct {
+	Template         string    `json:"template"`
+	Count            int       `json:"count"`
+	DistinctVariants int       `json:"distinct_variants"`
+	Variants         []string  `json:"variants,omitempty"`
+	FirstSeen        time.Time `json:"first_seen"`
+	LastSeen         time.Time `json:"last_seen"`
+}
+
+// Flush emits a DedupeSummary for every template with more than one
+// occurrence whose lastSeen is older than the configured quiet interval,
+// then clears those entries so the next burst starts a fresh summary.
+func (f *DedupeFilter) Flush(now time.Time) []DedupeSummary {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var out []DedupeSummary
+	for key, entry := range f.seen {
+		if entry.count <= 1 || now.Sub(entry.lastSeen) < f.quietInterval {
+			continue
+		}
+		template := entry.template
+		if template == "" {
+			template = key
+		}
+		out = append(out, DedupeSummary{
+			Template:         template,
+			Count:            entry.count,
+			DistinctVariants: len(entry.distinct),
+			Variants:         entry.variants,
+			FirstSeen:        entry.firstSeen,
+			LastSeen:         entry.lastSeen,
+		})
+		delete(f.seen, key)
 	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Template < out[j].Template })
+	return out
 }
 
 // GetPendingDuplicates returns entries with count > 1 that haven't been reported
@@ -109,6 +261,8 @@ func (f *DedupeFilter) GetPendingDuplicates() map[string]*dedupeEntry {
 				count:     entry.count,
 				firstSeen: entry.firstSeen,
 				lastSeen:  entry.lastSeen,
+				template:  entry.template,
+				variants:  entry.variants,
 			}
 		}
 	}
@@ -0,0 +1,73 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vburojevic/xcw/internal/domain"
+	"github.com/vburojevic/xcw/internal/output"
+)
+
+func TestDedupeFilter_TemplateModeCollapsesVariants(t *testing.T) {
+	store := output.NewPatternStore(t.TempDir() + "/patterns.json")
+	f := NewDedupeFilterWithTemplater(time.Minute, store)
+
+	r1 := f.Check(&domain.LogEntry{Message: "connection refused to 10.0.0.1:8080"})
+	if !r1.ShouldEmit || r1.Count != 1 {
+		t.Fatalf("expected first occurrence to emit, got %+v", r1)
+	}
+
+	r2 := f.Check(&domain.LogEntry{Message: "connection refused to 10.0.0.2:8080"})
+	if r2.ShouldEmit {
+		t.Fatalf("expected second variant to be suppressed as a duplicate template")
+	}
+	if r2.Count != 2 {
+		t.Fatalf("expected count 2, got %d", r2.Count)
+	}
+	if r2.Template != r1.Template {
+		t.Fatalf("expected both messages to share a template, got %q vs %q", r1.Template, r2.Template)
+	}
+	if len(r2.Variants) != 2 {
+		t.Fatalf("expected 2 collapsed variants, got %v", r2.Variants)
+	}
+}
+
+func TestDedupeFilter_FlushEmitsOnlyQuietTemplates(t *testing.T) {
+	store := output.NewPatternStore(t.TempDir() + "/patterns.json")
+	f := NewDedupeFilterWithTemplater(time.Minute, store)
+	f.SetQuietInterval(time.Second)
+
+	f.Check(&domain.LogEntry{Message: "connection refused to 10.0.0.1:8080"})
+	f.Check(&domain.LogEntry{Message: "connection refused to 10.0.0.2:8080"})
+
+	now := time.Now()
+	if summaries := f.Flush(now); len(summaries) != 0 {
+		t.Fatalf("expected no summaries before the quiet interval elapses, got %v", summaries)
+	}
+
+	summaries := f.Flush(now.Add(2 * time.Second))
+	if len(summaries) != 1 {
+		t.Fatalf("expected one summary once quiet, got %d", len(summaries))
+	}
+	if summaries[0].Count != 2 || summaries[0].DistinctVariants != 2 {
+		t.Fatalf("unexpected summary: %+v", summaries[0])
+	}
+
+	// A flushed template is cleared, so a later check starts a fresh count.
+	r := f.Check(&domain.LogEntry{Message: "connection refused to 10.0.0.3:8080"})
+	if !r.ShouldEmit || r.Count != 1 {
+		t.Fatalf("expected a fresh occurrence after flush, got %+v", r)
+	}
+}
+
+func TestDedupeFilter_ExactModeUnaffected(t *testing.T) {
+	f := NewDedupeFilter(0)
+	r1 := f.Check(&domain.LogEntry{Message: "same message"})
+	r2 := f.Check(&domain.LogEntry{Message: "same message"})
+	if !r1.ShouldEmit || r2.ShouldEmit {
+		t.Fatalf("expected consecutive-duplicate behavior unchanged, got %+v / %+v", r1, r2)
+	}
+	if r1.Template != "" || r2.Template != "" {
+		t.Fatalf("expected no template in exact mode, got %q / %q", r1.Template, r2.Template)
+	}
+}
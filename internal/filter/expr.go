@@ -0,0 +1,653 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+// Expression is a compiled boolean predicate parsed from a single --where-expr
+// string, supporting combined boolean logic (&&/and, ||/or, !/not,
+// parentheses) over the comparisons WhereClause handles one at a time. It's
+// kept alongside ParseWhereClause/WhereFilter rather than replacing them -
+// existing "field OP value" shorthand callers are unaffected.
+type Expression struct {
+	root node
+}
+
+// Compile parses src into an Expression. Any regex used with the `matches`
+// operator is compiled once here, not per entry.
+func Compile(src string) (*Expression, error) {
+	p := &exprParser{lex: newExprLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected token %q in where expression", p.tok.text)
+	}
+	return &Expression{root: root}, nil
+}
+
+// Match reports whether entry satisfies the expression.
+func (e *Expression) Match(entry *domain.LogEntry) bool {
+	if e == nil {
+		return true
+	}
+	return e.root.eval(entry)
+}
+
+// LooksLikeExpression reports whether src needs the full Expression parser
+// rather than the single-clause ParseWhereClause shorthand - i.e. it
+// combines clauses with a boolean operator, negates one, or groups with
+// parentheses. --where auto-detects between the two parsers using this;
+// --where-expr always uses Compile directly.
+func LooksLikeExpression(src string) bool {
+	for _, kw := range []string{"&&", "||", " and ", " or ", "(", ")", "in ["} {
+		if strings.Contains(src, kw) {
+			return true
+		}
+	}
+	trimmed := strings.TrimSpace(src)
+	return strings.HasPrefix(trimmed, "!") || strings.HasPrefix(trimmed, "not ")
+}
+
+// --- AST ---
+
+// node is a boolean-valued AST node: and/or/not combinators and the leaf
+// comparisons (cmpNode, matchesNode, inNode).
+type node interface {
+	eval(entry *domain.LogEntry) bool
+}
+
+// valueNode is a value-valued AST node: a field identifier or a literal.
+type valueNode interface {
+	evalValue(entry *domain.LogEntry) exprValue
+}
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumber
+	kindLevel
+)
+
+// exprValue is the result of evaluating a valueNode against one entry.
+// Comparisons branch on kind so "pid > 100" compares numerically and
+// "level >= \"Error\"" compares by domain.LogLevel.Priority() rather than
+// lexicographically.
+type exprValue struct {
+	kind  valueKind
+	str   string
+	num   float64
+	level domain.LogLevel
+}
+
+type identNode struct{ name string }
+
+func (n *identNode) evalValue(entry *domain.LogEntry) exprValue {
+	switch n.name {
+	case "level":
+		return exprValue{kind: kindLevel, str: string(entry.Level), level: entry.Level}
+	case "subsystem":
+		return exprValue{kind: kindString, str: entry.Subsystem}
+	case "category":
+		return exprValue{kind: kindString, str: entry.Category}
+	case "process":
+		return exprValue{kind: kindString, str: entry.Process}
+	case "message":
+		return exprValue{kind: kindString, str: entry.Message}
+	case "timestamp":
+		return exprValue{kind: kindString, str: entry.Timestamp.Format("2006-01-02T15:04:05.000000000Z07:00")}
+	case "pid":
+		return exprValue{kind: kindNumber, num: float64(entry.PID)}
+	default:
+		return exprValue{kind: kindString}
+	}
+}
+
+type stringLit struct{ v string }
+
+func (n *stringLit) evalValue(*domain.LogEntry) exprValue { return exprValue{kind: kindString, str: n.v} }
+
+type numberLit struct{ v float64 }
+
+func (n *numberLit) evalValue(*domain.LogEntry) exprValue { return exprValue{kind: kindNumber, num: n.v} }
+
+type andNode struct{ left, right node }
+
+func (n *andNode) eval(entry *domain.LogEntry) bool { return n.left.eval(entry) && n.right.eval(entry) }
+
+type orNode struct{ left, right node }
+
+func (n *orNode) eval(entry *domain.LogEntry) bool { return n.left.eval(entry) || n.right.eval(entry) }
+
+type notNode struct{ inner node }
+
+func (n *notNode) eval(entry *domain.LogEntry) bool { return !n.inner.eval(entry) }
+
+type cmpOp int
+
+const (
+	opEq cmpOp = iota
+	opNeq
+	opLt
+	opLe
+	opGt
+	opGe
+	opContains
+	opStartsWith
+	opEndsWith
+)
+
+type cmpNode struct {
+	left, right valueNode
+	op          cmpOp
+}
+
+func (n *cmpNode) eval(entry *domain.LogEntry) bool {
+	l, r := n.left.evalValue(entry), n.right.evalValue(entry)
+	switch n.op {
+	case opEq:
+		return valuesEqual(l, r)
+	case opNeq:
+		return !valuesEqual(l, r)
+	case opLt, opLe, opGt, opGe:
+		return compareValues(l, r, n.op)
+	case opContains:
+		return strings.Contains(l.str, r.str)
+	case opStartsWith:
+		return strings.HasPrefix(l.str, r.str)
+	case opEndsWith:
+		return strings.HasSuffix(l.str, r.str)
+	}
+	return false
+}
+
+// matchesNode holds a regex compiled once at Compile time, not per entry.
+type matchesNode struct {
+	left valueNode
+	re   *regexp.Regexp
+}
+
+func (n *matchesNode) eval(entry *domain.LogEntry) bool {
+	return n.re.MatchString(n.left.evalValue(entry).str)
+}
+
+type inNode struct {
+	left valueNode
+	list []valueNode
+}
+
+func (n *inNode) eval(entry *domain.LogEntry) bool {
+	l := n.left.evalValue(entry)
+	for _, item := range n.list {
+		if valuesEqual(l, item.evalValue(entry)) {
+			return true
+		}
+	}
+	return false
+}
+
+func valuesEqual(a, b exprValue) bool {
+	if a.kind == kindLevel || b.kind == kindLevel {
+		return strings.EqualFold(a.str, b.str)
+	}
+	if a.kind == kindNumber && b.kind == kindNumber {
+		return a.num == b.num
+	}
+	return a.str == b.str
+}
+
+// compareValues handles <, <=, >, >= for exprValue pairs: level comparisons
+// go through domain.LogLevel.Priority() (so "level >= \"Error\"" means
+// "at least as severe as error", not a lexicographic string compare),
+// numbers compare numerically, everything else compares lexicographically.
+func compareValues(a, b exprValue, op cmpOp) bool {
+	if a.kind == kindLevel || b.kind == kindLevel {
+		return compareInts(levelPriority(a), levelPriority(b), op)
+	}
+	if a.kind == kindNumber && b.kind == kindNumber {
+		return compareFloats(a.num, b.num, op)
+	}
+	return compareStrings(a.str, b.str, op)
+}
+
+func levelPriority(v exprValue) int {
+	if v.kind == kindLevel {
+		return v.level.Priority()
+	}
+	return domain.ParseLogLevel(v.str).Priority()
+}
+
+func compareInts(a, b int, op cmpOp) bool {
+	switch op {
+	case opLt:
+		return a < b
+	case opLe:
+		return a <= b
+	case opGt:
+		return a > b
+	case opGe:
+		return a >= b
+	}
+	return false
+}
+
+func compareFloats(a, b float64, op cmpOp) bool {
+	switch op {
+	case opLt:
+		return a < b
+	case opLe:
+		return a <= b
+	case opGt:
+		return a > b
+	case opGe:
+		return a >= b
+	}
+	return false
+}
+
+func compareStrings(a, b string, op cmpOp) bool {
+	switch op {
+	case opLt:
+		return a < b
+	case opLe:
+		return a <= b
+	case opGt:
+		return a > b
+	case opGe:
+		return a >= b
+	}
+	return false
+}
+
+// --- Parser ---
+
+type exprParser struct {
+	lex *exprLexer
+	tok token
+}
+
+func (p *exprParser) advance() error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func (p *exprParser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOr {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokAnd {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (node, error) {
+	if p.tok.kind == tokNot {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{inner: inner}, nil
+	}
+	if p.tok.kind == tokLParen {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, fmt.Errorf("expected ')' in where expression")
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (node, error) {
+	left, err := p.parsePrimaryValue()
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.tok.kind {
+	case tokEq, tokNeq, tokLt, tokLe, tokGt, tokGe, tokContains, tokStartsWith, tokEndsWith:
+		op := cmpOpFor(p.tok.kind)
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parsePrimaryValue()
+		if err != nil {
+			return nil, err
+		}
+		return &cmpNode{left: left, right: right, op: op}, nil
+
+	case tokMatches:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokString {
+			return nil, fmt.Errorf("'matches' requires a string regex literal")
+		}
+		re, err := regexp.Compile(p.tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex in 'matches': %w", err)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return &matchesNode{left: left, re: re}, nil
+
+	case tokIn:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokLBracket {
+			return nil, fmt.Errorf("expected '[' after 'in'")
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var list []valueNode
+		if p.tok.kind != tokRBracket {
+			for {
+				v, err := p.parsePrimaryValue()
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+				if p.tok.kind == tokComma {
+					if err := p.advance(); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				break
+			}
+		}
+		if p.tok.kind != tokRBracket {
+			return nil, fmt.Errorf("expected ']' to close 'in' list")
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return &inNode{left: left, list: list}, nil
+	}
+
+	return nil, fmt.Errorf("expected a comparison operator, got %q", p.tok.text)
+}
+
+func (p *exprParser) parsePrimaryValue() (valueNode, error) {
+	switch p.tok.kind {
+	case tokIdent:
+		name := p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return &identNode{name: name}, nil
+	case tokString:
+		v := p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return &stringLit{v: v}, nil
+	case tokNumber:
+		v := p.tok.num
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return &numberLit{v: v}, nil
+	}
+	return nil, fmt.Errorf("expected a field name or literal, got %q", p.tok.text)
+}
+
+func cmpOpFor(k tokenKind) cmpOp {
+	switch k {
+	case tokEq:
+		return opEq
+	case tokNeq:
+		return opNeq
+	case tokLt:
+		return opLt
+	case tokLe:
+		return opLe
+	case tokGt:
+		return opGt
+	case tokGe:
+		return opGe
+	case tokContains:
+		return opContains
+	case tokStartsWith:
+		return opStartsWith
+	case tokEndsWith:
+		return opEndsWith
+	}
+	return opEq
+}
+
+// --- Lexer ---
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+	tokEq
+	tokNeq
+	tokLt
+	tokLe
+	tokGt
+	tokGe
+	tokAnd
+	tokOr
+	tokNot
+	tokIn
+	tokContains
+	tokStartsWith
+	tokEndsWith
+	tokMatches
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+}
+
+type exprLexer struct {
+	src []rune
+	pos int
+}
+
+func newExprLexer(src string) *exprLexer {
+	return &exprLexer{src: []rune(src)}
+}
+
+var exprKeywords = map[string]tokenKind{
+	"and":        tokAnd,
+	"or":         tokOr,
+	"not":        tokNot,
+	"in":         tokIn,
+	"contains":   tokContains,
+	"startsWith": tokStartsWith,
+	"endsWith":   tokEndsWith,
+	"matches":    tokMatches,
+}
+
+func (l *exprLexer) next() (token, error) {
+	l.skipSpace()
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "("}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")"}, nil
+	case c == '[':
+		l.pos++
+		return token{kind: tokLBracket, text: "["}, nil
+	case c == ']':
+		l.pos++
+		return token{kind: tokRBracket, text: "]"}, nil
+	case c == ',':
+		l.pos++
+		return token{kind: tokComma, text: ","}, nil
+	case c == '"' || c == '\'':
+		return l.lexString(c)
+	case c == '=' && l.peek(1) == '=':
+		l.pos += 2
+		return token{kind: tokEq, text: "=="}, nil
+	case c == '!' && l.peek(1) == '=':
+		l.pos += 2
+		return token{kind: tokNeq, text: "!="}, nil
+	case c == '!':
+		l.pos++
+		return token{kind: tokNot, text: "!"}, nil
+	case c == '<' && l.peek(1) == '=':
+		l.pos += 2
+		return token{kind: tokLe, text: "<="}, nil
+	case c == '<':
+		l.pos++
+		return token{kind: tokLt, text: "<"}, nil
+	case c == '>' && l.peek(1) == '=':
+		l.pos += 2
+		return token{kind: tokGe, text: ">="}, nil
+	case c == '>':
+		l.pos++
+		return token{kind: tokGt, text: ">"}, nil
+	case c == '&' && l.peek(1) == '&':
+		l.pos += 2
+		return token{kind: tokAnd, text: "&&"}, nil
+	case c == '|' && l.peek(1) == '|':
+		l.pos += 2
+		return token{kind: tokOr, text: "||"}, nil
+	case isDigit(c):
+		return l.lexNumber()
+	case isIdentStart(c):
+		return l.lexIdent()
+	}
+	return token{}, fmt.Errorf("unexpected character %q in where expression", string(c))
+}
+
+func (l *exprLexer) peek(offset int) rune {
+	if l.pos+offset >= len(l.src) {
+		return 0
+	}
+	return l.src[l.pos+offset]
+}
+
+func (l *exprLexer) skipSpace() {
+	for l.pos < len(l.src) && unicode.IsSpace(l.src[l.pos]) {
+		l.pos++
+	}
+}
+
+func (l *exprLexer) lexString(quote rune) (token, error) {
+	l.pos++ // consume opening quote
+	var sb strings.Builder
+	for {
+		if l.pos >= len(l.src) {
+			return token{}, fmt.Errorf("unterminated string literal in where expression")
+		}
+		c := l.src[l.pos]
+		if c == quote {
+			l.pos++
+			return token{kind: tokString, text: sb.String()}, nil
+		}
+		if c == '\\' && l.pos+1 < len(l.src) {
+			l.pos++
+			c = l.src[l.pos]
+		}
+		sb.WriteRune(c)
+		l.pos++
+	}
+}
+
+func (l *exprLexer) lexNumber() (token, error) {
+	start := l.pos
+	for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.pos++
+	}
+	s := string(l.src[start:l.pos])
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return token{}, fmt.Errorf("invalid number %q in where expression", s)
+	}
+	return token{kind: tokNumber, text: s, num: n}, nil
+}
+
+func (l *exprLexer) lexIdent() (token, error) {
+	start := l.pos
+	for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+		l.pos++
+	}
+	s := string(l.src[start:l.pos])
+	if kind, ok := exprKeywords[s]; ok {
+		return token{kind: kind, text: s}, nil
+	}
+	return token{kind: tokIdent, text: s}, nil
+}
+
+func isDigit(c rune) bool      { return c >= '0' && c <= '9' }
+func isIdentStart(c rune) bool { return unicode.IsLetter(c) || c == '_' }
+func isIdentPart(c rune) bool  { return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' }
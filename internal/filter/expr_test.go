@@ -0,0 +1,169 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+func mustCompile(t *testing.T, src string) *Expression {
+	t.Helper()
+	expr, err := Compile(src)
+	if err != nil {
+		t.Fatalf("Compile(%q) failed: %v", src, err)
+	}
+	return expr
+}
+
+func TestExpression_SimpleComparisons(t *testing.T) {
+	entry := &domain.LogEntry{Level: domain.LogLevelError, Subsystem: "com.apple.network", PID: 501, Message: "connection timeout"}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`level == "Error"`, true},
+		{`level != "Error"`, false},
+		{`pid == 501`, true},
+		{`pid != 501`, false},
+		{`pid > 500`, true},
+		{`pid < 500`, false},
+		{`pid >= 501`, true},
+		{`pid <= 501`, true},
+		{`subsystem startsWith "com.apple."`, true},
+		{`subsystem endsWith ".network"`, true},
+		{`message contains "timeout"`, true},
+		{`message matches "(?i)TIMEOUT"`, true},
+	}
+
+	for _, tt := range tests {
+		expr := mustCompile(t, tt.expr)
+		if got := expr.Match(entry); got != tt.want {
+			t.Errorf("Compile(%q).Match(entry) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestExpression_LevelComparedByPriority(t *testing.T) {
+	expr := mustCompile(t, `level >= "Error"`)
+
+	if !expr.Match(&domain.LogEntry{Level: domain.LogLevelFault}) {
+		t.Fatalf("expected Fault to satisfy level >= Error")
+	}
+	if expr.Match(&domain.LogEntry{Level: domain.LogLevelInfo}) {
+		t.Fatalf("expected Info to fail level >= Error")
+	}
+}
+
+func TestExpression_BooleanCombinators(t *testing.T) {
+	entry := &domain.LogEntry{Level: domain.LogLevelError, Subsystem: "com.apple.network", PID: 501, Message: "timeout"}
+
+	expr := mustCompile(t, `level in ["Error","Fault"] and (subsystem startsWith "com.apple." or message matches "(?i)timeout") and pid != 1`)
+	if !expr.Match(entry) {
+		t.Fatalf("expected combined expression to match")
+	}
+
+	entry2 := &domain.LogEntry{Level: domain.LogLevelInfo, Subsystem: "com.apple.network", PID: 501, Message: "timeout"}
+	if expr.Match(entry2) {
+		t.Fatalf("expected combined expression to reject Info level")
+	}
+}
+
+func TestExpression_NotOperator(t *testing.T) {
+	expr := mustCompile(t, `not level == "Error"`)
+	if expr.Match(&domain.LogEntry{Level: domain.LogLevelError}) {
+		t.Fatalf("expected 'not level == Error' to reject an Error entry")
+	}
+	if !expr.Match(&domain.LogEntry{Level: domain.LogLevelInfo}) {
+		t.Fatalf("expected 'not level == Error' to accept an Info entry")
+	}
+
+	expr2 := mustCompile(t, `!(level == "Error")`)
+	if expr2.Match(&domain.LogEntry{Level: domain.LogLevelError}) {
+		t.Fatalf("expected '!(level == Error)' to reject an Error entry")
+	}
+}
+
+func TestExpression_SymbolicAndKeywordOperatorsAreEquivalent(t *testing.T) {
+	e1 := mustCompile(t, `level == "Error" && pid == 1`)
+	e2 := mustCompile(t, `level == "Error" and pid == 1`)
+	e3 := mustCompile(t, `level == "Error" || pid == 1`)
+	e4 := mustCompile(t, `level == "Error" or pid == 1`)
+
+	entry := &domain.LogEntry{Level: domain.LogLevelError, PID: 1}
+	for i, e := range []*Expression{e1, e2, e3, e4} {
+		if !e.Match(entry) {
+			t.Fatalf("expression %d: expected match", i)
+		}
+	}
+}
+
+func TestExpression_InMembership(t *testing.T) {
+	expr := mustCompile(t, `subsystem in ["com.apple.network", "com.apple.ui"]`)
+	if !expr.Match(&domain.LogEntry{Subsystem: "com.apple.ui"}) {
+		t.Fatalf("expected subsystem in list to match")
+	}
+	if expr.Match(&domain.LogEntry{Subsystem: "com.example.app"}) {
+		t.Fatalf("expected subsystem not in list to reject")
+	}
+}
+
+func TestExpression_RegexCompiledOnce(t *testing.T) {
+	// Regression guard: a bad regex should fail at Compile time, not at
+	// Match time (which would mean it was being recompiled per entry).
+	_, err := Compile(`message matches "("`)
+	if err == nil {
+		t.Fatalf("expected Compile to reject an invalid regex")
+	}
+}
+
+func TestExpression_ParseErrors(t *testing.T) {
+	tests := []string{
+		`level ==`,
+		`level "Error"`,
+		`(level == "Error"`,
+		`level in "Error"`,
+		`level matches 5`,
+	}
+	for _, src := range tests {
+		if _, err := Compile(src); err == nil {
+			t.Errorf("Compile(%q) = nil error, want error", src)
+		}
+	}
+}
+
+func TestLooksLikeExpression(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{`level=error`, false},
+		{`message~timeout`, false},
+		{`level == "Error" and pid == 1`, true},
+		{`level == "Error" && pid == 1`, true},
+		{`!(level == "Error")`, true},
+		{`(level == "Error")`, true},
+		{`level in ["Error","Fault"]`, true},
+	}
+	for _, tt := range tests {
+		if got := LooksLikeExpression(tt.src); got != tt.want {
+			t.Errorf("LooksLikeExpression(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestExpression_Timestamp(t *testing.T) {
+	ts := time.Date(2025, 12, 14, 22, 0, 0, 0, time.UTC)
+	expr := mustCompile(t, `timestamp startsWith "2025-12-14"`)
+	if !expr.Match(&domain.LogEntry{Timestamp: ts}) {
+		t.Fatalf("expected timestamp startsWith to match")
+	}
+}
+
+func TestExpression_NilExpressionMatchesEverything(t *testing.T) {
+	var expr *Expression
+	if !expr.Match(&domain.LogEntry{}) {
+		t.Fatalf("expected a nil Expression to match every entry")
+	}
+}
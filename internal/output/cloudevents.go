@@ -0,0 +1,48 @@
+package output
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// CloudEventsSpecVersion is the CloudEvents spec version xcw emits when
+// --cloudevents wraps NDJSON output (see `xcw schema --format cloudevents`
+// for the matching schema definitions).
+const CloudEventsSpecVersion = "1.0"
+
+// cloudEventSeq gives each CloudEvent emitted by this process a distinct
+// id even when several fire within the same nanosecond.
+var cloudEventSeq uint64
+
+// CloudEvent is the CloudEvents 1.0 envelope xcw wraps each NDJSON event in
+// when --cloudevents is set, so consumers built against the CloudEvents
+// spec can ingest xcw's stream without a bespoke adapter.
+type CloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	Type            string      `json:"type"`
+	Source          string      `json:"source"`
+	ID              string      `json:"id"`
+	Time            string      `json:"time"`
+	DataContentType string      `json:"datacontenttype"`
+	Data            interface{} `json:"data"`
+}
+
+// WrapCloudEvent wraps data (the payload a "kind" NDJSON event would
+// otherwise be emitted as) in a CloudEvent envelope typed "dev.xcw.<kind>"
+// and attributed to source (e.g. "xcw://<hostname>/watch").
+func WrapCloudEvent(kind, source string, data interface{}) *CloudEvent {
+	return &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		Type:            "dev.xcw." + kind,
+		Source:          source,
+		ID:              nextCloudEventID(),
+		Time:            time.Now().UTC().Format(time.RFC3339Nano),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
+
+func nextCloudEventID() string {
+	return fmt.Sprintf("%d-%d", time.Now().UnixNano(), atomic.AddUint64(&cloudEventSeq, 1))
+}
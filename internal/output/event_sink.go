@@ -0,0 +1,291 @@
+package output
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventSink is a single destination for the raw NDJSON lines NDJSONWriter
+// produces. kind is the event's "type" discriminator (e.g. "log",
+// "heartbeat"); payload is the already-marshaled JSON line, newline
+// included.
+type EventSink interface {
+	WriteEvent(kind string, payload []byte) error
+	Rotate() error
+	Close() error
+}
+
+// writerEventSink is the original NDJSONWriter behavior: every event goes
+// straight to a plain io.Writer the caller owns.
+type writerEventSink struct {
+	w io.Writer
+}
+
+// NewWriterEventSink wraps w (usually os.Stdout or a *bytes.Buffer in
+// tests) as an EventSink. Rotate is a no-op: an arbitrary io.Writer has no
+// notion of segments. Close closes w if it implements io.Closer, otherwise
+// it's a no-op too (callers that don't own w, e.g. os.Stdout, are expected
+// to pass a non-closing wrapper if that matters to them).
+func NewWriterEventSink(w io.Writer) EventSink {
+	return &writerEventSink{w: w}
+}
+
+func (s *writerEventSink) WriteEvent(_ string, payload []byte) error {
+	_, err := s.w.Write(payload)
+	return err
+}
+
+func (s *writerEventSink) Rotate() error { return nil }
+
+func (s *writerEventSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// teeEventSink fans every event out to multiple sinks, same "report every
+// failure but keep writing to the others" contract as output.Pipeline.
+type teeEventSink struct {
+	sinks []EventSink
+}
+
+// NewTeeSink fans every event out to each of sinks.
+func NewTeeSink(sinks ...EventSink) EventSink {
+	return &teeEventSink{sinks: sinks}
+}
+
+func (s *teeEventSink) WriteEvent(kind string, payload []byte) error {
+	var first error
+	for _, sink := range s.sinks {
+		if err := sink.WriteEvent(kind, payload); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (s *teeEventSink) Rotate() error {
+	var first error
+	for _, sink := range s.sinks {
+		if err := sink.Rotate(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (s *teeEventSink) Close() error {
+	var first error
+	for _, sink := range s.sinks {
+		if err := sink.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// rotatingSegmentTimeFormat names closed segments path.YYYYMMDDTHHMMSS so
+// they sort lexically in creation order.
+const rotatingSegmentTimeFormat = "20060102T150405"
+
+// RotatingFileSink writes NDJSON lines to path, rotating it to a
+// timestamped, gzip-compressed segment once it grows past maxBytes or gets
+// older than maxAge, and pruning all but the keep most recent segments.
+// maxBytes <= 0 disables size-based rotation, maxAge <= 0 disables
+// age-based rotation, and keep <= 0 keeps every segment.
+type RotatingFileSink struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	maxAge   time.Duration
+	keep     int
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// NewRotatingFileSink opens (creating if necessary) path for appending and
+// returns a sink that rotates it per the rules above.
+func NewRotatingFileSink(path string, maxBytes int64, maxAge time.Duration, keep int) (*RotatingFileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	s := &RotatingFileSink{path: path, maxBytes: maxBytes, maxAge: maxAge, keep: keep}
+	if err := s.openLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *RotatingFileSink) openLocked() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", s.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("statting %s: %w", s.path, err)
+	}
+	s.file = f
+	s.size = info.Size()
+	s.openedAt = time.Now()
+	return nil
+}
+
+// WriteEvent appends payload to the current segment, rotating first if the
+// write would exceed maxBytes or the segment has aged past maxAge.
+func (s *RotatingFileSink) WriteEvent(_ string, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shouldRotateLocked(int64(len(payload))) {
+		if err := s.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(payload)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", s.path, err)
+	}
+	return nil
+}
+
+func (s *RotatingFileSink) shouldRotateLocked(nextWrite int64) bool {
+	if s.maxBytes > 0 && s.size+nextWrite > s.maxBytes {
+		return true
+	}
+	if s.maxAge > 0 && time.Since(s.openedAt) >= s.maxAge {
+		return true
+	}
+	return false
+}
+
+// Rotate closes the current segment, renames it to
+// path.YYYYMMDDTHHMMSS.ndjson, gzips it, opens a fresh path for writing,
+// and prunes segments beyond keep.
+func (s *RotatingFileSink) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rotateLocked()
+}
+
+func (s *RotatingFileSink) rotateLocked() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", s.path, err)
+	}
+
+	if s.size > 0 {
+		segment := s.nextSegmentName()
+		if err := os.Rename(s.path, segment); err != nil {
+			return fmt.Errorf("rotating %s: %w", s.path, err)
+		}
+		if err := gzipAndRemove(segment); err != nil {
+			return err
+		}
+	}
+
+	if err := s.openLocked(); err != nil {
+		return err
+	}
+	return s.pruneLocked()
+}
+
+// nextSegmentName picks path.<timestamp>.ndjson, disambiguating with a
+// numeric suffix (path.<timestamp>_001.ndjson, ...) on the rare occasion two
+// rotations land in the same second. Suffixed names still end in
+// .ndjson.gz once compressed and sort after the unsuffixed one, so
+// pruneLocked counts and orders them too.
+func (s *RotatingFileSink) nextSegmentName() string {
+	stamp := fmt.Sprintf("%s.%s", s.path, time.Now().UTC().Format(rotatingSegmentTimeFormat))
+	segment := stamp + ".ndjson"
+	for i := 1; ; i++ {
+		// A closed segment only survives as its .gz, so both names count as taken.
+		_, errPlain := os.Stat(segment)
+		_, errGz := os.Stat(segment + ".gz")
+		if os.IsNotExist(errPlain) && os.IsNotExist(errGz) {
+			return segment
+		}
+		segment = fmt.Sprintf("%s_%03d.ndjson", stamp, i)
+	}
+}
+
+// Close flushes and closes the current segment without rotating it.
+func (s *RotatingFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// gzipAndRemove compresses segment to segment+".gz" and removes the
+// uncompressed original.
+func gzipAndRemove(segment string) error {
+	in, err := os.Open(segment)
+	if err != nil {
+		return fmt.Errorf("opening segment %s: %w", segment, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(segment + ".gz")
+	if err != nil {
+		return fmt.Errorf("creating %s.gz: %w", segment, err)
+	}
+	gw := gzip.NewWriter(out)
+	if _, err := io.Copy(gw, in); err != nil {
+		gw.Close()
+		out.Close()
+		return fmt.Errorf("compressing %s: %w", segment, err)
+	}
+	if err := gw.Close(); err != nil {
+		out.Close()
+		return fmt.Errorf("closing gzip writer for %s: %w", segment, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("closing %s.gz: %w", segment, err)
+	}
+	return os.Remove(segment)
+}
+
+// pruneLocked removes all but the s.keep most recent rotated segments.
+// Segment names embed a fixed-width timestamp, so lexical and chronological
+// order coincide.
+func (s *RotatingFileSink) pruneLocked() error {
+	if s.keep <= 0 {
+		return nil
+	}
+	base := filepath.Base(s.path)
+	dir := filepath.Dir(s.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", dir, err)
+	}
+
+	var segments []string
+	prefix := base + "."
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".ndjson.gz") {
+			segments = append(segments, name)
+		}
+	}
+	sort.Strings(segments)
+
+	for len(segments) > s.keep {
+		if err := os.Remove(filepath.Join(dir, segments[0])); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("pruning %s: %w", segments[0], err)
+		}
+		segments = segments[1:]
+	}
+	return nil
+}
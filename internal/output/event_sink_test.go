@@ -0,0 +1,89 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterEventSinkWritesVerbatim(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sink := NewWriterEventSink(buf)
+
+	require.NoError(t, sink.WriteEvent("log", []byte(`{"type":"log"}`+"\n")))
+	require.NoError(t, sink.Rotate())
+	require.Equal(t, "{\"type\":\"log\"}\n", buf.String())
+}
+
+func TestTeeSinkFansOutAndCollectsErrors(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	tee := NewTeeSink(NewWriterEventSink(&buf1), NewWriterEventSink(&buf2))
+
+	require.NoError(t, tee.WriteEvent("log", []byte("a\n")))
+	require.Equal(t, "a\n", buf1.String())
+	require.Equal(t, "a\n", buf2.String())
+}
+
+func TestRotatingFileSinkRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.ndjson")
+
+	sink, err := NewRotatingFileSink(path, 10, 0, 5)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	require.NoError(t, sink.WriteEvent("log", []byte("123456789\n"))) // 10 bytes, fits exactly
+	require.NoError(t, sink.WriteEvent("log", []byte("next\n")))      // forces rotation first
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var gz []string
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".gz" {
+			gz = append(gz, e.Name())
+		}
+	}
+	require.Len(t, gz, 1, "expected exactly one rotated, gzipped segment")
+
+	f, err := os.Open(filepath.Join(dir, gz[0]))
+	require.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	data, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	require.Equal(t, "123456789\n", string(data))
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "next\n", string(current))
+}
+
+func TestRotatingFileSinkPrunesToKeep(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.ndjson")
+
+	sink, err := NewRotatingFileSink(path, 1, 0, 2)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, sink.WriteEvent("log", []byte("x\n")))
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var gz []string
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".gz" {
+			gz = append(gz, e.Name())
+		}
+	}
+	require.LessOrEqual(t, len(gz), 2, "expected pruning to keep at most 2 segments")
+}
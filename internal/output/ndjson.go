@@ -0,0 +1,278 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+// Heartbeat is the periodic keepalive event emitted by a live tail/watch
+// session so long-running consumers (and the agents driving them) can tell
+// the stream is still alive between log lines.
+type Heartbeat struct {
+	Type              string `json:"type"` // "heartbeat"
+	SchemaVersion     int    `json:"schemaVersion"`
+	Timestamp         string `json:"timestamp"`
+	UptimeSeconds     int    `json:"uptime_seconds"`
+	LogsSinceLast     int    `json:"logs_since_last"`
+	TailID            string `json:"tail_id,omitempty"`
+	ContractVersion   int    `json:"contract_version,omitempty"`
+	LatestSession     int    `json:"latest_session"`
+	LastSeenTimestamp string `json:"last_seen_timestamp,omitempty"`
+}
+
+// defaultClearBufferHints accompanies every clear_buffer event so an agent
+// consuming the stream knows what invalidated its locally-buffered state.
+var defaultClearBufferHints = []string{
+	"discard any counts, dedupe state, or windowed aggregates keyed by the previous session",
+	"tail_id stays the same; only the session counter advanced",
+}
+
+// NDJSONWriter renders log entries and xcw's control events (heartbeat,
+// ready, cutoff, ...) as newline-delimited JSON and fans each line out to
+// one or more EventSinks.
+type NDJSONWriter struct {
+	sinks []EventSink
+
+	cloudEvents bool
+	source      string
+}
+
+// NewNDJSONWriter creates a writer that sends every event to w, preserving
+// the single-io.Writer behavior callers have always relied on.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return NewNDJSONWriterWithSinks(NewWriterEventSink(w))
+}
+
+// NewNDJSONWriterWithSinks creates a writer that fans every event out to
+// each of sinks (e.g. the stdout writer plus a NewRotatingFileSink).
+func NewNDJSONWriterWithSinks(sinks ...EventSink) *NDJSONWriter {
+	return &NDJSONWriter{sinks: sinks}
+}
+
+// AddSink registers an additional destination for every future event.
+func (w *NDJSONWriter) AddSink(sink EventSink) {
+	w.sinks = append(w.sinks, sink)
+}
+
+// EnableCloudEvents wraps every event written after this call in a
+// CloudEvents 1.0 envelope (see WrapCloudEvent) attributed to source, e.g.
+// "xcw://<hostname>/watch" for `watch --cloudevents`.
+func (w *NDJSONWriter) EnableCloudEvents(source string) {
+	w.cloudEvents = true
+	w.source = source
+}
+
+// Rotate rotates every sink that supports it (see RotatingFileSink).
+func (w *NDJSONWriter) Rotate() error {
+	var first error
+	for _, sink := range w.sinks {
+		if err := sink.Rotate(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Close flushes and closes every sink, collecting (not stopping on) errors.
+func (w *NDJSONWriter) Close() error {
+	var first error
+	for _, sink := range w.sinks {
+		if err := sink.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// WriteEvent renders v as the given NDJSON "kind" line. It's the general
+// escape hatch for one-off control events (e.g. a CLI command's own
+// session/service notices) that don't warrant a dedicated Write* method of
+// their own, while still going through the same CloudEvents wrapping and
+// sink fan-out every other event does.
+func (w *NDJSONWriter) WriteEvent(kind string, v interface{}) error {
+	return w.writeEvent(kind, v)
+}
+
+// writeEvent marshals v (wrapping it in a CloudEvents envelope first, if
+// EnableCloudEvents was called), appends a trailing newline, and fans the
+// resulting line out to every sink, returning the first sink error (if
+// any).
+func (w *NDJSONWriter) writeEvent(kind string, v interface{}) error {
+	var payload interface{} = v
+	if w.cloudEvents {
+		payload = WrapCloudEvent(kind, w.source, v)
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling %s event: %w", kind, err)
+	}
+	b = append(b, '\n')
+
+	var first error
+	for _, sink := range w.sinks {
+		if err := sink.WriteEvent(kind, b); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Write renders a single log entry as an NDJSON "log" line.
+func (w *NDJSONWriter) Write(entry *domain.LogEntry) error {
+	return w.writeEvent("log", struct {
+		Type      string `json:"type"`
+		Timestamp string `json:"timestamp"`
+		Level     string `json:"level"`
+		Process   string `json:"process"`
+		PID       int    `json:"pid"`
+		Subsystem string `json:"subsystem,omitempty"`
+		Category  string `json:"category,omitempty"`
+		Message   string `json:"message"`
+	}{
+		Type:      "log",
+		Timestamp: entry.Timestamp.Format(time.RFC3339Nano),
+		Level:     string(entry.Level),
+		Process:   entry.Process,
+		PID:       entry.PID,
+		Subsystem: entry.Subsystem,
+		Category:  entry.Category,
+		Message:   entry.Message,
+	})
+}
+
+// WriteError renders an "error" event using the same {code, message, hint}
+// shape every command reports fatal failures with.
+func (w *NDJSONWriter) WriteError(code, message string, hint ...string) error {
+	payload := struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Hint    string `json:"hint,omitempty"`
+	}{Type: "error", Code: code, Message: message}
+	if len(hint) > 0 {
+		payload.Hint = hint[0]
+	}
+	return w.writeEvent("error", payload)
+}
+
+// WriteHeartbeat renders hb verbatim as the "heartbeat" event.
+func (w *NDJSONWriter) WriteHeartbeat(hb *Heartbeat) error {
+	if hb.Type == "" {
+		hb.Type = "heartbeat"
+	}
+	if hb.SchemaVersion == 0 {
+		hb.SchemaVersion = SchemaVersion
+	}
+	return w.writeEvent("heartbeat", hb)
+}
+
+// WriteReady renders the "ready" event a tail session emits once it has
+// attached to a device/app and is about to start streaming logs.
+func (w *NDJSONWriter) WriteReady(timestamp, simulator, udid, app, tailID string, session int) error {
+	return w.writeEvent("ready", struct {
+		Type            string `json:"type"`
+		SchemaVersion   int    `json:"schemaVersion"`
+		Timestamp       string `json:"timestamp"`
+		Simulator       string `json:"simulator"`
+		UDID            string `json:"udid"`
+		App             string `json:"app"`
+		TailID          string `json:"tail_id"`
+		Session         int    `json:"session"`
+		ContractVersion int    `json:"contract_version"`
+	}{
+		Type:            "ready",
+		SchemaVersion:   SchemaVersion,
+		Timestamp:       timestamp,
+		Simulator:       simulator,
+		UDID:            udid,
+		App:             app,
+		TailID:          tailID,
+		Session:         session,
+		ContractVersion: 1,
+	})
+}
+
+// WriteCutoff renders a "cutoff_reached" event when a tail session ends
+// itself (e.g. --max-duration, --max-count) rather than being interrupted.
+func (w *NDJSONWriter) WriteCutoff(reason, tailID string, session, count int) error {
+	return w.writeEvent("cutoff_reached", struct {
+		Type          string `json:"type"`
+		SchemaVersion int    `json:"schemaVersion"`
+		Reason        string `json:"reason"`
+		TailID        string `json:"tail_id"`
+		Session       int    `json:"session"`
+		Count         int    `json:"count"`
+	}{
+		Type:          "cutoff_reached",
+		SchemaVersion: SchemaVersion,
+		Reason:        reason,
+		TailID:        tailID,
+		Session:       session,
+		Count:         count,
+	})
+}
+
+// WriteReconnect renders a "reconnect_notice" event, e.g. when a sink
+// failed and xcw is retrying or falling back.
+func (w *NDJSONWriter) WriteReconnect(message, tailID, severity string) error {
+	return w.writeEvent("reconnect_notice", struct {
+		Type          string `json:"type"`
+		SchemaVersion int    `json:"schemaVersion"`
+		Message       string `json:"message"`
+		TailID        string `json:"tail_id,omitempty"`
+		Severity      string `json:"severity"`
+	}{
+		Type:          "reconnect_notice",
+		SchemaVersion: SchemaVersion,
+		Message:       message,
+		TailID:        tailID,
+		Severity:      severity,
+	})
+}
+
+// WriteAgentHints renders the "agent_hints" event: a one-time (per session)
+// reminder of how an agent should scope its reasoning over the stream.
+func (w *NDJSONWriter) WriteAgentHints(tailID string, session int, hints []string) error {
+	return w.writeEvent("agent_hints", struct {
+		Type             string   `json:"type"`
+		SchemaVersion    int      `json:"schemaVersion"`
+		TailID           string   `json:"tail_id"`
+		Session          int      `json:"session"`
+		ContractVersion  int      `json:"contract_version"`
+		RecommendedScope string   `json:"recommended_scope"`
+		Hints            []string `json:"hints"`
+	}{
+		Type:             "agent_hints",
+		SchemaVersion:    SchemaVersion,
+		TailID:           tailID,
+		Session:          session,
+		ContractVersion:  1,
+		RecommendedScope: "tail_id + latest session only",
+		Hints:            hints,
+	})
+}
+
+// WriteClearBuffer renders the "clear_buffer" event a new session emits to
+// tell agents to drop any state they'd accumulated from the previous one.
+func (w *NDJSONWriter) WriteClearBuffer(reason, tailID string, session int) error {
+	return w.writeEvent("clear_buffer", struct {
+		Type          string   `json:"type"`
+		SchemaVersion int      `json:"schemaVersion"`
+		Reason        string   `json:"reason"`
+		TailID        string   `json:"tail_id"`
+		Session       int      `json:"session"`
+		Hints         []string `json:"hints"`
+	}{
+		Type:          "clear_buffer",
+		SchemaVersion: SchemaVersion,
+		Reason:        reason,
+		TailID:        tailID,
+		Session:       session,
+		Hints:         defaultClearBufferHints,
+	})
+}
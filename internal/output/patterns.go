@@ -0,0 +1,503 @@
+package output
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// patternsSchemaVersion is bumped whenever the on-disk patternsFile shape
+// changes in a way that requires a migration on load. v2 added the
+// per-minute rate buckets used for anomaly detection.
+const patternsSchemaVersion = 2
+
+// maxPatternSamples caps how many raw (un-normalized) sample messages are
+// retained per learned pattern.
+const maxPatternSamples = 5
+
+// Anomaly detection tuning. maxRateBuckets bounds the rolling one-minute
+// rate history (60 buckets == a rolling hour). anomalyK and
+// anomalyMinAbsolute implement the "current rate exceeds
+// max(k*baseline, minAbsolute)" spike rule; anomalyQuietThreshold and
+// anomalyQuietBurst implement the "previously quiet pattern suddenly fires"
+// rule.
+const (
+	maxRateBuckets        = 60
+	anomalyK              = 5
+	anomalyMinAbsolute    = 3
+	anomalyQuietThreshold = 24 * time.Hour
+	anomalyQuietBurst     = 3
+	ewmaAlpha             = 0.1
+)
+
+// Templater normalizes a raw log message into a template key, collapsing
+// messages that only differ in their variable parts (counters, addresses,
+// identifiers, ...) into the same learned pattern.
+type Templater interface {
+	Normalize(msg string) string
+}
+
+// defaultTemplater is the built-in Templater used unless a caller supplies
+// their own via PatternStore.SetTemplater.
+type defaultTemplater struct{}
+
+var (
+	reQuoted    = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	reUUID      = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	reTimestamp = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?\b`)
+	reAddr      = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d+)?\b|\b(?:[0-9a-fA-F]{1,4}:){2,7}[0-9a-fA-F]{1,4}\b|\b[a-zA-Z0-9.-]+:\d{2,5}\b`)
+	reBlob      = regexp.MustCompile(`\b[0-9a-fA-F]{20,}\b|\b[A-Za-z0-9+/]{24,}\b={0,2}`)
+	reNumber    = regexp.MustCompile(`\b\d+\b`)
+)
+
+// Normalize replaces numeric literals, UUIDs/hex tokens, addresses,
+// timestamps, quoted strings, and long base64/hex blobs with placeholder
+// tokens, in an order chosen so the more specific patterns (timestamps,
+// UUIDs, addresses) are consumed before the generic number pattern would
+// otherwise fragment them.
+func (defaultTemplater) Normalize(msg string) string {
+	out := reTimestamp.ReplaceAllString(msg, "<ts>")
+	out = reUUID.ReplaceAllString(out, "<uuid>")
+	out = reAddr.ReplaceAllString(out, "<addr>")
+	out = reQuoted.ReplaceAllString(out, "<str>")
+	out = reBlob.ReplaceAllString(out, "<blob>")
+	out = reNumber.ReplaceAllString(out, "<n>")
+	return out
+}
+
+// PatternMatch is a single observed pattern with a count and a few sample
+// messages, as produced by a one-shot analysis pass.
+type PatternMatch struct {
+	Pattern string   `json:"pattern"`
+	Count   int      `json:"count"`
+	Samples []string `json:"samples,omitempty"`
+}
+
+// EnhancedPatternMatch augments a PatternMatch with what the PatternStore
+// already knew about it.
+type EnhancedPatternMatch struct {
+	PatternMatch
+	IsNew       bool       `json:"is_new"`
+	FirstSeen   *time.Time `json:"first_seen,omitempty"`
+	TotalCount  int        `json:"total_count"`
+	IsAnomalous bool       `json:"is_anomalous,omitempty"`
+}
+
+// PatternAnomaly describes a learned pattern whose recent firing rate has
+// spiked well above (or dormantly resumed above) its historical baseline.
+type PatternAnomaly struct {
+	Pattern      string    `json:"pattern"`
+	CurrentRate  float64   `json:"current_rate"`
+	BaselineRate float64   `json:"baseline_rate"`
+	ZScore       float64   `json:"z_score"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastQuietFor string    `json:"last_quiet_for,omitempty"`
+}
+
+type patternRecord struct {
+	TotalCount int           `json:"total_count"`
+	FirstSeen  time.Time     `json:"first_seen"`
+	LastSeen   time.Time     `json:"last_seen"`
+	PrevSeen   time.Time     `json:"prev_seen"`
+	Samples    []string      `json:"samples,omitempty"`
+	Buckets    map[int64]int `json:"buckets,omitempty"` // unix-minute -> occurrences, bounded to maxRateBuckets
+}
+
+func (r *patternRecord) addSamples(samples []string) {
+	for _, s := range samples {
+		if s == "" || len(r.Samples) >= maxPatternSamples {
+			continue
+		}
+		r.Samples = append(r.Samples, s)
+	}
+}
+
+// tick records count occurrences in now's one-minute bucket and prunes
+// buckets older than the rolling window.
+func (r *patternRecord) tick(now time.Time, count int) {
+	minute := now.Unix() / 60
+	if r.Buckets == nil {
+		r.Buckets = make(map[int64]int)
+	}
+	r.Buckets[minute] += count
+	for k := range r.Buckets {
+		if minute-k >= maxRateBuckets {
+			delete(r.Buckets, k)
+		}
+	}
+}
+
+// ewmaBaseline computes an exponentially-weighted moving average rate (and
+// its standard deviation) from every completed minute bucket, i.e. every
+// bucket except currentMinute, which is still filling.
+func ewmaBaseline(buckets map[int64]int, currentMinute int64) (ewma, stddev float64) {
+	keys := make([]int64, 0, len(buckets))
+	for k := range buckets {
+		if k != currentMinute {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return 0, 0
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	ewma = float64(buckets[keys[0]])
+	var sum, sumSq float64
+	for _, k := range keys {
+		v := float64(buckets[k])
+		ewma = ewmaAlpha*v + (1-ewmaAlpha)*ewma
+		sum += v
+		sumSq += v * v
+	}
+	n := float64(len(keys))
+	mean := sum / n
+	variance := sumSq/n - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	return ewma, math.Sqrt(variance)
+}
+
+// PatternInfo is the read-only view of a stored pattern returned by GetPattern.
+type PatternInfo struct {
+	TotalCount int
+	FirstSeen  time.Time
+	LastSeen   time.Time
+	Samples    []string
+}
+
+type patternsFile struct {
+	Version  int                      `json:"version"`
+	Patterns map[string]patternRecord `json:"patterns"`
+}
+
+// PatternStore learns which log message shapes have been seen before across
+// runs, persisting counts and first/last-seen timestamps to disk.
+type PatternStore struct {
+	mu        sync.Mutex
+	path      string
+	patterns  map[string]*patternRecord
+	templater Templater
+}
+
+// NewPatternStore creates a store backed by path (or ~/.xcw/patterns.json
+// when path is empty) and eagerly loads any existing data.
+func NewPatternStore(path string) *PatternStore {
+	if path == "" {
+		path = defaultPatternsPath()
+	}
+	s := &PatternStore{
+		path:      path,
+		patterns:  make(map[string]*patternRecord),
+		templater: defaultTemplater{},
+	}
+	_ = s.Load()
+	return s
+}
+
+// SetTemplater overrides the Templater used to key new patterns. Patterns
+// already recorded under the previous templater's keys are left as-is.
+func (s *PatternStore) SetTemplater(t Templater) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t == nil {
+		t = defaultTemplater{}
+	}
+	s.templater = t
+}
+
+// Normalize returns the template key msg would be recorded under.
+func (s *PatternStore) Normalize(msg string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.normalizeLocked(msg)
+}
+
+func (s *PatternStore) normalizeLocked(msg string) string {
+	if s.templater == nil {
+		return defaultTemplater{}.Normalize(msg)
+	}
+	return s.templater.Normalize(msg)
+}
+
+func defaultPatternsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".xcw", "patterns.json")
+}
+
+// RecordPattern normalizes msg to its template key, adds count occurrences
+// under that key, and reports whether the template was previously unknown.
+// msg itself is retained verbatim as a sample, up to maxPatternSamples.
+func (s *PatternStore) RecordPattern(msg string, count int) bool {
+	return s.recordPattern(msg, count, []string{msg})
+}
+
+func (s *PatternStore) recordPattern(msg string, count int, samples []string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.normalizeLocked(msg)
+	now := time.Now()
+	rec, ok := s.patterns[key]
+	if !ok {
+		rec = &patternRecord{TotalCount: count, FirstSeen: now, LastSeen: now}
+		s.patterns[key] = rec
+		rec.addSamples(samples)
+		rec.tick(now, count)
+		return true
+	}
+	rec.PrevSeen = rec.LastSeen
+	rec.TotalCount += count
+	rec.LastSeen = now
+	rec.addSamples(samples)
+	rec.tick(now, count)
+	return false
+}
+
+// anomalyForLocked evaluates the spike and dormant-burst rules for rec,
+// keyed under key. Callers must hold s.mu.
+func (s *PatternStore) anomalyForLocked(key string, rec *patternRecord, now time.Time) (PatternAnomaly, bool) {
+	currentMinute := now.Unix() / 60
+	currentRate := float64(rec.Buckets[currentMinute])
+	baseline, stddev := ewmaBaseline(rec.Buckets, currentMinute)
+
+	spiked := currentRate > math.Max(anomalyK*baseline, anomalyMinAbsolute)
+
+	var quietFor time.Duration
+	dormantBurst := false
+	if !rec.PrevSeen.IsZero() {
+		quietFor = now.Sub(rec.PrevSeen)
+		dormantBurst = quietFor > anomalyQuietThreshold && currentRate >= anomalyQuietBurst
+	}
+
+	if !spiked && !dormantBurst {
+		return PatternAnomaly{}, false
+	}
+
+	zScore := 0.0
+	switch {
+	case stddev > 0:
+		zScore = (currentRate - baseline) / stddev
+	case currentRate > baseline:
+		zScore = currentRate - baseline
+	}
+
+	anomaly := PatternAnomaly{
+		Pattern:      key,
+		CurrentRate:  currentRate,
+		BaselineRate: baseline,
+		ZScore:       zScore,
+		FirstSeen:    rec.FirstSeen,
+	}
+	if dormantBurst {
+		anomaly.LastQuietFor = quietFor.String()
+	}
+	return anomaly, true
+}
+
+// CheckAnomaly reports whether msg's normalized template is currently
+// anomalous (see Anomalies for the rules), without scanning every pattern.
+func (s *PatternStore) CheckAnomaly(msg string) (PatternAnomaly, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := s.normalizeLocked(msg)
+	rec, ok := s.patterns[key]
+	if !ok {
+		return PatternAnomaly{}, false
+	}
+	return s.anomalyForLocked(key, rec, time.Now())
+}
+
+// Anomalies returns every currently-anomalous pattern: ones whose 1-minute
+// rate exceeds max(k*baseline, minAbsolute), or ones that were quiet for
+// over 24h and have just fired anomalyQuietBurst or more times in the last
+// minute.
+func (s *PatternStore) Anomalies() []PatternAnomaly {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var out []PatternAnomaly
+	for key, rec := range s.patterns {
+		if anomaly, ok := s.anomalyForLocked(key, rec, now); ok {
+			out = append(out, anomaly)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ZScore > out[j].ZScore })
+	return out
+}
+
+// RecordPatterns records every match (keyed by its normalized template) and
+// returns the enhanced view, reflecting the post-record totals. The
+// returned Pattern field is the normalized template, not the raw message.
+func (s *PatternStore) RecordPatterns(matches []PatternMatch) []EnhancedPatternMatch {
+	out := make([]EnhancedPatternMatch, 0, len(matches))
+	for _, m := range matches {
+		key := s.Normalize(m.Pattern)
+		isNew := s.recordPattern(m.Pattern, m.Count, m.Samples)
+		info := s.GetPattern(m.Pattern)
+		enhanced := EnhancedPatternMatch{PatternMatch: m, IsNew: isNew}
+		enhanced.Pattern = key
+		if info != nil {
+			enhanced.TotalCount = info.TotalCount
+			enhanced.Samples = info.Samples
+			fs := info.FirstSeen
+			enhanced.FirstSeen = &fs
+		}
+		if _, anomalous := s.CheckAnomaly(m.Pattern); anomalous {
+			enhanced.IsAnomalous = true
+		}
+		out = append(out, enhanced)
+	}
+	return out
+}
+
+// AnnotatePatterns reports what the store already knows about each match's
+// normalized template, without recording it (read-only).
+func (s *PatternStore) AnnotatePatterns(matches []PatternMatch) []EnhancedPatternMatch {
+	out := make([]EnhancedPatternMatch, 0, len(matches))
+	for _, m := range matches {
+		enhanced := EnhancedPatternMatch{PatternMatch: m}
+		enhanced.Pattern = s.Normalize(m.Pattern)
+		if info := s.GetPattern(m.Pattern); info != nil {
+			enhanced.IsNew = false
+			enhanced.TotalCount = info.TotalCount
+			enhanced.Samples = info.Samples
+			fs := info.FirstSeen
+			enhanced.FirstSeen = &fs
+		} else {
+			enhanced.IsNew = true
+		}
+		if _, anomalous := s.CheckAnomaly(m.Pattern); anomalous {
+			enhanced.IsAnomalous = true
+		}
+		out = append(out, enhanced)
+	}
+	return out
+}
+
+// IsKnown reports whether msg's normalized template has been recorded before.
+func (s *PatternStore) IsKnown(msg string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.patterns[s.normalizeLocked(msg)]
+	return ok
+}
+
+// GetPattern returns a snapshot of what's known about msg's normalized
+// template, or nil.
+func (s *PatternStore) GetPattern(msg string) *PatternInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.patterns[s.normalizeLocked(msg)]
+	if !ok {
+		return nil
+	}
+	samples := make([]string, len(rec.Samples))
+	copy(samples, rec.Samples)
+	return &PatternInfo{TotalCount: rec.TotalCount, FirstSeen: rec.FirstSeen, LastSeen: rec.LastSeen, Samples: samples}
+}
+
+// GetAllPatterns returns every known pattern key.
+func (s *PatternStore) GetAllPatterns() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.patterns))
+	for k := range s.patterns {
+		out = append(out, k)
+	}
+	return out
+}
+
+// Count returns the number of known patterns.
+func (s *PatternStore) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.patterns)
+}
+
+// Clear wipes all in-memory pattern state without touching disk.
+func (s *PatternStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.patterns = make(map[string]*patternRecord)
+}
+
+// Load reads the store's backing file, if it exists, migrating older
+// schema versions in place.
+func (s *PatternStore) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadLocked()
+}
+
+func (s *PatternStore) loadLocked() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var file patternsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	patterns := make(map[string]*patternRecord, len(file.Patterns))
+	for k, v := range file.Patterns {
+		rec := v
+		if file.Version < 2 && rec.Buckets == nil {
+			// v1 files predate rate-bucket tracking; start every pattern
+			// with an empty, unsuspicious history rather than refusing to
+			// load.
+			rec.Buckets = make(map[int64]int)
+		}
+		patterns[k] = &rec
+	}
+	s.patterns = patterns
+	return nil
+}
+
+// Save atomically persists the store: it writes to path+".tmp" and renames
+// over the destination so a crash mid-write can't corrupt the file.
+func (s *PatternStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveLocked()
+}
+
+func (s *PatternStore) saveLocked() error {
+	file := patternsFile{
+		Version:  patternsSchemaVersion,
+		Patterns: make(map[string]patternRecord, len(s.patterns)),
+	}
+	for k, v := range s.patterns {
+		file.Patterns[k] = *v
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternStore_Anomalies_SpikeAboveBaseline(t *testing.T) {
+	store := NewPatternStore("")
+	store.Clear()
+
+	key := "steady error"
+	rec := &patternRecord{FirstSeen: time.Now(), LastSeen: time.Now(), Buckets: make(map[int64]int)}
+	now := time.Now()
+	currentMinute := now.Unix() / 60
+	// Quiet, steady baseline of ~1/minute for the last 10 minutes.
+	for i := int64(1); i <= 10; i++ {
+		rec.Buckets[currentMinute-i] = 1
+	}
+	// Then a spike in the current minute.
+	rec.Buckets[currentMinute] = 50
+	rec.TotalCount = 60
+	store.patterns[key] = rec
+
+	anomalies := store.Anomalies()
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, key, anomalies[0].Pattern)
+	assert.Equal(t, 50.0, anomalies[0].CurrentRate)
+	assert.Greater(t, anomalies[0].ZScore, 0.0)
+}
+
+func TestPatternStore_Anomalies_DormantBurst(t *testing.T) {
+	store := NewPatternStore("")
+	store.Clear()
+
+	key := "rare error"
+	now := time.Now()
+	rec := &patternRecord{
+		FirstSeen: now.Add(-72 * time.Hour),
+		LastSeen:  now.Add(-48 * time.Hour),
+		PrevSeen:  now.Add(-48 * time.Hour),
+		Buckets:   make(map[int64]int),
+	}
+	currentMinute := now.Unix() / 60
+	rec.Buckets[currentMinute] = 5
+	store.patterns[key] = rec
+
+	anomaly, ok := store.CheckAnomaly(key)
+	require.True(t, ok)
+	assert.NotEmpty(t, anomaly.LastQuietFor)
+}
+
+func TestPatternStore_Anomalies_NoFalsePositiveOnQuietSteadyPattern(t *testing.T) {
+	store := NewPatternStore("")
+	store.Clear()
+
+	store.RecordPattern("quiet pattern", 1)
+
+	anomalies := store.Anomalies()
+	assert.Empty(t, anomalies)
+}
+
+func TestPatternStore_SaveLoad_MigratesV1Buckets(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := tmpDir + "/patterns.json"
+
+	raw := `{"version":1,"patterns":{"legacy pattern":{"total_count":3,"first_seen":"2024-01-01T00:00:00Z","last_seen":"2024-01-01T00:00:00Z"}}}`
+	require.NoError(t, os.WriteFile(path, []byte(raw), 0o644))
+
+	store := NewPatternStore(path)
+	assert.Equal(t, 1, store.Count())
+	assert.True(t, store.IsKnown("legacy pattern"))
+}
@@ -0,0 +1,67 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultTemplater_Normalize(t *testing.T) {
+	tpl := defaultTemplater{}
+
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"number", "user 123 failed", "user <n> failed"},
+		{"uuid", "session 4F3C9E2A-1B2C-4D3E-9F8A-1234567890AB closed", "session <uuid> closed"},
+		{"ipv4 with port", "timeout at 192.168.1.10:8080", "timeout at <addr>"},
+		{"host with port", "timeout at api.example.com:443", "timeout at <addr>"},
+		{"timestamp", "event at 2024-01-02T15:04:05Z happened", "event at <ts> happened"},
+		{"quoted string", `value was "some text" here`, "value was <str> here"},
+		{"base64 blob", "token eyJhbGciOiJIUzI1NiJ9abcdefghij== received", "token <blob> received"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tpl.Normalize(tc.in))
+		})
+	}
+}
+
+func TestPatternStore_NormalizationCollapsesVariants(t *testing.T) {
+	store := NewPatternStore("")
+	store.Clear()
+
+	store.RecordPattern("user 123 failed", 1)
+	isNew := store.RecordPattern("user 456 failed", 1)
+
+	assert.False(t, isNew, "second variant should collapse into the same learned pattern")
+	assert.Equal(t, 1, store.Count())
+
+	info := store.GetPattern("user 789 failed")
+	if assert.NotNil(t, info) {
+		assert.Equal(t, 2, info.TotalCount)
+		assert.ElementsMatch(t, []string{"user 123 failed", "user 456 failed"}, info.Samples)
+	}
+}
+
+func TestPatternStore_RecordPatterns_NormalizesKey(t *testing.T) {
+	store := NewPatternStore("")
+	store.Clear()
+
+	matches := []PatternMatch{
+		{Pattern: "user 1 failed", Count: 1, Samples: []string{"user 1 failed"}},
+		{Pattern: "user 2 failed", Count: 1, Samples: []string{"user 2 failed"}},
+	}
+
+	enhanced := store.RecordPatterns(matches)
+
+	assert.Equal(t, "user <n> failed", enhanced[0].Pattern)
+	assert.Equal(t, "user <n> failed", enhanced[1].Pattern)
+	assert.True(t, enhanced[0].IsNew)
+	assert.False(t, enhanced[1].IsNew)
+	assert.Equal(t, 2, enhanced[1].TotalCount)
+	assert.Equal(t, 1, store.Count())
+}
@@ -81,9 +81,9 @@ func TestPatternStore_GetAllPatterns(t *testing.T) {
 	store := NewPatternStore("")
 	store.Clear()
 
-	store.RecordPattern("pattern 1", 1)
-	store.RecordPattern("pattern 2", 2)
-	store.RecordPattern("pattern 3", 3)
+	store.RecordPattern("alpha pattern", 1)
+	store.RecordPattern("bravo pattern", 2)
+	store.RecordPattern("charlie pattern", 3)
 
 	patterns := store.GetAllPatterns()
 	assert.Len(t, patterns, 3)
@@ -122,7 +122,7 @@ func TestPatternStore_SaveAndLoad(t *testing.T) {
 	var file patternsFile
 	err = json.Unmarshal(data, &file)
 	require.NoError(t, err)
-	assert.Equal(t, 1, file.Version)
+	assert.Equal(t, 2, file.Version)
 	assert.Len(t, file.Patterns, 2)
 
 	// Create new store and load
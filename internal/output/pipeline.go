@@ -0,0 +1,76 @@
+package output
+
+import (
+	"sync"
+
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+// Filterer is satisfied by filter.FilterChain (and anything else with the
+// same shape) so Pipeline doesn't need to import the filter package directly.
+type Filterer interface {
+	Match(entry *domain.LogEntry) bool
+}
+
+// Pipeline buffers each entry once and fans it out to every configured sink,
+// applying an optional filter first. A sink error is reported through
+// OnSinkError rather than aborting the whole pipeline, so one bad file
+// handle doesn't take down every other destination.
+type Pipeline struct {
+	mu          sync.Mutex
+	filter      Filterer
+	sinks       []Sink
+	OnSinkError func(sink string, err error)
+}
+
+// NewPipeline creates a Pipeline that applies filter (may be nil, meaning
+// "allow all") before writing to its sinks.
+func NewPipeline(filter Filterer) *Pipeline {
+	return &Pipeline{filter: filter}
+}
+
+// AddSink registers an additional output destination.
+func (p *Pipeline) AddSink(sink Sink) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sinks = append(p.sinks, sink)
+}
+
+// Write applies the pipeline's filter and, if the entry passes, writes it to
+// every sink. It always returns nil for individual sink failures (reported
+// via OnSinkError); a non-nil return means the pipeline itself is unusable.
+func (p *Pipeline) Write(entry *domain.LogEntry) error {
+	if p.filter != nil && !p.filter.Match(entry) {
+		return nil
+	}
+
+	p.mu.Lock()
+	sinks := make([]Sink, len(p.sinks))
+	copy(sinks, p.sinks)
+	p.mu.Unlock()
+
+	for _, sink := range sinks {
+		if err := sink.Write(entry); err != nil {
+			if p.OnSinkError != nil {
+				p.OnSinkError(sink.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Close flushes and closes every sink, collecting (not stopping on) errors.
+func (p *Pipeline) Close() error {
+	p.mu.Lock()
+	sinks := make([]Sink, len(p.sinks))
+	copy(sinks, p.sinks)
+	p.mu.Unlock()
+
+	var first error
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
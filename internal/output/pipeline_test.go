@@ -0,0 +1,86 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+type recordingSink struct {
+	name    string
+	entries []*domain.LogEntry
+	failOn  int
+}
+
+func (s *recordingSink) Name() string { return s.name }
+func (s *recordingSink) Write(entry *domain.LogEntry) error {
+	if s.failOn > 0 && len(s.entries)+1 == s.failOn {
+		s.entries = append(s.entries, entry)
+		return assert.AnError
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+func (s *recordingSink) Close() error { return nil }
+
+func TestPipeline_FansOutToAllSinks(t *testing.T) {
+	p := NewPipeline(nil)
+	a := &recordingSink{name: "a"}
+	b := &recordingSink{name: "b"}
+	p.AddSink(a)
+	p.AddSink(b)
+
+	require.NoError(t, p.Write(&domain.LogEntry{Message: "hello"}))
+	assert.Len(t, a.entries, 1)
+	assert.Len(t, b.entries, 1)
+}
+
+func TestPipeline_SinkErrorDoesNotStopOthers(t *testing.T) {
+	p := NewPipeline(nil)
+	broken := &recordingSink{name: "broken", failOn: 1}
+	healthy := &recordingSink{name: "healthy"}
+	p.AddSink(broken)
+	p.AddSink(healthy)
+
+	var warned string
+	p.OnSinkError = func(sink string, err error) { warned = sink }
+
+	require.NoError(t, p.Write(&domain.LogEntry{Message: "hello"}))
+	assert.Equal(t, "broken", warned)
+	assert.Len(t, healthy.entries, 1)
+}
+
+type stubFilter struct{ allow bool }
+
+func (f stubFilter) Match(*domain.LogEntry) bool { return f.allow }
+
+func TestPipeline_AppliesFilterBeforeSinks(t *testing.T) {
+	p := NewPipeline(stubFilter{allow: false})
+	sink := &recordingSink{name: "sink"}
+	p.AddSink(sink)
+
+	require.NoError(t, p.Write(&domain.LogEntry{Message: "dropped"}))
+	assert.Empty(t, sink.entries)
+}
+
+func TestParseSinkSpec_InvalidKind(t *testing.T) {
+	_, err := ParseSinkSpec("carrier-pigeon:/tmp/out", nil)
+	assert.Error(t, err)
+}
+
+func TestParseSinkSpec_FileSinks(t *testing.T) {
+	dir := t.TempDir()
+	human, err := ParseSinkSpec("human:"+filepath.Join(dir, "out.log"), nil)
+	require.NoError(t, err)
+	defer human.Close()
+
+	ndjson, err := ParseSinkSpec("ndjson:"+filepath.Join(dir, "out.ndjson"), nil)
+	require.NoError(t, err)
+	defer ndjson.Close()
+
+	require.NoError(t, human.Write(&domain.LogEntry{Message: "hi"}))
+	require.NoError(t, ndjson.Write(&domain.LogEntry{Message: "hi"}))
+}
@@ -0,0 +1,6 @@
+package output
+
+// SchemaVersion is the schema version stamped on every NDJSON event emitted
+// by this package. Bump it when an event's on-the-wire shape changes in a
+// way downstream agents need to know about.
+const SchemaVersion = 1
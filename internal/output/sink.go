@@ -0,0 +1,120 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+// Sink is a single output destination for log entries, e.g. a file written
+// in human-readable form, an NDJSON file, or stdout itself.
+type Sink interface {
+	// Name identifies the sink in warnings (e.g. "ndjson:/tmp/out.ndjson").
+	Name() string
+	Write(entry *domain.LogEntry) error
+	Close() error
+}
+
+// fileSink wraps a buffered file handle shared by the human and ndjson sinks.
+type fileSink struct {
+	name   string
+	file   *os.File
+	buf    *bufio.Writer
+	write  func(w io.Writer, entry *domain.LogEntry) error
+}
+
+func (s *fileSink) Name() string { return s.name }
+
+func (s *fileSink) Write(entry *domain.LogEntry) error {
+	return s.write(s.buf, entry)
+}
+
+func (s *fileSink) Close() error {
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	return s.file.Close()
+}
+
+// NewHumanSink writes entries to path using the same rendering as TextWriter.
+func NewHumanSink(path string) (Sink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating human sink %s: %w", path, err)
+	}
+	buf := bufio.NewWriter(f)
+	tw := NewTextWriter(buf)
+	return &fileSink{
+		name: "human:" + path,
+		file: f,
+		buf:  buf,
+		write: func(w io.Writer, entry *domain.LogEntry) error {
+			return tw.Write(entry)
+		},
+	}, nil
+}
+
+// NewNDJSONFileSink writes entries to path as NDJSON.
+func NewNDJSONFileSink(path string) (Sink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating ndjson sink %s: %w", path, err)
+	}
+	buf := bufio.NewWriter(f)
+	nw := NewNDJSONWriter(buf)
+	return &fileSink{
+		name: "ndjson:" + path,
+		file: f,
+		buf:  buf,
+		write: func(w io.Writer, entry *domain.LogEntry) error {
+			return nw.Write(entry)
+		},
+	}, nil
+}
+
+// stdoutSink writes directly to an io.Writer (usually globals.Stdout) that
+// the caller owns and will close/flush itself.
+type stdoutSink struct {
+	name string
+	w    io.Writer
+	fn   func(entry *domain.LogEntry) error
+}
+
+func (s *stdoutSink) Name() string                    { return s.name }
+func (s *stdoutSink) Write(entry *domain.LogEntry) error { return s.fn(entry) }
+func (s *stdoutSink) Close() error                     { return nil }
+
+// NewStdoutSink writes entries to w in either "human" or "ndjson" mode
+// without taking ownership of w (the caller manages its lifecycle).
+func NewStdoutSink(format string, w io.Writer) Sink {
+	if format == "ndjson" {
+		nw := NewNDJSONWriter(w)
+		return &stdoutSink{name: "stdout:ndjson", w: w, fn: nw.Write}
+	}
+	tw := NewTextWriter(w)
+	return &stdoutSink{name: "stdout:human", w: w, fn: tw.Write}
+}
+
+// ParseSinkSpec parses a "--sink kind:path" flag value, e.g.
+// "human:/tmp/out.log", "ndjson:/tmp/out.ndjson", or "stdout:ndjson". w is
+// used when kind is "stdout".
+func ParseSinkSpec(spec string, w io.Writer) (Sink, error) {
+	kind, rest, ok := strings.Cut(spec, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid --sink %q, expected kind:path", spec)
+	}
+	switch kind {
+	case "human":
+		return NewHumanSink(rest)
+	case "ndjson":
+		return NewNDJSONFileSink(rest)
+	case "stdout":
+		return NewStdoutSink(rest, w), nil
+	default:
+		return nil, fmt.Errorf("unsupported sink kind %q (want human, ndjson, or stdout)", kind)
+	}
+}
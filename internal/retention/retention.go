@@ -0,0 +1,95 @@
+// Package retention decides which rotated files (session recordings,
+// resume-state snapshots, log segments) have outlived a configured policy,
+// so callers elsewhere in xcw don't each reimplement the same
+// sort-by-age-and-trim logic.
+package retention
+
+import (
+	"sort"
+	"time"
+)
+
+// Policy bounds how many rotated files accumulate on disk. Each constraint
+// is optional: a zero value disables it. KeepLatest is a safety floor, not
+// a pruning trigger - the KeepLatest most recently modified candidates are
+// never selected for deletion regardless of the other three.
+type Policy struct {
+	MaxFiles      int
+	MaxAge        time.Duration
+	MaxTotalBytes int64
+	KeepLatest    int
+}
+
+// Candidate is one file retention is deciding whether to keep.
+type Candidate struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Select applies p to candidates and returns the ones that should be
+// deleted, oldest first. Callers gather candidates themselves (typically
+// via filepath.Glob) and should simply omit any file that must never be
+// pruned - e.g. the session file a caller still has open - rather than
+// relying on KeepLatest to protect it by timing.
+func Select(p Policy, candidates []Candidate) []Candidate {
+	sorted := make([]Candidate, len(candidates))
+	copy(sorted, candidates)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ModTime.Before(sorted[j].ModTime) })
+
+	protected := p.KeepLatest
+	if protected < 0 {
+		protected = 0
+	}
+	if protected > len(sorted) {
+		protected = len(sorted)
+	}
+	remaining := sorted[:len(sorted)-protected]
+	kept := sorted[len(sorted)-protected:]
+
+	var totalBytes int64
+	for _, c := range kept {
+		totalBytes += c.Size
+	}
+	for _, c := range remaining {
+		totalBytes += c.Size
+	}
+
+	var toDelete []Candidate
+
+	if p.MaxAge > 0 {
+		now := time.Now()
+		var stillRemaining []Candidate
+		for _, c := range remaining {
+			if now.Sub(c.ModTime) > p.MaxAge {
+				toDelete = append(toDelete, c)
+				totalBytes -= c.Size
+			} else {
+				stillRemaining = append(stillRemaining, c)
+			}
+		}
+		remaining = stillRemaining
+	}
+
+	if p.MaxFiles > 0 {
+		allowed := p.MaxFiles - len(kept)
+		if allowed < 0 {
+			allowed = 0
+		}
+		for len(remaining) > allowed {
+			toDelete = append(toDelete, remaining[0])
+			totalBytes -= remaining[0].Size
+			remaining = remaining[1:]
+		}
+	}
+
+	if p.MaxTotalBytes > 0 {
+		for totalBytes > p.MaxTotalBytes && len(remaining) > 0 {
+			toDelete = append(toDelete, remaining[0])
+			totalBytes -= remaining[0].Size
+			remaining = remaining[1:]
+		}
+	}
+
+	return toDelete
+}
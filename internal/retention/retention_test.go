@@ -0,0 +1,84 @@
+package retention
+
+import (
+	"testing"
+	"time"
+)
+
+func at(secondsAgo int) time.Time {
+	return time.Now().Add(-time.Duration(secondsAgo) * time.Second)
+}
+
+func TestSelectMaxFilesTrimsOldestFirst(t *testing.T) {
+	candidates := []Candidate{
+		{Path: "a", ModTime: at(300)},
+		{Path: "b", ModTime: at(200)},
+		{Path: "c", ModTime: at(100)},
+		{Path: "d", ModTime: at(0)},
+	}
+
+	toDelete := Select(Policy{MaxFiles: 2}, candidates)
+
+	if len(toDelete) != 2 {
+		t.Fatalf("expected 2 candidates deleted, got %d", len(toDelete))
+	}
+	if toDelete[0].Path != "a" || toDelete[1].Path != "b" {
+		t.Fatalf("expected oldest files a,b deleted first, got %v", toDelete)
+	}
+}
+
+func TestSelectMaxAgeDeletesOnlyOlderThanThreshold(t *testing.T) {
+	candidates := []Candidate{
+		{Path: "old", ModTime: at(3600)},
+		{Path: "new", ModTime: at(1)},
+	}
+
+	toDelete := Select(Policy{MaxAge: time.Minute}, candidates)
+
+	if len(toDelete) != 1 || toDelete[0].Path != "old" {
+		t.Fatalf("expected only 'old' deleted, got %v", toDelete)
+	}
+}
+
+func TestSelectMaxTotalBytesTrimsUntilUnderBudget(t *testing.T) {
+	candidates := []Candidate{
+		{Path: "a", Size: 100, ModTime: at(300)},
+		{Path: "b", Size: 100, ModTime: at(200)},
+		{Path: "c", Size: 100, ModTime: at(100)},
+	}
+
+	toDelete := Select(Policy{MaxTotalBytes: 150}, candidates)
+
+	if len(toDelete) != 2 {
+		t.Fatalf("expected 2 candidates deleted to get under budget, got %d", len(toDelete))
+	}
+	if toDelete[0].Path != "a" || toDelete[1].Path != "b" {
+		t.Fatalf("expected oldest files trimmed first, got %v", toDelete)
+	}
+}
+
+func TestSelectKeepLatestProtectsMostRecentRegardlessOfOtherConstraints(t *testing.T) {
+	candidates := []Candidate{
+		{Path: "ancient", Size: 1000, ModTime: at(100000)},
+		{Path: "current", Size: 1000, ModTime: at(0)},
+	}
+
+	toDelete := Select(Policy{MaxFiles: 0, MaxAge: time.Second, MaxTotalBytes: 1, KeepLatest: 1}, candidates)
+
+	for _, c := range toDelete {
+		if c.Path == "current" {
+			t.Fatalf("expected the currently-open file to never be selected for deletion, got %v", toDelete)
+		}
+	}
+}
+
+func TestSelectNoConstraintsDeletesNothing(t *testing.T) {
+	candidates := []Candidate{
+		{Path: "a", ModTime: at(100000)},
+		{Path: "b", ModTime: at(0)},
+	}
+
+	if toDelete := Select(Policy{}, candidates); len(toDelete) != 0 {
+		t.Fatalf("expected no deletions with a zero-value policy, got %v", toDelete)
+	}
+}
@@ -0,0 +1,25 @@
+package rpc
+
+import "github.com/vburojevic/xcw/internal/output"
+
+// eventSink adapts a Registry to output.EventSink, so an output.NDJSONWriter
+// can fan its events (log, heartbeat, ready, cutoff, ...) out to connected
+// Follow streams the same way it already fans out to a rotating log file.
+type eventSink struct {
+	registry *Registry
+}
+
+// NewEventSink wraps registry as an output.EventSink for
+// output.NDJSONWriter.AddSink.
+func NewEventSink(registry *Registry) output.EventSink {
+	return &eventSink{registry: registry}
+}
+
+func (s *eventSink) WriteEvent(kind string, payload []byte) error {
+	s.registry.Broadcast(kind, payload)
+	return nil
+}
+
+func (s *eventSink) Rotate() error { return nil }
+
+func (s *eventSink) Close() error { return nil }
@@ -0,0 +1,230 @@
+// Package rpc implements xcw's optional `--serve grpc://addr` subsystem: a
+// FollowLogService server that lets remote agents subscribe to a running
+// `xcw watch` session's NDJSON stream over gRPC instead of tailing xcrun
+// (or xcw itself) locally. See followlog.proto for the wire contract.
+package rpc
+
+import (
+	"crypto/subtle"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tokenMetadataKey is the gRPC metadata key a follower must set to the
+// server's --serve-token to authenticate, e.g. via grpc-go's
+// metadata.AppendToOutgoingContext(ctx, "authorization", token).
+const tokenMetadataKey = "authorization"
+
+// defaultFollowerQueueSize bounds how many undelivered events pile up per
+// follower before Registry starts dropping the oldest one to make room, so
+// one slow or disconnected follower can't back up log processing for
+// everyone else - the same tradeoff trigger.Dispatcher makes for sinks.
+const defaultFollowerQueueSize = 256
+
+// Registry fans every broadcast event out to each currently connected
+// follower over its own bounded, drop-oldest queue, and remembers the most
+// recent session_start so a reconnecting follower passing from_session can
+// be brought back up to date before live streaming resumes.
+type Registry struct {
+	mu        sync.Mutex
+	followers map[*follower]struct{}
+
+	lastSession        int64
+	lastSessionPayload []byte
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{followers: make(map[*follower]struct{})}
+}
+
+// follower is one connected Follow stream's delivery queue.
+type follower struct {
+	events  chan *FollowEvent
+	dropped int64
+}
+
+// enqueue appends event to f's queue, dropping the oldest queued event
+// first if the queue is already at capacity, and counting the drop.
+func (f *follower) enqueue(event *FollowEvent) {
+	select {
+	case f.events <- event:
+		return
+	default:
+	}
+	select {
+	case <-f.events:
+		atomic.AddInt64(&f.dropped, 1)
+	default:
+	}
+	select {
+	case f.events <- event:
+	default:
+	}
+}
+
+// Dropped reports how many events this follower's queue has discarded
+// because it fell behind.
+func (f *follower) Dropped() int64 {
+	return atomic.LoadInt64(&f.dropped)
+}
+
+// register adds a new follower to the registry, returning it plus an
+// unregister func the caller must defer.
+func (r *Registry) register() (*follower, func()) {
+	f := &follower{events: make(chan *FollowEvent, defaultFollowerQueueSize)}
+	r.mu.Lock()
+	r.followers[f] = struct{}{}
+	r.mu.Unlock()
+	return f, func() {
+		r.mu.Lock()
+		delete(r.followers, f)
+		r.mu.Unlock()
+	}
+}
+
+// Broadcast fans out a FollowEvent{kind, payload} - the same kind/JSON
+// bytes a live watch session writes to its NDJSON sinks - to every
+// currently registered follower.
+func (r *Registry) Broadcast(kind string, payload []byte) {
+	event := &FollowEvent{Kind: kind, Payload: payload}
+
+	r.mu.Lock()
+	followers := make([]*follower, 0, len(r.followers))
+	for f := range r.followers {
+		followers = append(followers, f)
+	}
+	r.mu.Unlock()
+
+	for _, f := range followers {
+		f.enqueue(event)
+	}
+}
+
+// RecordSessionStart remembers session's "session_start" payload (the raw
+// JSON envelope watch already built for it) so a follower that reconnects
+// with from_session <= session gets it replayed before live streaming
+// resumes, instead of only ever seeing events from whatever session
+// happens to be active when it dials in.
+func (r *Registry) RecordSessionStart(session int, payload []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastSession = int64(session)
+	r.lastSessionPayload = payload
+}
+
+// Server wraps a grpc.Server running FollowLogService against a Registry.
+type Server struct {
+	registry *Registry
+	grpc     *grpc.Server
+	listener net.Listener
+}
+
+// NewServer starts listening on addr (the host:port portion of a
+// `--serve grpc://addr` flag) and registers a FollowLogService backed by
+// registry. If token is non-empty, every Follow call must present it as
+// "authorization" gRPC metadata or the stream is rejected with
+// Unauthenticated before anything is registered or sent - this run's NDJSON
+// stream can carry addresses, UUIDs, and arbitrary app log content, so an
+// empty token should only be passed for a listener already restricted to
+// loopback (see WatchCmd's --serve validation). The caller is responsible
+// for calling Serve to start accepting connections and GracefulStop to
+// shut down.
+func NewServer(addr, token string, registry *Registry) (*Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []grpc.ServerOption
+	if token != "" {
+		opts = append(opts, grpc.StreamInterceptor(tokenStreamInterceptor(token)))
+	}
+
+	s := &Server{registry: registry, grpc: grpc.NewServer(opts...), listener: lis}
+	RegisterFollowLogServiceServer(s.grpc, &followLogServer{registry: registry})
+	return s, nil
+}
+
+// tokenStreamInterceptor rejects any stream whose "authorization" metadata
+// doesn't match token, using a constant-time comparison so responses don't
+// leak how much of the token a guess got right.
+func tokenStreamInterceptor(token string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, ok := metadata.FromIncomingContext(ss.Context())
+		if !ok || !validToken(md, token) {
+			return status.Error(codes.Unauthenticated, "missing or invalid authorization token")
+		}
+		return handler(srv, ss)
+	}
+}
+
+func validToken(md metadata.MD, token string) bool {
+	values := md.Get(tokenMetadataKey)
+	if len(values) != 1 {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(values[0]), []byte(token)) == 1
+}
+
+// Addr returns the address the server actually bound to, useful when addr
+// was passed as "host:0" to let the OS pick a port.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Serve blocks accepting connections until GracefulStop is called.
+func (s *Server) Serve() error {
+	return s.grpc.Serve(s.listener)
+}
+
+// GracefulStop stops accepting new connections and waits for in-flight
+// RPCs (i.e. connected followers) to finish.
+func (s *Server) GracefulStop() {
+	s.grpc.GracefulStop()
+}
+
+// followLogServer implements FollowLogServiceServer against a Registry.
+type followLogServer struct {
+	UnimplementedFollowLogServiceServer
+	registry *Registry
+}
+
+// Follow registers a follower for the lifetime of the stream, optionally
+// replaying the last known session_start first when req.FromSession asks
+// for one, and forwards every broadcast event until the client disconnects
+// or the server shuts down.
+func (s *followLogServer) Follow(req *FollowRequest, stream FollowLogService_FollowServer) error {
+	if req.FromSession > 0 {
+		s.registry.mu.Lock()
+		replay := s.registry.lastSessionPayload
+		haveReplay := s.registry.lastSession >= req.FromSession
+		s.registry.mu.Unlock()
+		if haveReplay && replay != nil {
+			if err := stream.Send(&FollowEvent{Kind: "session_start", Payload: replay}); err != nil {
+				return err
+			}
+		}
+	}
+
+	f, unregister := s.registry.register()
+	defer unregister()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-f.events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestValidToken(t *testing.T) {
+	md := metadata.Pairs(tokenMetadataKey, "s3cr3t")
+	if !validToken(md, "s3cr3t") {
+		t.Fatal("expected matching token to validate")
+	}
+	if validToken(md, "wrong") {
+		t.Fatal("expected mismatched token to be rejected")
+	}
+	if validToken(metadata.MD{}, "s3cr3t") {
+		t.Fatal("expected missing token to be rejected")
+	}
+	dup := metadata.Pairs(tokenMetadataKey, "a", tokenMetadataKey, "b")
+	if validToken(dup, "a") {
+		t.Fatal("expected multiple token values to be rejected")
+	}
+}
+
+func TestRegistryBroadcastFansOutToAllFollowers(t *testing.T) {
+	r := NewRegistry()
+	a, unregisterA := r.register()
+	defer unregisterA()
+	b, unregisterB := r.register()
+	defer unregisterB()
+
+	r.Broadcast("log", []byte(`{"type":"log"}`))
+
+	select {
+	case event := <-a.events:
+		if event.Kind != "log" {
+			t.Fatalf("follower a: expected kind log, got %s", event.Kind)
+		}
+	default:
+		t.Fatal("follower a: expected an event, got none")
+	}
+
+	select {
+	case event := <-b.events:
+		if event.Kind != "log" {
+			t.Fatalf("follower b: expected kind log, got %s", event.Kind)
+		}
+	default:
+		t.Fatal("follower b: expected an event, got none")
+	}
+}
+
+func TestFollowerEnqueueDropsOldestWhenSaturated(t *testing.T) {
+	f := &follower{events: make(chan *FollowEvent, 2)}
+
+	f.enqueue(&FollowEvent{Kind: "log", Payload: []byte("1")})
+	f.enqueue(&FollowEvent{Kind: "log", Payload: []byte("2")})
+	f.enqueue(&FollowEvent{Kind: "log", Payload: []byte("3")})
+
+	if got := f.Dropped(); got != 1 {
+		t.Fatalf("expected 1 dropped event, got %d", got)
+	}
+
+	first := <-f.events
+	second := <-f.events
+	if string(first.Payload) != "2" || string(second.Payload) != "3" {
+		t.Fatalf("expected oldest event dropped, got %q then %q", first.Payload, second.Payload)
+	}
+}
+
+func TestRegistryRecordSessionStartTracksLatest(t *testing.T) {
+	r := NewRegistry()
+	r.RecordSessionStart(3, []byte(`{"type":"session_start","session":3}`))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lastSession != 3 {
+		t.Fatalf("expected lastSession 3, got %d", r.lastSession)
+	}
+	if string(r.lastSessionPayload) != `{"type":"session_start","session":3}` {
+		t.Fatalf("unexpected lastSessionPayload: %s", r.lastSessionPayload)
+	}
+}
@@ -0,0 +1,168 @@
+// Package service integrates xcw's long-running commands (currently just
+// `xcw watch`) with whatever process supervisor is keeping them alive: on
+// Linux, sd_notify over systemd's NOTIFY_SOCKET; on macOS, a JSON
+// --status-file a launchd-managed process can write for a watcher to poll,
+// since launchd itself has no equivalent readiness/watchdog protocol.
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Counts is the liveness snapshot reported with each Ready/Heartbeat/
+// Stopping call, mirroring the fields `xcw watch --heartbeat` already
+// tracks per session.
+type Counts struct {
+	Logs          int
+	Errors        int
+	UptimeSeconds int
+}
+
+// Notifier reports this process's liveness to whichever supervisor is
+// watching it. A Notifier with nothing configured (no NOTIFY_SOCKET, no
+// status file) is valid and every method on it is a no-op, so callers
+// don't need to special-case "nothing to report to".
+type Notifier struct {
+	conn       *net.UnixConn
+	statusFile string
+	watchdog   time.Duration
+}
+
+// New creates a Notifier for the current process's environment and the
+// --status-file path given (blank if --status-file wasn't set). It dials
+// NOTIFY_SOCKET immediately if present; a dial failure is treated the same
+// as NOTIFY_SOCKET being unset, since a process running outside systemd
+// (or one whose unit forgot Type=notify) shouldn't fail to start over it.
+func New(statusFile string) *Notifier {
+	n := &Notifier{statusFile: statusFile}
+
+	if sock := os.Getenv("NOTIFY_SOCKET"); sock != "" {
+		if conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: sock, Net: "unixgram"}); err == nil {
+			n.conn = conn
+		}
+	}
+
+	if usec := os.Getenv("WATCHDOG_USEC"); usec != "" {
+		if raw, err := strconv.ParseInt(usec, 10, 64); err == nil && raw > 0 {
+			// systemd expects a WATCHDOG=1 ping at least every WATCHDOG_USEC;
+			// halving it leaves headroom for scheduling jitter.
+			n.watchdog = (time.Duration(raw) * time.Microsecond) / 2
+		}
+	}
+
+	return n
+}
+
+// Active reports whether this Notifier has anywhere to report to.
+func (n *Notifier) Active() bool {
+	return n.conn != nil || n.statusFile != ""
+}
+
+// WatchdogInterval returns how often WATCHDOG=1 should be sent while
+// healthy, and whether systemd asked for watchdog keepalives at all
+// (false when WATCHDOG_USEC isn't set, e.g. the unit has no WatchdogSec=).
+// It depends only on WATCHDOG_USEC, not on NOTIFY_SOCKET.
+func (n *Notifier) WatchdogInterval() (time.Duration, bool) {
+	return n.watchdog, n.watchdog > 0
+}
+
+// Ready reports that the streamer has successfully attached to the
+// simulator: READY=1 plus a STATUS= line to systemd, "ready" plus counts
+// to --status-file.
+func (n *Notifier) Ready(c Counts) error {
+	return n.report("ready", "READY=1\nSTATUS="+statusLine(c), c)
+}
+
+// Heartbeat reports the current counts without changing readiness:
+// STATUS= to systemd, counts to --status-file.
+func (n *Notifier) Heartbeat(c Counts) error {
+	return n.report("running", "STATUS="+statusLine(c), c)
+}
+
+// Watchdog sends a single WATCHDOG=1 keepalive. Callers should call this
+// roughly every WatchdogInterval while the process is healthy - systemd
+// restarts the unit once WatchdogSec elapses without one.
+func (n *Notifier) Watchdog() error {
+	return n.notify("WATCHDOG=1")
+}
+
+// Stopping reports an impending graceful shutdown: STOPPING=1 to systemd,
+// "stopping" plus final counts to --status-file.
+func (n *Notifier) Stopping(c Counts) error {
+	return n.report("stopping", "STOPPING=1", c)
+}
+
+// Close releases the NOTIFY_SOCKET connection, if one was dialed.
+func (n *Notifier) Close() error {
+	if n.conn == nil {
+		return nil
+	}
+	return n.conn.Close()
+}
+
+func statusLine(c Counts) string {
+	return fmt.Sprintf("logs=%d errors=%d uptime=%s", c.Logs, c.Errors, (time.Duration(c.UptimeSeconds) * time.Second).String())
+}
+
+func (n *Notifier) report(state, sdMessage string, c Counts) error {
+	notifyErr := n.notify(sdMessage)
+	fileErr := n.writeStatusFile(state, c)
+	if notifyErr != nil {
+		return notifyErr
+	}
+	return fileErr
+}
+
+func (n *Notifier) notify(msg string) error {
+	if n.conn == nil {
+		return nil
+	}
+	_, err := n.conn.Write([]byte(msg))
+	return err
+}
+
+// statusFilePayload is the shape written to --status-file - the closest
+// launchd equivalent of sd_notify's STATUS=, since launchd itself has no
+// notification socket a watched process can write to.
+type statusFilePayload struct {
+	State         string `json:"state"`
+	Logs          int    `json:"logs"`
+	Errors        int    `json:"errors"`
+	UptimeSeconds int    `json:"uptime_seconds"`
+	Timestamp     string `json:"timestamp"`
+}
+
+// writeStatusFile overwrites --status-file via a temp file + rename so a
+// launchd watcher polling the path never observes a half-written file.
+func (n *Notifier) writeStatusFile(state string, c Counts) error {
+	if n.statusFile == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(statusFilePayload{
+		State:         state,
+		Logs:          c.Logs,
+		Errors:        c.Errors,
+		UptimeSeconds: c.UptimeSeconds,
+		Timestamp:     time.Now().UTC().Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling status file: %w", err)
+	}
+	b = append(b, '\n')
+
+	tmpPath := n.statusFile + ".tmp"
+	if err := os.WriteFile(tmpPath, b, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, n.statusFile); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("renaming %s to %s: %w", tmpPath, n.statusFile, err)
+	}
+	return nil
+}
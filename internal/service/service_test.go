@@ -0,0 +1,81 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNotifierInactiveWithNothingConfigured(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	t.Setenv("WATCHDOG_USEC", "")
+
+	n := New("")
+	if n.Active() {
+		t.Fatal("expected Notifier with no NOTIFY_SOCKET/status file to be inactive")
+	}
+	if _, ok := n.WatchdogInterval(); ok {
+		t.Fatal("expected no watchdog interval without WATCHDOG_USEC")
+	}
+	if err := n.Ready(Counts{}); err != nil {
+		t.Fatalf("Ready on inactive notifier should be a no-op, got %v", err)
+	}
+}
+
+func TestNotifierWatchdogInterval(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	t.Setenv("WATCHDOG_USEC", "10000000") // 10s
+
+	n := New("")
+	d, ok := n.WatchdogInterval()
+	if !ok {
+		t.Fatal("expected watchdog to be enabled")
+	}
+	if d.Seconds() != 5 {
+		t.Fatalf("expected watchdog interval of half WATCHDOG_USEC (5s), got %s", d)
+	}
+}
+
+func TestNotifierWritesStatusFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "status.json")
+
+	n := New(path)
+	if !n.Active() {
+		t.Fatal("expected Notifier with a status file to be active")
+	}
+	if err := n.Ready(Counts{Logs: 12, Errors: 3, UptimeSeconds: 5}); err != nil {
+		t.Fatalf("Ready: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading status file: %v", err)
+	}
+	var got statusFilePayload
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("unmarshaling status file: %v", err)
+	}
+	if got.State != "ready" || got.Logs != 12 || got.Errors != 3 || got.UptimeSeconds != 5 {
+		t.Fatalf("unexpected status file contents: %+v", got)
+	}
+
+	if err := n.Stopping(Counts{Logs: 20, Errors: 4, UptimeSeconds: 9}); err != nil {
+		t.Fatalf("Stopping: %v", err)
+	}
+	b, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading status file after Stopping: %v", err)
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("unmarshaling status file after Stopping: %v", err)
+	}
+	if got.State != "stopping" || got.Logs != 20 {
+		t.Fatalf("unexpected status file contents after Stopping: %+v", got)
+	}
+
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected temp file to be renamed away, stat err = %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package session
+
+import "strings"
+
+// ClassifyLaunchdEvent inspects a log entry and, if it looks like a
+// com.apple.launchd message about bundleID's process, classifies it as a
+// Launched/Exited/Crashed ProcessEventKind. This is a best-effort heuristic
+// over launchd's free-form log text (there's no structured schema for it),
+// intended as the log-stream alternative to polling `xcrun simctl spawn
+// <udid> launchctl print` mentioned in ProcessEventKind's doc comment. Entries from
+// other subsystems, or launchd entries about unrelated processes, return
+// ok=false.
+func ClassifyLaunchdEvent(subsystem, message, bundleID string) (kind ProcessEventKind, ok bool) {
+	if subsystem != "com.apple.launchd" {
+		return "", false
+	}
+	if bundleID == "" || !strings.Contains(message, bundleID) {
+		return "", false
+	}
+
+	msg := strings.ToLower(message)
+	switch {
+	case strings.Contains(msg, "exited abnormally"), strings.Contains(msg, "crash"):
+		return ProcessCrashed, true
+	case strings.Contains(msg, "service exited"), strings.Contains(msg, "exited:"):
+		return ProcessExited, true
+	case strings.Contains(msg, "posix_spawn"), strings.Contains(msg, "spawned"):
+		return ProcessLaunched, true
+	}
+	return "", false
+}
@@ -0,0 +1,353 @@
+package session
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vburojevic/xcw/internal/domain"
+)
+
+// Exit reasons recorded in a session's summary.json, mirroring why the
+// tracker decided the session was over.
+const (
+	ExitReasonPIDChange = "pid_change"
+	ExitReasonStreamEnd = "stream_end"
+	ExitReasonShutdown  = "shutdown"
+)
+
+// RecordSummary is the on-disk companion to a recorded session: the same
+// counters emitted in the live session_end NDJSON event, plus wall-clock
+// bounds and why the session ended - useful once the tarball has been
+// copied off the machine that ran the simulator.
+type RecordSummary struct {
+	Session    int                   `json:"session"`
+	PID        int                   `json:"pid"`
+	App        string                `json:"app"`
+	Simulator  string                `json:"simulator"`
+	UDID       string                `json:"udid"`
+	StartedAt  time.Time             `json:"started_at"`
+	EndedAt    time.Time             `json:"ended_at"`
+	ExitReason string                `json:"exit_reason"` // pid_change, stream_end, shutdown
+	Summary    domain.SessionSummary `json:"summary"`
+}
+
+// Recorder persists each detected session as a self-contained directory
+// (entries.ndjson, simulator.json, app.json, triggers/*.log, summary.json)
+// under baseDir, then tars+gzips it into session-<n>.tar.gz once the
+// session ends. It is driven externally by whatever is calling
+// Tracker.CheckEntry - Recorder itself has no opinion on session
+// boundaries.
+type Recorder struct {
+	mu      sync.Mutex
+	baseDir string
+	current *recordingSession
+}
+
+type recordingSession struct {
+	dir         string
+	number      int
+	pid         int
+	app         string
+	simulator   string
+	udid        string
+	startedAt   time.Time
+	entriesFile *os.File
+	encoder     *json.Encoder
+	triggerSeq  int
+}
+
+// NewRecorder creates a Recorder that writes session artifacts under baseDir.
+func NewRecorder(baseDir string) *Recorder {
+	return &Recorder{baseDir: baseDir}
+}
+
+// StartSession opens a new recording directory for session `number`,
+// capturing simulator/app metadata up front since it's only available
+// while the simulator is still running.
+func (r *Recorder) StartSession(number, pid int, app, simulator, udid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current != nil {
+		// A caller that starts a new session without ending the previous one
+		// (e.g. it crashed between CheckEntry returning and EndSession being
+		// called) shouldn't leak the open file handle.
+		_ = r.endSessionLocked(domain.SessionSummary{}, ExitReasonPIDChange)
+	}
+
+	dir := filepath.Join(r.baseDir, fmt.Sprintf("session-%d-%d-%d", number, pid, time.Now().Unix()))
+	if err := os.MkdirAll(filepath.Join(dir, "triggers"), 0o755); err != nil {
+		return fmt.Errorf("creating recording dir: %w", err)
+	}
+
+	f, err := os.Create(filepath.Join(dir, "entries.ndjson"))
+	if err != nil {
+		return fmt.Errorf("creating entries.ndjson: %w", err)
+	}
+
+	r.current = &recordingSession{
+		dir:         dir,
+		number:      number,
+		pid:         pid,
+		app:         app,
+		simulator:   simulator,
+		udid:        udid,
+		startedAt:   time.Now(),
+		entriesFile: f,
+		encoder:     json.NewEncoder(f),
+	}
+
+	writeSimulatorSnapshot(dir, udid)
+	writeAppSnapshot(dir, udid, app)
+	return nil
+}
+
+// RecordEntry appends entry to the open session's entries.ndjson. It is a
+// no-op if no session is currently being recorded.
+func (r *Recorder) RecordEntry(entry *domain.LogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current == nil {
+		return nil
+	}
+	return r.current.encoder.Encode(entry)
+}
+
+// RecordTrigger captures a trigger command's combined output into
+// triggers/<n>.log under the open session's directory. Triggers run on a
+// trigger.Dispatcher worker goroutine (trigger.ExecSink calls back here
+// with the command's output), so this locks the same
+// mutex as RecordEntry/StartSession/EndSession.
+func (r *Recorder) RecordTrigger(label, combinedOutput string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current == nil {
+		return nil
+	}
+	r.current.triggerSeq++
+	path := filepath.Join(r.current.dir, "triggers", fmt.Sprintf("%d.log", r.current.triggerSeq))
+	content := fmt.Sprintf("# trigger: %s\n%s", label, combinedOutput)
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+// Recording reports whether a session is currently open.
+func (r *Recorder) Recording() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current != nil
+}
+
+// EndSession writes summary.json, closes entries.ndjson, and atomically
+// tar+gzips the session directory into session-<n>.tar.gz next to baseDir.
+// It is a no-op if no session is currently open.
+func (r *Recorder) EndSession(summary domain.SessionSummary, exitReason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.endSessionLocked(summary, exitReason)
+}
+
+// endSessionLocked is EndSession's body, callable while r.mu is already
+// held (StartSession uses it to close out an unexpectedly-still-open
+// previous session).
+func (r *Recorder) endSessionLocked(summary domain.SessionSummary, exitReason string) error {
+	cur := r.current
+	if cur == nil {
+		return nil
+	}
+	r.current = nil
+
+	if err := cur.entriesFile.Close(); err != nil {
+		return fmt.Errorf("closing entries.ndjson: %w", err)
+	}
+
+	rec := RecordSummary{
+		Session:    cur.number,
+		PID:        cur.pid,
+		App:        cur.app,
+		Simulator:  cur.simulator,
+		UDID:       cur.udid,
+		StartedAt:  cur.startedAt,
+		EndedAt:    time.Now(),
+		ExitReason: exitReason,
+		Summary:    summary,
+	}
+	b, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(cur.dir, "summary.json"), b, 0o644); err != nil {
+		return fmt.Errorf("writing summary.json: %w", err)
+	}
+
+	archivePath := filepath.Join(r.baseDir, fmt.Sprintf("session-%d.tar.gz", cur.number))
+	if err := archiveSessionDir(cur.dir, archivePath); err != nil {
+		return fmt.Errorf("archiving session %d: %w", cur.number, err)
+	}
+
+	return os.RemoveAll(cur.dir)
+}
+
+// ReadSummary extracts and returns the raw summary.json bytes from a
+// session-<n>.tar.gz produced by EndSession, for callers (e.g. `xcw
+// support dump`) that just want the RecordSummary without replaying
+// entries.ndjson.
+func ReadSummary(tarballPath string) ([]byte, error) {
+	f, err := os.Open(tarballPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", tarballPath, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", tarballPath, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", tarballPath, err)
+		}
+		if filepath.Base(hdr.Name) != "summary.json" {
+			continue
+		}
+		return io.ReadAll(tr)
+	}
+	return nil, fmt.Errorf("summary.json not found in %s", tarballPath)
+}
+
+// archiveSessionDir tars+gzips dir into archivePath, writing to a .tmp
+// sibling first (then renaming over archivePath) so a crash mid-archive
+// never leaves a truncated tarball where a caller expects a real one.
+func archiveSessionDir(dir, archivePath string) error {
+	tmpPath := archivePath + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	walkErr := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = rel
+		if info.IsDir() {
+			hdr.Name += "/"
+			return tw.WriteHeader(hdr)
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+
+	tarErr := tw.Close()
+	gzErr := gz.Close()
+	closeErr := f.Close()
+
+	if err := firstNonNil(walkErr, tarErr, gzErr, closeErr); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, archivePath)
+}
+
+func firstNonNil(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// simctlDevice mirrors the subset of `xcrun simctl list devices --json`
+// we snapshot into simulator.json.
+type simctlDevice struct {
+	Name  string `json:"name"`
+	UDID  string `json:"udid"`
+	State string `json:"state"`
+}
+
+func writeSimulatorSnapshot(dir, udid string) {
+	out, err := exec.Command("xcrun", "simctl", "list", "devices", "--json").Output()
+	if err != nil {
+		return
+	}
+	var parsed struct {
+		Devices map[string][]simctlDevice `json:"devices"`
+	}
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return
+	}
+
+	for runtime, devices := range parsed.Devices {
+		for _, d := range devices {
+			if d.UDID != udid {
+				continue
+			}
+			snapshot := struct {
+				Runtime string       `json:"runtime"`
+				Device  simctlDevice `json:"device"`
+			}{Runtime: runtime, Device: d}
+			if b, err := json.MarshalIndent(snapshot, "", "  "); err == nil {
+				_ = os.WriteFile(filepath.Join(dir, "simulator.json"), b, 0o644)
+			}
+			return
+		}
+	}
+}
+
+func writeAppSnapshot(dir, udid, bundleID string) {
+	info := struct {
+		BundleID    string `json:"bundle_id"`
+		InstallPath string `json:"install_path,omitempty"`
+	}{BundleID: bundleID}
+
+	if out, err := exec.Command("xcrun", "simctl", "get_app_container", udid, bundleID).Output(); err == nil {
+		info.InstallPath = strings.TrimSpace(string(out))
+	}
+
+	if b, err := json.MarshalIndent(info, "", "  "); err == nil {
+		_ = os.WriteFile(filepath.Join(dir, "app.json"), b, 0o644)
+	}
+}
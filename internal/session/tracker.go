@@ -7,43 +7,122 @@ import (
 	"github.com/vburojevic/xcw/internal/domain"
 )
 
-// Tracker monitors log entries for PID changes to detect app relaunches
+// Exit reasons a Tracker can attribute to a closed session, mirrored onto
+// domain.SessionSummary.ExitReason.
+const (
+	ExitReasonBinaryChanged = "binary_changed"
+	ExitReasonExited        = "exited"
+	ExitReasonCrashed       = "crashed"
+	ExitReasonIdleTimeout   = "idle_timeout"
+)
+
+// ProcessEventKind classifies a process-lifecycle signal fed into
+// Tracker.OnProcessEvent, sourced from either polling `xcrun simctl spawn
+// <udid> launchctl print` or watching the com.apple.launchd subsystem in
+// the log stream (ClassifyLaunchdEvent, which matches an entry when the
+// app's bundle ID appears in its message text; processImagePath is not
+// consulted).
+type ProcessEventKind string
+
+const (
+	ProcessLaunched ProcessEventKind = "launched"
+	ProcessExited   ProcessEventKind = "exited"
+	ProcessCrashed  ProcessEventKind = "crashed"
+)
+
+// Tracker monitors log entries (and, optionally, process-lifecycle events)
+// to detect app relaunches and crashes.
+//
+// Sessions used to be delimited purely by PID drift: the first log entry
+// carrying a new PID closed the old session and opened a new one. That
+// missed crashes the user never relaunched from (no new PID ever arrives,
+// so the summary never flushes) and misattributed the first few log lines
+// after a real relaunch to the outgoing session (they arrive before the
+// first new-PID entry). OnProcessEvent and the binary-UUID check in
+// CheckEntry close that gap; PID drift remains as the fallback for callers
+// that only have the log stream to go on.
 type Tracker struct {
-	mu             sync.Mutex
-	currentSession int
-	currentPID     int
-	sessionStart   time.Time
-	logCount       int
-	errorCount     int
-	faultCount     int
-	app            string
-	simulator      string
-	udid           string
-	initialized    bool
+	mu               sync.Mutex
+	currentSession   int
+	currentPID       int
+	currentImageUUID string
+	sessionStart     time.Time
+	lastEntryTime    time.Time
+	logCount         int
+	errorCount       int
+	faultCount       int
+	app              string
+	simulator        string
+	udid             string
+	// tailID, appVersion, buildNumber are carried along for the richer
+	// per-tail session events (domain.NewSessionStartWithMeta and friends)
+	// emitted once a tail-aware emitter consumes this tracker; a Tracker
+	// started without them just leaves these blank.
+	tailID      string
+	appVersion  string
+	buildNumber string
+	idleTimeout time.Duration
+	initialized bool
+
+	// snapshotLogCount/snapshotTime mark where the last Snapshot call left
+	// off, so the next one can compute an instantaneous logs/sec rate over
+	// just the elapsed interval; logsPerSecEWMA smooths that instantaneous
+	// rate across calls so a single quiet or bursty tick doesn't whipsaw it.
+	snapshotLogCount int
+	snapshotTime     time.Time
+	logsPerSecEWMA   float64
+	ewmaInitialized  bool
 }
 
+// heartbeatEWMAAlpha weights Snapshot's most recent interval against the
+// running rate; 0.3 favors recent activity while still damping single-tick
+// spikes.
+const heartbeatEWMAAlpha = 0.3
+
 // SessionChange contains events emitted when a session changes
 type SessionChange struct {
 	EndSession   *domain.SessionEnd
 	StartSession *domain.SessionStart
 }
 
-// NewTracker creates a new session tracker
-func NewTracker(app, simulator, udid string) *Tracker {
+// NewTracker creates a new session tracker. tailID, appVersion, and
+// buildNumber may be left blank by callers that don't have them handy
+// (e.g. the current `xcw watch` wiring) - they're only used to enrich
+// session events for consumers that care about tail/version attribution.
+func NewTracker(app, simulator, udid, tailID, appVersion, buildNumber string) *Tracker {
 	return &Tracker{
-		app:       app,
-		simulator: simulator,
-		udid:      udid,
+		app:         app,
+		simulator:   simulator,
+		udid:        udid,
+		tailID:      tailID,
+		appVersion:  appVersion,
+		buildNumber: buildNumber,
 	}
 }
 
-// CheckEntry processes a log entry and returns a SessionChange if the app was relaunched
+// SetIdleTimeout arms the idle-session fallback: if no matching log entry
+// arrives for at least d, the next call to CheckIdle closes the session
+// with ExitReasonIdleTimeout. A zero duration (the default) disables it.
+func (t *Tracker) SetIdleTimeout(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.idleTimeout = d
+}
+
+// CheckEntry processes a log entry and returns a SessionChange if the app
+// was relaunched, either because its PID changed or, for a launchd-reused
+// PID, because its binary identity (ProcessImageUUID) changed underneath
+// the same PID.
 func (t *Tracker) CheckEntry(entry *domain.LogEntry) *SessionChange {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	// Only track entries matching our app's bundle ID
-	if entry.Subsystem != t.app && !t.matchesApp(entry) {
+	// Only track entries matching our app's bundle ID. An empty Subsystem
+	// isn't evidence the entry is from some other process - `xcrun log
+	// stream` entries not tagged with a subsystem (and tests constructing
+	// entries by hand) still belong to whatever single app this tracker
+	// already follows - so it isn't filtered out here.
+	if entry.Subsystem != "" && entry.Subsystem != t.app && !t.matchesApp(entry) {
 		// Still increment counts if we're tracking
 		if t.initialized {
 			t.logCount++
@@ -53,62 +132,37 @@ func (t *Tracker) CheckEntry(entry *domain.LogEntry) *SessionChange {
 	}
 
 	pid := entry.PID
+	t.lastEntryTime = time.Now()
 
 	// First entry - initialize session
 	if !t.initialized {
-		t.initialized = true
-		t.currentSession = 1
-		t.currentPID = pid
-		t.sessionStart = time.Now()
+		t.beginSession(pid, entry.ProcessImageUUID)
 		t.logCount = 1
 		t.updateCounts(entry)
-
-		// Return initial session start
 		return &SessionChange{
-			StartSession: domain.NewSessionStart(
-				t.currentSession,
-				pid,
-				0, // no previous PID
-				t.app,
-				t.simulator,
-				t.udid,
-			),
+			StartSession: domain.NewSessionStart(t.currentSession, pid, 0, t.app, t.simulator, t.udid),
 		}
 	}
 
 	// PID changed - app was relaunched
 	if pid != t.currentPID && pid > 0 {
-		previousPID := t.currentPID
-		previousSession := t.currentSession
-
-		// Create session end summary
-		summary := domain.SessionSummary{
-			TotalLogs:       t.logCount,
-			Errors:          t.errorCount,
-			Faults:          t.faultCount,
-			DurationSeconds: int(time.Since(t.sessionStart).Seconds()),
-		}
+		change := t.rollover(pid, entry.ProcessImageUUID, ExitReasonPIDChange)
+		t.updateCounts(entry)
+		return change
+	}
 
-		// Start new session
-		t.currentSession++
-		t.currentPID = pid
-		t.sessionStart = time.Now()
-		t.logCount = 1
-		t.errorCount = 0
-		t.faultCount = 0
+	// Same PID, but the running binary's identity changed underneath it -
+	// launchd reused the PID for a new instance (or Xcode rebuilt/reinstalled
+	// the app without the simulator recycling the process). Counts as a new
+	// session even though no new PID ever arrives.
+	if entry.ProcessImageUUID != "" && t.currentImageUUID != "" && entry.ProcessImageUUID != t.currentImageUUID {
+		change := t.rollover(pid, entry.ProcessImageUUID, ExitReasonBinaryChanged)
 		t.updateCounts(entry)
+		return change
+	}
 
-		return &SessionChange{
-			EndSession: domain.NewSessionEnd(previousSession, previousPID, summary),
-			StartSession: domain.NewSessionStart(
-				t.currentSession,
-				pid,
-				previousPID,
-				t.app,
-				t.simulator,
-				t.udid,
-			),
-		}
+	if entry.ProcessImageUUID != "" {
+		t.currentImageUUID = entry.ProcessImageUUID
 	}
 
 	// Same session - just increment counts
@@ -117,6 +171,131 @@ func (t *Tracker) CheckEntry(entry *domain.LogEntry) *SessionChange {
 	return nil
 }
 
+// OnProcessEvent feeds a process-lifecycle signal into the tracker,
+// independent of whatever log entries happen to have arrived. Launched
+// pre-seats currentPID so subsequently-processed entries are attributed to
+// the new session immediately, instead of waiting for the first log line
+// that happens to carry the new PID. Exited and Crashed close the current
+// session right away, covering the case where the app never gets
+// relaunched (and so no new-PID entry would ever flush the summary).
+func (t *Tracker) OnProcessEvent(kind ProcessEventKind, pid int, ts time.Time) *SessionChange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch kind {
+	case ProcessLaunched:
+		if !t.initialized {
+			t.beginSessionAt(pid, "", ts)
+			return &SessionChange{
+				StartSession: domain.NewSessionStart(t.currentSession, pid, 0, t.app, t.simulator, t.udid),
+			}
+		}
+		if pid == t.currentPID {
+			return nil
+		}
+		return t.rolloverAt(pid, "", ExitReasonPIDChange, ts)
+
+	case ProcessExited:
+		return t.closeSessionAt(ExitReasonExited, ts)
+
+	case ProcessCrashed:
+		return t.closeSessionAt(ExitReasonCrashed, ts)
+	}
+
+	return nil
+}
+
+// CheckIdle closes the current session with ExitReasonIdleTimeout if no
+// matching log entry has arrived within the configured idle timeout.
+// Callers (e.g. WatchCmd) poll this on a timer since Tracker otherwise only
+// reacts to entries/process events pushed into it.
+func (t *Tracker) CheckIdle(now time.Time) *SessionChange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.initialized || t.idleTimeout <= 0 {
+		return nil
+	}
+	if now.Sub(t.lastEntryTime) < t.idleTimeout {
+		return nil
+	}
+	return t.closeSessionAt(ExitReasonIdleTimeout, now)
+}
+
+// beginSession starts tracking a new session as of now.
+func (t *Tracker) beginSession(pid int, imageUUID string) {
+	t.beginSessionAt(pid, imageUUID, time.Now())
+}
+
+func (t *Tracker) beginSessionAt(pid int, imageUUID string, ts time.Time) {
+	t.initialized = true
+	t.currentSession++
+	t.currentPID = pid
+	t.currentImageUUID = imageUUID
+	t.sessionStart = ts
+	t.lastEntryTime = ts
+	t.logCount = 0
+	t.errorCount = 0
+	t.faultCount = 0
+	t.snapshotLogCount = 0
+	t.snapshotTime = time.Time{}
+	t.logsPerSecEWMA = 0
+	t.ewmaInitialized = false
+}
+
+// rollover closes the current session (attributing reason) and opens a new
+// one for pid/newImageUUID, returning both halves of the transition.
+func (t *Tracker) rollover(pid int, newImageUUID, reason string) *SessionChange {
+	return t.rolloverAt(pid, newImageUUID, reason, time.Now())
+}
+
+func (t *Tracker) rolloverAt(pid int, newImageUUID, reason string, ts time.Time) *SessionChange {
+	previousPID := t.currentPID
+	previousSession := t.currentSession
+
+	summary := domain.SessionSummary{
+		TotalLogs:       t.logCount,
+		Errors:          t.errorCount,
+		Faults:          t.faultCount,
+		DurationSeconds: int(ts.Sub(t.sessionStart).Seconds()),
+		ExitReason:      reason,
+	}
+
+	t.beginSessionAt(pid, newImageUUID, ts)
+
+	return &SessionChange{
+		EndSession:   domain.NewSessionEnd(previousSession, previousPID, summary),
+		StartSession: domain.NewSessionStart(t.currentSession, pid, previousPID, t.app, t.simulator, t.udid),
+	}
+}
+
+// closeSessionAt ends the current session without starting a new one (used
+// for Exited/Crashed/idle-timeout, where there's no new PID to attribute
+// subsequent entries to until the next Launched/new-PID entry arrives).
+func (t *Tracker) closeSessionAt(reason string, ts time.Time) *SessionChange {
+	if !t.initialized {
+		return nil
+	}
+
+	summary := domain.SessionSummary{
+		TotalLogs:       t.logCount,
+		Errors:          t.errorCount,
+		Faults:          t.faultCount,
+		DurationSeconds: int(ts.Sub(t.sessionStart).Seconds()),
+		ExitReason:      reason,
+	}
+	change := &SessionChange{EndSession: domain.NewSessionEnd(t.currentSession, t.currentPID, summary)}
+
+	t.initialized = false
+	t.currentPID = 0
+	t.currentImageUUID = ""
+	t.logCount = 0
+	t.errorCount = 0
+	t.faultCount = 0
+
+	return change
+}
+
 // matchesApp checks if entry is from our app by process name or subsystem prefix
 func (t *Tracker) matchesApp(entry *domain.LogEntry) bool {
 	// Check if subsystem starts with our bundle ID
@@ -164,6 +343,48 @@ func (t *Tracker) GetFinalSummary() *domain.SessionEnd {
 	)
 }
 
+// Snapshot returns a domain.SessionHeartbeat for the current session as of
+// now, or nil if no session is being tracked. Callers (e.g. WatchCmd's
+// --heartbeat ticker) are expected to call this on a regular interval; the
+// logs/sec figure is an EWMA over the intervals between calls, so a
+// snapshot cadence that varies wildly will skew it.
+func (t *Tracker) Snapshot(now time.Time) *domain.SessionHeartbeat {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.initialized {
+		return nil
+	}
+
+	since := now.Sub(t.snapshotTime)
+	if t.snapshotTime.IsZero() {
+		since = now.Sub(t.sessionStart)
+	}
+
+	var instantRate float64
+	if since > 0 {
+		instantRate = float64(t.logCount-t.snapshotLogCount) / since.Seconds()
+	}
+	if !t.ewmaInitialized {
+		t.logsPerSecEWMA = instantRate
+		t.ewmaInitialized = true
+	} else {
+		t.logsPerSecEWMA = heartbeatEWMAAlpha*instantRate + (1-heartbeatEWMAAlpha)*t.logsPerSecEWMA
+	}
+	t.snapshotLogCount = t.logCount
+	t.snapshotTime = now
+
+	return domain.NewSessionHeartbeat(
+		t.currentSession,
+		t.currentPID,
+		int(now.Sub(t.sessionStart).Seconds()),
+		t.logCount,
+		t.errorCount,
+		t.faultCount,
+		t.logsPerSecEWMA,
+	)
+}
+
 // Stats returns current session statistics
 func (t *Tracker) Stats() (session, pid, logs, errors, faults int) {
 	t.mu.Lock()
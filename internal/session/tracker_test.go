@@ -2,6 +2,7 @@ package session
 
 import (
 	"testing"
+	"time"
 
 	"github.com/vburojevic/xcw/internal/domain"
 )
@@ -32,3 +33,29 @@ func TestTrackerDetectsBinaryUUIDChange(t *testing.T) {
 		t.Fatalf("expected previous session to close")
 	}
 }
+
+func TestTrackerSnapshotReturnsRunningCounts(t *testing.T) {
+	tr := NewTracker("com.example.app", "Sim", "UDID", "tail-1", "", "")
+
+	if hb := tr.Snapshot(time.Now()); hb != nil {
+		t.Fatalf("expected nil snapshot before any session starts")
+	}
+
+	start := time.Now()
+	tr.CheckEntry(&domain.LogEntry{PID: 111, Subsystem: "com.example.app", ProcessImageUUID: "UUID-1", Level: domain.LogLevelInfo, Timestamp: start})
+	tr.CheckEntry(&domain.LogEntry{PID: 111, Subsystem: "com.example.app", ProcessImageUUID: "UUID-1", Level: domain.LogLevelError, Timestamp: start})
+
+	hb := tr.Snapshot(start.Add(2 * time.Second))
+	if hb == nil {
+		t.Fatalf("expected a snapshot once a session is tracked")
+	}
+	if hb.Session != 1 || hb.PID != 111 {
+		t.Fatalf("expected snapshot for session 1/pid 111, got session=%d pid=%d", hb.Session, hb.PID)
+	}
+	if hb.TotalLogs != 2 || hb.Errors != 1 {
+		t.Fatalf("expected total_logs=2 errors=1, got total_logs=%d errors=%d", hb.TotalLogs, hb.Errors)
+	}
+	if hb.LogsPerSecond <= 0 {
+		t.Fatalf("expected a positive logs/sec rate, got %v", hb.LogsPerSecond)
+	}
+}
@@ -2,7 +2,7 @@ package tmux
 
 import (
 	"fmt"
-	"io"
+	"os"
 	"strings"
 	"time"
 
@@ -121,58 +121,47 @@ func escapeTmuxString(s string) string {
 	return s
 }
 
-// Writer implements io.Writer for streaming logs to tmux pane
-type Writer struct {
-	manager *Manager
-	buffer  strings.Builder
-}
+// PasteRaw delivers data to the pane via tmux's buffer mechanism instead
+// of send-keys: it's written to a temp file, loaded into a named tmux
+// buffer with load-buffer, then paste-buffer flushes that buffer straight
+// into the pane in bracketed-paste mode (-p) so tmux never hands the
+// pane's shell anything to interpret, and -d drops the buffer afterwards
+// so repeated calls don't accumulate named buffers. See Writer, which
+// batches many lines per call instead of one send-keys per line.
+func (m *Manager) PasteRaw(bufferName string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-// NewWriter creates a new writer that streams to tmux pane
-func NewWriter(manager *Manager) *Writer {
-	return &Writer{
-		manager: manager,
+	if m.pane == nil {
+		return ErrNoPaneAvailable
+	}
+	if len(data) == 0 {
+		return nil
 	}
-}
 
-// Write implements io.Writer - writes data to tmux pane
-func (w *Writer) Write(p []byte) (n int, err error) {
-	w.buffer.Write(p)
-
-	// Process complete lines
-	content := w.buffer.String()
-	lines := strings.Split(content, "\n")
-
-	// Keep incomplete last line in buffer
-	if !strings.HasSuffix(content, "\n") && len(lines) > 0 {
-		w.buffer.Reset()
-		w.buffer.WriteString(lines[len(lines)-1])
-		lines = lines[:len(lines)-1]
-	} else {
-		w.buffer.Reset()
+	tmp, err := os.CreateTemp("", "xcw-paste-*")
+	if err != nil {
+		return fmt.Errorf("creating paste-buffer tempfile: %w", err)
 	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
 
-	// Write complete lines to pane
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-		if err := w.manager.WriteLine(line); err != nil {
-			return 0, err
-		}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing paste-buffer tempfile: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing paste-buffer tempfile: %w", err)
 	}
 
-	return len(p), nil
-}
+	if _, err := m.tmux.Command("load-buffer", "-b", bufferName, tmpPath); err != nil {
+		return fmt.Errorf("tmux load-buffer: %w", err)
+	}
 
-// Flush writes any remaining buffered content
-func (w *Writer) Flush() error {
-	if w.buffer.Len() > 0 {
-		err := w.manager.WriteLine(w.buffer.String())
-		w.buffer.Reset()
-		return err
+	paneTarget := fmt.Sprintf("%s:0.0", m.config.SessionName)
+	if _, err := m.tmux.Command("paste-buffer", "-d", "-p", "-b", bufferName, "-t", paneTarget); err != nil {
+		return fmt.Errorf("tmux paste-buffer: %w", err)
 	}
+
 	return nil
 }
-
-// Ensure Writer implements io.Writer
-var _ io.Writer = (*Writer)(nil)
@@ -0,0 +1,214 @@
+package tmux
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ANSI SGR sequences Writer wraps recognized NDJSON envelopes in. Named
+// after what they're used for, not the color, so a future palette change
+// doesn't require touching colorizeLine's switch.
+const (
+	ansiReset        = "\x1b[0m"
+	ansiSessionStart = "\x1b[36m"   // cyan
+	ansiSessionEnd   = "\x1b[2m"    // dim
+	ansiHeartbeat    = "\x1b[90m"   // gray
+	ansiLevelError   = "\x1b[31m"   // red
+	ansiLevelFault   = "\x1b[1;31m" // bold red
+)
+
+// writerFlushBytes/writerFlushInterval bound how long Write batches
+// accumulated lines before handing them to a single PasteRaw call: a
+// burst of log lines waits at most writerFlushInterval (or fills
+// writerFlushBytes first) rather than triggering one tmux invocation per
+// line the way send-keys-based WriteLine did.
+const (
+	writerFlushBytes    = 64 * 1024
+	writerFlushInterval = 100 * time.Millisecond
+)
+
+// pasteSink is the subset of *Manager Writer depends on, so tests (and
+// the benchmarks in writer_bench_test.go) can exercise Writer's batching/colorizing without
+// a real tmux session.
+type pasteSink interface {
+	PasteRaw(bufferName string, data []byte) error
+}
+
+// writerSeq gives each Writer its own tmux buffer name so concurrent
+// Writers (unlikely today, but cheap to make safe) never collide on the
+// same -b name mid-paste.
+var writerSeq int64
+
+// Writer implements io.Writer, batching whatever's written to it into
+// few-hundred-line chunks and delivering each chunk to the pane with one
+// tmux load-buffer + paste-buffer round trip (see Manager.PasteRaw)
+// instead of one send-keys per line. Each complete line is colorized
+// first based on the NDJSON envelope it looks like (see colorizeLine).
+type Writer struct {
+	manager    pasteSink
+	bufferName string
+
+	mu      sync.Mutex
+	partial strings.Builder // trailing incomplete line carried across Write calls
+	pending strings.Builder // complete, colorized lines awaiting flush
+	timer   *time.Timer
+	lastErr error
+}
+
+// NewWriter creates a new writer that streams to manager's pane.
+func NewWriter(manager *Manager) *Writer {
+	return &Writer{
+		manager:    manager,
+		bufferName: fmt.Sprintf("xcw-%d", atomic.AddInt64(&writerSeq, 1)),
+	}
+}
+
+// Write implements io.Writer. Complete lines are colorized and queued;
+// the queue is flushed immediately once it reaches writerFlushBytes, and
+// otherwise within writerFlushInterval of the first line landing in an
+// empty queue.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial.WriteString(string(p))
+
+	content := w.partial.String()
+	lines := strings.Split(content, "\n")
+	if !strings.HasSuffix(content, "\n") && len(lines) > 0 {
+		w.partial.Reset()
+		w.partial.WriteString(lines[len(lines)-1])
+		lines = lines[:len(lines)-1]
+	} else {
+		w.partial.Reset()
+	}
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		w.queueLocked(line)
+	}
+
+	// Sticky error, same convention as bufio.Writer: once a background
+	// flush (triggered by the flush timer, not this call) fails, this and
+	// every subsequent Write still buffers its data (nothing written
+	// through Write is ever silently dropped) but reports that error to
+	// the caller until it's been surfaced once, so the caller notices and
+	// can stop instead of assuming the pane is still accepting pastes.
+	if w.lastErr != nil {
+		err, w.lastErr = w.lastErr, nil
+		return len(p), err
+	}
+
+	if w.pending.Len() >= writerFlushBytes {
+		if ferr := w.flushLocked(); ferr != nil {
+			return len(p), ferr
+		}
+	}
+
+	return len(p), nil
+}
+
+// queueLocked appends line's colorized form to pending, arming the flush
+// timer if this is the first line queued since the last flush. w.mu must
+// be held.
+func (w *Writer) queueLocked(line string) {
+	if w.pending.Len() == 0 && w.timer == nil {
+		w.timer = time.AfterFunc(writerFlushInterval, w.timerFlush)
+	}
+	w.pending.WriteString(colorizeLine(line))
+	w.pending.WriteByte('\n')
+}
+
+// timerFlush is writerFlushInterval's AfterFunc callback. A flush error
+// here can't be returned to whatever caller's Write triggered the
+// timer - it was probably a different caller entirely by the time the
+// timer fires - so it's surfaced on the next Write/Flush call instead.
+func (w *Writer) timerFlush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.flushLocked(); err != nil {
+		w.lastErr = err
+	}
+}
+
+// flushLocked hands whatever's in pending to the pane in one PasteRaw
+// call and resets pending. w.mu must be held.
+func (w *Writer) flushLocked() error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if w.pending.Len() == 0 {
+		return nil
+	}
+	data := []byte(w.pending.String())
+	w.pending.Reset()
+	return w.manager.PasteRaw(w.bufferName, data)
+}
+
+// Flush writes any remaining buffered content - both a still-pending
+// batch and a trailing line with no '\n' yet - to the pane.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.partial.Len() > 0 {
+		w.queueLocked(w.partial.String())
+		w.partial.Reset()
+	}
+	err := w.flushLocked()
+	if err == nil && w.lastErr != nil {
+		err, w.lastErr = w.lastErr, nil
+	}
+	return err
+}
+
+// Ensure Writer implements io.Writer
+var _ io.Writer = (*Writer)(nil)
+
+// ndjsonEnvelope is the handful of fields colorizeLine inspects, deliberately
+// narrower than domain.LogEntry/SessionStart/etc so Writer doesn't need to
+// import (or stay in lockstep with) every NDJSON event shape xcw emits.
+type ndjsonEnvelope struct {
+	Type  string `json:"type"`
+	Level string `json:"level"`
+}
+
+// colorizeLine wraps line in SGR codes based on the NDJSON envelope type
+// it looks like: session_start gets a cyan banner, session_end/
+// session_heartbeat are dimmed/grayed out so they recede behind actual
+// log lines, and error/fault levels are red/bold-red so they're visible
+// at a glance in a scrolling pane. A line that doesn't parse as the
+// envelope shape (plain text output, or a future event type) passes
+// through unchanged.
+func colorizeLine(line string) string {
+	var env ndjsonEnvelope
+	if json.Unmarshal([]byte(line), &env) != nil {
+		return line
+	}
+
+	switch env.Type {
+	case "session_start":
+		return ansiSessionStart + line + ansiReset
+	case "session_end":
+		return ansiSessionEnd + line + ansiReset
+	case "heartbeat", "session_heartbeat":
+		return ansiHeartbeat + line + ansiReset
+	}
+
+	switch strings.ToLower(env.Level) {
+	case "error":
+		return ansiLevelError + line + ansiReset
+	case "fault":
+		return ansiLevelFault + line + ansiReset
+	}
+
+	return line
+}
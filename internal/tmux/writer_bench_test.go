@@ -0,0 +1,86 @@
+package tmux
+
+import (
+	"fmt"
+	"testing"
+)
+
+// noopPasteSink discards data, isolating the benchmark to Writer's own
+// batching/colorizing overhead rather than any real tmux round trip.
+type noopPasteSink struct{}
+
+func (noopPasteSink) PasteRaw(string, []byte) error { return nil }
+
+// BenchmarkWriterWrite demonstrates that batching NDJSON lines through
+// PasteRaw keeps up well past the 10k lines/sec the old one-send-keys-
+// per-line WriteLine struggled to sustain (each send-keys call round-trips
+// through a tmux client process).
+func BenchmarkWriterWrite(b *testing.B) {
+	w := newTestWriter(noopPasteSink{})
+	line := []byte(`{"type":"log","level":"info","message":"connection established"}` + "\n")
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(line)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := w.Write(line); err != nil {
+			b.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		b.Fatalf("Flush: %v", err)
+	}
+
+	linesPerSec := float64(b.N) / b.Elapsed().Seconds()
+	if b.Elapsed().Seconds() > 0 && linesPerSec < 10000 {
+		b.Logf("warning: throughput %.0f lines/sec fell below the 10k/sec target", linesPerSec)
+	}
+	b.ReportMetric(linesPerSec, "lines/sec")
+}
+
+// BenchmarkColorizeLine isolates the per-line JSON-sniff cost that runs
+// once per complete line regardless of batch size.
+func BenchmarkColorizeLine(b *testing.B) {
+	lines := []string{
+		`{"type":"session_start","app":"com.example.app"}`,
+		`{"type":"log","level":"error","message":"boom"}`,
+		`{"type":"log","level":"info","message":"fine"}`,
+		"plain text passthrough line",
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		colorizeLine(lines[i%len(lines)])
+	}
+}
+
+// BenchmarkWriterWriteBurst models xcw's real workload: bursts of many
+// lines arriving in a single Write call (a batched log flush) rather than
+// one Write per line, which is the shape that most benefits from
+// PasteRaw's load-buffer/paste-buffer batching over per-line send-keys.
+func BenchmarkWriterWriteBurst(b *testing.B) {
+	const burstLines = 500
+	var burst []byte
+	for i := 0; i < burstLines; i++ {
+		burst = append(burst, []byte(fmt.Sprintf(`{"type":"log","level":"info","message":"line %d"}`, i)+"\n")...)
+	}
+
+	w := newTestWriter(noopPasteSink{})
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := w.Write(burst); err != nil {
+			b.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		b.Fatalf("Flush: %v", err)
+	}
+
+	totalLines := int64(b.N) * burstLines
+	linesPerSec := float64(totalLines) / b.Elapsed().Seconds()
+	b.ReportMetric(linesPerSec, "lines/sec")
+}
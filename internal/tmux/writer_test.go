@@ -0,0 +1,145 @@
+package tmux
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePasteSink records every PasteRaw call instead of touching tmux, so
+// Writer's batching/colorizing logic is testable (and benchmarkable)
+// without a real pane.
+type fakePasteSink struct {
+	mu    sync.Mutex
+	calls [][]byte
+}
+
+func (f *fakePasteSink) PasteRaw(_ string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cp := make([]byte, len(data))
+	copy(cp, data)
+	f.calls = append(f.calls, cp)
+	return nil
+}
+
+func (f *fakePasteSink) combined() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var b strings.Builder
+	for _, c := range f.calls {
+		b.Write(c)
+	}
+	return b.String()
+}
+
+func newTestWriter(sink pasteSink) *Writer {
+	return &Writer{manager: sink, bufferName: "xcw-test"}
+}
+
+func TestColorizeLine(t *testing.T) {
+	cases := []struct {
+		line string
+		want string
+	}{
+		{`{"type":"session_start","app":"x"}`, ansiSessionStart + `{"type":"session_start","app":"x"}` + ansiReset},
+		{`{"type":"session_end"}`, ansiSessionEnd + `{"type":"session_end"}` + ansiReset},
+		{`{"type":"heartbeat"}`, ansiHeartbeat + `{"type":"heartbeat"}` + ansiReset},
+		{`{"type":"session_heartbeat"}`, ansiHeartbeat + `{"type":"session_heartbeat"}` + ansiReset},
+		{`{"type":"log","level":"error"}`, ansiLevelError + `{"type":"log","level":"error"}` + ansiReset},
+		{`{"type":"log","level":"Fault"}`, ansiLevelFault + `{"type":"log","level":"Fault"}` + ansiReset},
+		{`{"type":"log","level":"info"}`, `{"type":"log","level":"info"}`},
+		{"not json at all", "not json at all"},
+	}
+	for _, c := range cases {
+		if got := colorizeLine(c.line); got != c.want {
+			t.Errorf("colorizeLine(%q) = %q, want %q", c.line, got, c.want)
+		}
+	}
+}
+
+func TestWriterBatchesCompleteLines(t *testing.T) {
+	sink := &fakePasteSink{}
+	w := newTestWriter(sink)
+
+	if _, err := w.Write([]byte("line one\nline two\nline th")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := w.Write([]byte("ree\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	got := sink.combined()
+	for _, want := range []string{"line one\n", "line two\n", "line three\n"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("combined output %q missing %q", got, want)
+		}
+	}
+}
+
+func TestWriterFlushEmitsTrailingPartialLine(t *testing.T) {
+	sink := &fakePasteSink{}
+	w := newTestWriter(sink)
+
+	if _, err := w.Write([]byte("no newline yet")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(sink.calls) != 0 {
+		t.Fatalf("expected no flush before Flush(), got %d calls", len(sink.calls))
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got := sink.combined(); !strings.Contains(got, "no newline yet") {
+		t.Fatalf("expected trailing partial line to be flushed, got %q", got)
+	}
+}
+
+func TestWriterFlushesAtByteThreshold(t *testing.T) {
+	sink := &fakePasteSink{}
+	w := newTestWriter(sink)
+
+	line := strings.Repeat("x", 100) + "\n"
+	for i := 0; i < (writerFlushBytes/len(line))+2; i++ {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	if len(sink.calls) == 0 {
+		t.Fatal("expected at least one flush once writerFlushBytes was exceeded, got none")
+	}
+}
+
+func TestWriterSurfacesBackgroundFlushError(t *testing.T) {
+	sink := &fakePasteSink{}
+	w := newTestWriter(sink)
+
+	w.mu.Lock()
+	w.lastErr = fmt.Errorf("boom")
+	w.mu.Unlock()
+
+	_, err := w.Write([]byte("x\n"))
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("expected sticky error 'boom', got %v", err)
+	}
+
+	// The sticky error is consumed, not permanent - the next Write succeeds.
+	if _, err := w.Write([]byte("y\n")); err != nil {
+		t.Fatalf("expected sticky error to clear after being returned once, got %v", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	// The line from the Write that returned the stale error must still
+	// have been buffered and delivered, not dropped.
+	if got := sink.combined(); !strings.Contains(got, "x\n") || !strings.Contains(got, "y\n") {
+		t.Fatalf("expected both lines to survive the sticky-error Write, got %q", got)
+	}
+}
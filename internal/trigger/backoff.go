@@ -0,0 +1,31 @@
+package trigger
+
+import (
+	"math/rand"
+	"time"
+)
+
+// backoffBase/backoffMax/backoffMaxAttempts bound retry delivery of a
+// single event to a single sink: a sink that's down shouldn't be hammered,
+// but it also shouldn't hold up the rest of the queue forever.
+const (
+	backoffBase        = 500 * time.Millisecond
+	backoffMax         = 30 * time.Second
+	backoffMaxAttempts = 5
+)
+
+// backoffDelay returns the delay before retry attempt n (1-indexed),
+// doubling backoffBase each attempt up to backoffMax and adding up to 20%
+// jitter so many sinks failing at once don't retry in lockstep.
+func backoffDelay(n int) time.Duration {
+	d := backoffBase
+	for i := 1; i < n; i++ {
+		d *= 2
+		if d >= backoffMax {
+			d = backoffMax
+			break
+		}
+	}
+	jitter := time.Duration(rand.Int63n(int64(d) / 5))
+	return d + jitter
+}
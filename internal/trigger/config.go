@@ -0,0 +1,202 @@
+package trigger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultFileSinkMaxBytes/defaultFileSinkKeep match --log-file's own
+// defaults (50MB, keep 5) so a FileSink configured with nothing but a path
+// behaves the way --log-file already does.
+const (
+	defaultFileSinkMaxBytes = 50 * 1024 * 1024
+	defaultFileSinkKeep     = 5
+)
+
+// ParseSinkSpec builds a Sink from a repeatable `--sink kind:target` flag,
+// e.g. "webhook:https://example.com/hook", "syslog:udp://host:514",
+// "file:/var/log/xcw-triggers.ndjson", or "exec:notify.sh". For richer
+// per-sink settings (HMAC secrets, extra headers, syslog facility, file
+// rotation size) use --sinks-config instead.
+func ParseSinkSpec(spec string) (Sink, error) {
+	kind, target, ok := strings.Cut(spec, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid --sink %q, expected kind:target", spec)
+	}
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, fmt.Errorf("invalid --sink %q: missing target", spec)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "webhook":
+		return NewWebhookSink(target), nil
+	case "syslog":
+		network, addr, ok := strings.Cut(target, "://")
+		if !ok {
+			return nil, fmt.Errorf("invalid --sink syslog target %q, expected network://host:port", target)
+		}
+		return NewSyslogSink(network, addr, 0), nil
+	case "file":
+		return NewFileSink(target, defaultFileSinkMaxBytes, defaultFileSinkKeep)
+	case "exec":
+		return NewExecSink(target, nil), nil
+	default:
+		return nil, fmt.Errorf("invalid --sink %q: unknown kind %q (want webhook, syslog, file, or exec)", spec, kind)
+	}
+}
+
+// sinksFile is the shape of a --sinks-config YAML file, a list of sinks
+// plus, per kind, the settings ParseSinkSpec's plain kind:target syntax
+// can't express.
+type sinksFile struct {
+	Sinks []sinkConfig `yaml:"sinks"`
+}
+
+type sinkConfig struct {
+	Kind string `yaml:"kind"`
+
+	// webhook
+	URL     string            `yaml:"url"`
+	Secret  string            `yaml:"secret"`
+	Headers map[string]string `yaml:"headers"`
+	Timeout string            `yaml:"timeout"`
+
+	// syslog
+	Network  string `yaml:"network"`
+	Address  string `yaml:"address"`
+	Facility int    `yaml:"facility"`
+
+	// file
+	Path    string `yaml:"path"`
+	MaxSize string `yaml:"max_size"`
+	Keep    int    `yaml:"keep"`
+
+	// exec
+	Command string `yaml:"command"`
+}
+
+// LoadSinksConfig reads a --sinks-config YAML file and builds its sinks.
+func LoadSinksConfig(path string) ([]Sink, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading sinks config %s: %w", path, err)
+	}
+
+	var file sinksFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing sinks config %s: %w", path, err)
+	}
+
+	sinks := make([]Sink, 0, len(file.Sinks))
+	for i, sc := range file.Sinks {
+		sink, err := sc.build()
+		if err != nil {
+			return nil, fmt.Errorf("sinks config %s, entry %d: %w", path, i, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+func (sc sinkConfig) build() (Sink, error) {
+	switch strings.ToLower(strings.TrimSpace(sc.Kind)) {
+	case "webhook":
+		if sc.URL == "" {
+			return nil, fmt.Errorf("webhook sink requires url")
+		}
+		var opts []WebhookOption
+		if sc.Secret != "" {
+			opts = append(opts, WithWebhookSecret(sc.Secret))
+		}
+		if len(sc.Headers) > 0 {
+			opts = append(opts, WithWebhookHeaders(sc.Headers))
+		}
+		if sc.Timeout != "" {
+			d, err := time.ParseDuration(sc.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout %q: %w", sc.Timeout, err)
+			}
+			opts = append(opts, WithWebhookTimeout(d))
+		}
+		return NewWebhookSink(sc.URL, opts...), nil
+
+	case "syslog":
+		if sc.Network == "" || sc.Address == "" {
+			return nil, fmt.Errorf("syslog sink requires network and address")
+		}
+		return NewSyslogSink(sc.Network, sc.Address, sc.Facility), nil
+
+	case "file":
+		if sc.Path == "" {
+			return nil, fmt.Errorf("file sink requires path")
+		}
+		maxBytes := int64(defaultFileSinkMaxBytes)
+		if sc.MaxSize != "" {
+			n, err := parseByteSize(sc.MaxSize)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max_size %q: %w", sc.MaxSize, err)
+			}
+			maxBytes = n
+		}
+		keep := defaultFileSinkKeep
+		if sc.Keep != 0 {
+			keep = sc.Keep
+		}
+		return NewFileSink(sc.Path, maxBytes, keep)
+
+	case "exec":
+		if sc.Command == "" {
+			return nil, fmt.Errorf("exec sink requires command")
+		}
+		return NewExecSink(sc.Command, nil), nil
+
+	default:
+		return nil, fmt.Errorf("unknown sink kind %q (want webhook, syslog, file, or exec)", sc.Kind)
+	}
+}
+
+// parseByteSize parses a human-friendly size string (e.g. "10MB", "1GB")
+// into a byte count. It takes the GB/MB/KB suffixes --log-rotate-size
+// takes, but a bare number is a byte count and the single-letter G/M/K
+// forms are not accepted.
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	upper := strings.ToUpper(s)
+	var unit string
+	var numPart string
+	switch {
+	case strings.HasSuffix(upper, "GB"):
+		unit, numPart = "GB", s[:len(s)-2]
+	case strings.HasSuffix(upper, "MB"):
+		unit, numPart = "MB", s[:len(s)-2]
+	case strings.HasSuffix(upper, "KB"):
+		unit, numPart = "KB", s[:len(s)-2]
+	default:
+		unit, numPart = "B", s
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(numPart), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("invalid size %q: must not be negative", s)
+	}
+
+	switch unit {
+	case "GB":
+		return n * 1024 * 1024 * 1024, nil
+	case "MB":
+		return n * 1024 * 1024, nil
+	case "KB":
+		return n * 1024, nil
+	default:
+		return n, nil
+	}
+}
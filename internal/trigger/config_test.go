@@ -0,0 +1,34 @@
+package trigger
+
+import "testing"
+
+func TestParseSinkSpec(t *testing.T) {
+	cases := []struct {
+		spec     string
+		wantName string
+		wantErr  bool
+	}{
+		{spec: "webhook:https://example.com/hook", wantName: "webhook:https://example.com/hook"},
+		{spec: "syslog:udp://localhost:514", wantName: "syslog:udp://localhost:514"},
+		{spec: "exec:notify.sh", wantName: "exec:notify.sh"},
+		{spec: "bogus", wantErr: true},
+		{spec: "syslog:not-a-url", wantErr: true},
+		{spec: "made-up:target", wantErr: true},
+	}
+
+	for _, c := range cases {
+		sink, err := ParseSinkSpec(c.spec)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("ParseSinkSpec(%q): expected error, got nil", c.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseSinkSpec(%q): unexpected error: %v", c.spec, err)
+		}
+		if sink.Name() != c.wantName {
+			t.Errorf("ParseSinkSpec(%q).Name() = %q, want %q", c.spec, sink.Name(), c.wantName)
+		}
+	}
+}
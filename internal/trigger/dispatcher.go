@@ -0,0 +1,200 @@
+package trigger
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// defaultQueueSize bounds how many undelivered events pile up per sink
+// before Dispatcher starts dropping the oldest one to make room, so a
+// slow or unreachable webhook/syslog endpoint can't back up the log
+// processing goroutine feeding Fire.
+const defaultQueueSize = 64
+
+// Dispatcher owns the cooldown, bounded queue, and retry/backoff machinery
+// shared by every trigger kind `xcw watch` supports (--on-error, --on-fault,
+// --on-pattern): WatchCmd.Run no longer tracks per-kind lastTrigger times or
+// runs sinks inline, it just calls Fire once per matched log entry.
+type Dispatcher struct {
+	ctx            context.Context
+	cooldown       time.Duration
+	queueSize      int
+	onDropped      func(sinkName string)
+	onDeliverError func(sinkName string, err error)
+
+	mu      sync.Mutex
+	last    map[string]time.Time
+	byKind  map[string][]*sinkWorker
+	global  []*sinkWorker
+	all     []*sinkWorker
+	closers []io.Closer
+}
+
+// NewDispatcher creates a Dispatcher that enforces cooldown between fires of
+// the same kind and delivers through sinks registered via Register. Workers
+// run until ctx is done or Close is called. onDropped is called whenever a
+// sink's queue was full and its oldest event was discarded; onDeliverError
+// is called when a sink exhausts its retry budget for one event.
+func NewDispatcher(ctx context.Context, cooldown time.Duration, onDropped func(sinkName string), onDeliverError func(sinkName string, err error)) *Dispatcher {
+	return &Dispatcher{
+		ctx:            ctx,
+		cooldown:       cooldown,
+		queueSize:      defaultQueueSize,
+		onDropped:      onDropped,
+		onDeliverError: onDeliverError,
+		last:           make(map[string]time.Time),
+		byKind:         make(map[string][]*sinkWorker),
+	}
+}
+
+// Register adds sink to the dispatcher and starts its delivery worker. With
+// no kinds given, sink receives every fired event (the role --sink/
+// --sinks-config destinations play); with kinds given, sink only receives
+// events fired under one of those exact kind strings (e.g. "error",
+// "fault", or "pattern:<regex>" - the role a --on-error/--on-fault/
+// --on-pattern exec command plays).
+func (d *Dispatcher) Register(sink Sink, kinds ...string) {
+	w := newSinkWorker(sink, d.queueSize, d.onDropped, d.onDeliverError)
+	d.all = append(d.all, w)
+	go w.run(d.ctx)
+	if closer, ok := sink.(io.Closer); ok {
+		d.closers = append(d.closers, closer)
+	}
+
+	if len(kinds) == 0 {
+		d.global = append(d.global, w)
+		return
+	}
+	for _, k := range kinds {
+		d.byKind[k] = append(d.byKind[k], w)
+	}
+}
+
+// Fire reports whether kind is past its cooldown and, if so, enqueues event
+// for delivery to every sink registered for kind plus every globally
+// registered sink. It returns false (and enqueues nothing) while kind is
+// still cooling down from its last fire.
+func (d *Dispatcher) Fire(kind string, event Event) bool {
+	d.mu.Lock()
+	if last, ok := d.last[kind]; ok && time.Since(last) < d.cooldown {
+		d.mu.Unlock()
+		return false
+	}
+	d.last[kind] = time.Now()
+	d.mu.Unlock()
+
+	for _, w := range d.byKind[kind] {
+		w.enqueue(event)
+	}
+	for _, w := range d.global {
+		w.enqueue(event)
+	}
+	return true
+}
+
+// Close stops every sink worker - it does not wait for in-flight retries to
+// drain - then closes every sink that holds an open resource (e.g.
+// FileSink's underlying file).
+func (d *Dispatcher) Close() {
+	for _, w := range d.all {
+		w.close()
+	}
+	for _, c := range d.closers {
+		_ = c.Close()
+	}
+}
+
+// sinkWorker delivers events to a single Sink off a bounded, drop-oldest
+// queue, one at a time, retrying failed deliveries with backoff before
+// moving on to the next queued event.
+type sinkWorker struct {
+	sink           Sink
+	queueSize      int
+	onDropped      func(sinkName string)
+	onDeliverError func(sinkName string, err error)
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  []Event
+	closed bool
+}
+
+func newSinkWorker(sink Sink, queueSize int, onDropped func(string), onDeliverError func(string, error)) *sinkWorker {
+	w := &sinkWorker{sink: sink, queueSize: queueSize, onDropped: onDropped, onDeliverError: onDeliverError}
+	w.cond = sync.NewCond(&w.mu)
+	return w
+}
+
+// enqueue appends event to the queue, dropping the oldest queued event
+// first if the queue is already at capacity.
+func (w *sinkWorker) enqueue(event Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	if len(w.items) >= w.queueSize {
+		w.items = w.items[1:]
+		if w.onDropped != nil {
+			w.onDropped(w.sink.Name())
+		}
+	}
+	w.items = append(w.items, event)
+	w.cond.Signal()
+}
+
+// run delivers queued events one at a time until the queue is both closed
+// and empty.
+func (w *sinkWorker) run(ctx context.Context) {
+	for {
+		w.mu.Lock()
+		for len(w.items) == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if len(w.items) == 0 && w.closed {
+			w.mu.Unlock()
+			return
+		}
+		event := w.items[0]
+		w.items = w.items[1:]
+		w.mu.Unlock()
+
+		w.deliver(ctx, event)
+	}
+}
+
+// deliver retries sink.Deliver with exponential backoff and jitter,
+// reporting to onDeliverError only once the retry budget is exhausted.
+func (w *sinkWorker) deliver(ctx context.Context, event Event) {
+	var err error
+	for attempt := 1; attempt <= backoffMaxAttempts; attempt++ {
+		err = w.sink.Deliver(ctx, event)
+		if err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if attempt < backoffMaxAttempts {
+			select {
+			case <-time.After(backoffDelay(attempt)):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+	if w.onDeliverError != nil {
+		w.onDeliverError(w.sink.Name(), err)
+	}
+}
+
+// close marks the queue closed so run exits once whatever's already queued
+// has drained.
+func (w *sinkWorker) close() {
+	w.mu.Lock()
+	w.closed = true
+	w.cond.Broadcast()
+	w.mu.Unlock()
+}
@@ -0,0 +1,105 @@
+package trigger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink counts deliveries and can be made to always fail.
+type recordingSink struct {
+	name string
+	fail bool
+
+	mu    sync.Mutex
+	count int
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Deliver(context.Context, Event) error {
+	s.mu.Lock()
+	s.count++
+	s.mu.Unlock()
+	if s.fail {
+		return errDelivery
+	}
+	return nil
+}
+
+func (s *recordingSink) deliveries() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+var errDelivery = errors.New("delivery failed")
+
+func TestDispatcherFireRespectsCooldown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := NewDispatcher(ctx, time.Hour, nil, nil)
+	sink := &recordingSink{name: "test"}
+	d.Register(sink, "error")
+	defer d.Close()
+
+	if !d.Fire("error", Event{}) {
+		t.Fatal("expected first fire to succeed")
+	}
+	if d.Fire("error", Event{}) {
+		t.Fatal("expected second fire to be suppressed by cooldown")
+	}
+}
+
+func TestDispatcherFireScopesByKind(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := NewDispatcher(ctx, 0, nil, nil)
+	errorSink := &recordingSink{name: "error-sink"}
+	globalSink := &recordingSink{name: "global-sink"}
+	d.Register(errorSink, "error")
+	d.Register(globalSink)
+	defer d.Close()
+
+	d.Fire("fault", Event{})
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) && globalSink.deliveries() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if errorSink.deliveries() != 0 {
+		t.Fatalf("expected kind-scoped sink to receive 0 events, got %d", errorSink.deliveries())
+	}
+	if globalSink.deliveries() != 1 {
+		t.Fatalf("expected global sink to receive 1 event, got %d", globalSink.deliveries())
+	}
+}
+
+func TestSinkWorkerEnqueueDropsOldestWhenSaturated(t *testing.T) {
+	var dropped []string
+	var mu sync.Mutex
+
+	w := newSinkWorker(&recordingSink{name: "slow"}, 2, func(name string) {
+		mu.Lock()
+		dropped = append(dropped, name)
+		mu.Unlock()
+	}, nil)
+
+	w.enqueue(Event{Message: "1"})
+	w.enqueue(Event{Message: "2"})
+	w.enqueue(Event{Message: "3"})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dropped) != 1 {
+		t.Fatalf("expected 1 drop notification, got %d", len(dropped))
+	}
+	if len(w.items) != 2 || w.items[0].Message != "2" || w.items[1].Message != "3" {
+		t.Fatalf("expected oldest item dropped, queue = %+v", w.items)
+	}
+}
@@ -0,0 +1,45 @@
+package trigger
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// ExecSink runs command through "sh -c", exactly how `xcw watch` has always
+// run --on-error/--on-fault/--on-pattern commands.
+type ExecSink struct {
+	command string
+	record  func(description, output string)
+}
+
+// NewExecSink wraps command as a Sink. record, if non-nil, is called with
+// the command's combined stdout+stderr so --record can still archive it to
+// triggers/<n>.log; it's nil when --record isn't in use.
+func NewExecSink(command string, record func(description, output string)) *ExecSink {
+	return &ExecSink{command: command, record: record}
+}
+
+func (s *ExecSink) Name() string { return "exec:" + s.command }
+
+// Deliver runs s.command with the event's fields exported as XCW_*
+// environment variables, matching the set `xcw watch` has always provided.
+func (s *ExecSink) Deliver(ctx context.Context, event Event) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.command)
+	cmd.Env = append(os.Environ(),
+		"XCW_TRIGGER="+event.Trigger,
+		"XCW_LEVEL="+event.Level,
+		"XCW_MESSAGE="+event.Message,
+		"XCW_SUBSYSTEM="+event.Subsystem,
+		"XCW_PROCESS="+event.Process,
+		"XCW_TIMESTAMP="+event.Timestamp.Format(time.RFC3339),
+	)
+
+	if s.record != nil {
+		out, err := cmd.CombinedOutput()
+		s.record(event.Trigger+": "+s.command, string(out))
+		return err
+	}
+	return cmd.Run()
+}
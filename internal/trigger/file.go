@@ -0,0 +1,52 @@
+package trigger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vburojevic/xcw/internal/output"
+)
+
+// FileSink appends each event as an NDJSON line to a file, reusing
+// output.RotatingFileSink for the same size-based rotation and pruning
+// behavior --log-file already gives live log output; age-based rotation is
+// not enabled for it.
+type FileSink struct {
+	path string
+	rfs  *output.RotatingFileSink
+}
+
+// NewFileSink opens (creating if necessary) path for appending, rotating it
+// once it exceeds maxBytes and keeping at most keep gzip-compressed
+// segments. maxBytes <= 0 disables size-based rotation; keep <= 0 keeps
+// every segment.
+func NewFileSink(path string, maxBytes int64, keep int) (*FileSink, error) {
+	rfs, err := output.NewRotatingFileSink(path, maxBytes, 0, keep)
+	if err != nil {
+		return nil, fmt.Errorf("opening sink file %s: %w", path, err)
+	}
+	return &FileSink{path: path, rfs: rfs}, nil
+}
+
+func (s *FileSink) Name() string { return "file:" + s.path }
+
+func (s *FileSink) Deliver(_ context.Context, event Event) error {
+	b, err := json.Marshal(webhookPayload{
+		Trigger:   event.Trigger,
+		Level:     event.Level,
+		Message:   event.Message,
+		Subsystem: event.Subsystem,
+		Process:   event.Process,
+		PID:       event.PID,
+		Timestamp: event.Timestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling file sink payload: %w", err)
+	}
+	b = append(b, '\n')
+	return s.rfs.WriteEvent("trigger", b)
+}
+
+// Close flushes and closes the underlying file without rotating it.
+func (s *FileSink) Close() error { return s.rfs.Close() }
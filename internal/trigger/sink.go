@@ -0,0 +1,13 @@
+package trigger
+
+import "context"
+
+// Sink is a single delivery destination for a fired trigger - running a
+// shell command, POSTing a webhook, forwarding to syslog, or appending to a
+// file.
+type Sink interface {
+	// Name identifies the sink in sink_dropped events and debug logging,
+	// e.g. "exec:notify.sh" or "webhook:https://example.com/hook".
+	Name() string
+	Deliver(ctx context.Context, event Event) error
+}
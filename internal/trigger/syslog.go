@@ -0,0 +1,83 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// defaultSyslogFacility is local0, the conventional facility for
+// application-level (rather than OS-level) syslog messages.
+const defaultSyslogFacility = 16
+
+// SyslogSink sends each event as an RFC5424 syslog message over UDP or TCP.
+type SyslogSink struct {
+	network  string // "udp" or "tcp"
+	addr     string // host:port
+	facility int
+	appName  string
+}
+
+// NewSyslogSink creates a SyslogSink writing to addr over network ("udp" or
+// "tcp"). facility defaults to local0 (16) when 0.
+func NewSyslogSink(network, addr string, facility int) *SyslogSink {
+	if facility == 0 {
+		facility = defaultSyslogFacility
+	}
+	return &SyslogSink{network: network, addr: addr, facility: facility, appName: "xcw"}
+}
+
+func (s *SyslogSink) Name() string { return "syslog:" + s.network + "://" + s.addr }
+
+// severityFor maps an xcw log level to an RFC5424 severity (0=emergency,
+// 7=debug); xcw's five levels don't map one-to-one onto syslog's eight, so
+// Fault/Error/Default/Info/Debug land on critical/error/notice/info/debug.
+func severityFor(level string) int {
+	switch level {
+	case "Fault":
+		return 2
+	case "Error":
+		return 3
+	case "Default":
+		return 5
+	case "Info":
+		return 6
+	case "Debug":
+		return 7
+	default:
+		return 5
+	}
+}
+
+// Deliver dials s.addr fresh for every event rather than holding a
+// persistent connection, so a syslog receiver that's down doesn't leave a
+// stale half-open socket between trigger firings.
+func (s *SyslogSink) Deliver(ctx context.Context, event Event) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, s.network, s.addr)
+	if err != nil {
+		return fmt.Errorf("dialing syslog %s %s: %w", s.network, s.addr, err)
+	}
+	defer conn.Close()
+
+	pri := s.facility*8 + severityFor(event.Level)
+	hostname, _ := os.Hostname()
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d %s - %s",
+		pri,
+		event.Timestamp.UTC().Format(time.RFC3339Nano),
+		hostname,
+		s.appName,
+		os.Getpid(),
+		event.Trigger,
+		event.Message,
+	)
+	if s.network != "udp" {
+		msg += "\n"
+	}
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		return fmt.Errorf("writing syslog message to %s: %w", s.addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+// Package trigger implements pluggable delivery destinations ("sinks") for
+// `xcw watch`'s --on-error/--on-fault/--on-pattern triggers, plus the
+// shared cooldown and backoff/queueing machinery those trigger kinds all
+// need, so WatchCmd itself doesn't have to know how any particular sink
+// delivers an event.
+package trigger
+
+import "time"
+
+// Event is the information captured when a watch trigger condition fires -
+// the same fields `xcw watch` has always exported to exec triggers as
+// XCW_* environment variables, plus PID (which ExecSink does not export) -
+// handed to every Sink regardless of kind.
+type Event struct {
+	Trigger   string // e.g. "error", "fault", "pattern:<regex>"
+	Level     string
+	Message   string
+	Subsystem string
+	Process   string
+	PID       int
+	Timestamp time.Time
+}
@@ -0,0 +1,106 @@
+package trigger
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookSink POSTs event as a JSON payload to url, signing the body with
+// HMAC-SHA256 (as "sha256=<hex>" in an X-Xcw-Signature header) when secret is
+// set, so a receiving endpoint can verify the request actually came from
+// this xcw instance.
+type WebhookSink struct {
+	url     string
+	secret  string
+	headers map[string]string
+	timeout time.Duration
+	client  *http.Client
+}
+
+// WebhookOption configures a WebhookSink beyond its required url.
+type WebhookOption func(*WebhookSink)
+
+// WithWebhookSecret signs every request body with HMAC-SHA256 using secret.
+func WithWebhookSecret(secret string) WebhookOption {
+	return func(s *WebhookSink) { s.secret = secret }
+}
+
+// WithWebhookHeaders sets additional headers on every request.
+func WithWebhookHeaders(headers map[string]string) WebhookOption {
+	return func(s *WebhookSink) { s.headers = headers }
+}
+
+// WithWebhookTimeout overrides the default 10s request timeout.
+func WithWebhookTimeout(d time.Duration) WebhookOption {
+	return func(s *WebhookSink) { s.timeout = d }
+}
+
+// NewWebhookSink creates a WebhookSink posting to url.
+func NewWebhookSink(url string, opts ...WebhookOption) *WebhookSink {
+	s := &WebhookSink{url: url, timeout: 10 * time.Second}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.client = &http.Client{Timeout: s.timeout}
+	return s
+}
+
+func (s *WebhookSink) Name() string { return "webhook:" + s.url }
+
+// webhookPayload is the JSON body posted to url - the same fields as
+// Event, just with json tags since Event itself is kept sink-agnostic.
+type webhookPayload struct {
+	Trigger   string    `json:"trigger"`
+	Level     string    `json:"level"`
+	Message   string    `json:"message"`
+	Subsystem string    `json:"subsystem"`
+	Process   string    `json:"process"`
+	PID       int       `json:"pid"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, event Event) error {
+	body, err := json.Marshal(webhookPayload{
+		Trigger:   event.Trigger,
+		Level:     event.Level,
+		Message:   event.Message,
+		Subsystem: event.Subsystem,
+		Process:   event.Process,
+		PID:       event.PID,
+		Timestamp: event.Timestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	if s.secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write(body)
+		req.Header.Set("X-Xcw-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("delivering webhook to %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded %s", s.url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package updater
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// binaryName is what xcw's release workflow names the executable inside
+// the tar.gz AssetName downloads, e.g. "xcw" ("xcw.exe" on Windows).
+func binaryName() string {
+	if runtime.GOOS == "windows" {
+		return "xcw.exe"
+	}
+	return "xcw"
+}
+
+// ExtractBinary reads archivePath as a gzip-compressed tarball (the shape
+// AssetName/Download produce) and writes the xcw binary it contains to
+// destPath with executable permissions. It's a separate step from Download
+// because the downloaded asset is the archive, not the binary Apply
+// expects to rename into place - skipping this step would leave Apply
+// installing a gzip file where the xcw binary used to be.
+func ExtractBinary(archivePath, destPath string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", archivePath, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("opening %s as gzip: %w", archivePath, err)
+	}
+	defer gz.Close()
+
+	want := binaryName()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s: no %s entry found in archive", archivePath, want)
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", archivePath, err)
+		}
+		if hdr.Typeflag != tar.TypeReg || filepath.Base(hdr.Name) != want {
+			continue
+		}
+
+		out, err := os.OpenFile(destPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", destPath, err)
+		}
+		if _, err := io.Copy(out, tr); err != nil {
+			out.Close()
+			return fmt.Errorf("extracting %s to %s: %w", hdr.Name, destPath, err)
+		}
+		if err := out.Close(); err != nil {
+			return fmt.Errorf("closing %s: %w", destPath, err)
+		}
+		if runtime.GOOS != "windows" {
+			if err := os.Chmod(destPath, 0o755); err != nil {
+				return fmt.Errorf("making %s executable: %w", destPath, err)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+package updater
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// buildReleaseArchive returns a real gzip-compressed tarball containing a
+// single entry named binaryName() with contents, matching the shape xcw's
+// release workflow publishes as AssetName(goos, goarch).
+func buildReleaseArchive(t *testing.T, contents []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name: binaryName(),
+		Mode: 0o755,
+		Size: int64(len(contents)),
+	}); err != nil {
+		t.Fatalf("writing tar header: %v", err)
+	}
+	if _, err := tw.Write(contents); err != nil {
+		t.Fatalf("writing tar entry: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar writer: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("closing gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDownloadExtractAndApplyInstallsExecutableBinary(t *testing.T) {
+	const contents = "#!/bin/sh\necho pretend xcw binary\n"
+	archive := buildReleaseArchive(t, []byte(contents))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "xcw_download.tar.gz")
+	if err := Download(context.Background(), srv.URL, archivePath, nil); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+
+	extractedPath := filepath.Join(dir, "xcw.new")
+	if err := ExtractBinary(archivePath, extractedPath); err != nil {
+		t.Fatalf("ExtractBinary: %v", err)
+	}
+
+	got, err := os.ReadFile(extractedPath)
+	if err != nil {
+		t.Fatalf("ReadFile extracted binary: %v", err)
+	}
+	if string(got) != contents {
+		t.Fatalf("extracted contents = %q, want %q", got, contents)
+	}
+
+	current := filepath.Join(dir, "xcw")
+	if err := os.WriteFile(current, []byte("old binary"), 0o755); err != nil {
+		t.Fatalf("seeding current binary: %v", err)
+	}
+	if _, err := Apply(extractedPath, current); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	info, err := os.Stat(current)
+	if err != nil {
+		t.Fatalf("Stat installed binary: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+		t.Fatalf("installed binary is not executable: mode %v", info.Mode())
+	}
+}
+
+func TestExtractBinaryErrorsWhenEntryMissing(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	contents := []byte("unrelated file")
+	if err := tw.WriteHeader(&tar.Header{Name: "README.md", Mode: 0o644, Size: int64(len(contents))}); err != nil {
+		t.Fatalf("writing tar header: %v", err)
+	}
+	if _, err := tw.Write(contents); err != nil {
+		t.Fatalf("writing tar entry: %v", err)
+	}
+	tw.Close()
+	gz.Close()
+
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "bad.tar.gz")
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("writing archive: %v", err)
+	}
+
+	if err := ExtractBinary(archivePath, filepath.Join(dir, "xcw.new")); err == nil {
+		t.Fatal("expected an error when the archive has no xcw entry")
+	}
+}
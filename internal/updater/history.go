@@ -0,0 +1,62 @@
+package updater
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HistoryEntry records one self-update so a later `xcw update --rollback`
+// knows what version was replaced and where Apply left its backup.
+type HistoryEntry struct {
+	PreviousVersion string    `json:"previous_version"`
+	NewVersion      string    `json:"new_version"`
+	RollbackPath    string    `json:"rollback_path"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// History is the ~/.xcw/updates.json shape: just the most recent update,
+// since Apply only ever keeps one ".old" backup around.
+type History struct {
+	Last *HistoryEntry `json:"last,omitempty"`
+}
+
+// DefaultHistoryPath returns ~/.xcw/updates.json.
+func DefaultHistoryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".xcw", "updates.json"), nil
+}
+
+// LoadHistory reads path, returning an empty History if it doesn't exist
+// yet - there's nothing to roll back to before the first self-update.
+func LoadHistory(path string) (*History, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &History{}, nil
+		}
+		return nil, err
+	}
+	var h History
+	if err := json.Unmarshal(b, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// SaveHistory writes h to path, creating its parent directory if needed.
+func SaveHistory(path string, h *History) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	return os.WriteFile(path, b, 0o600)
+}
@@ -0,0 +1,293 @@
+// Package updater implements `xcw update --apply`'s self-update: querying
+// GitHub Releases for vburojevic/xcw, picking the asset matching this
+// binary's OS/arch, verifying it against the release's published sha256
+// sidecar, and swapping it in for the running executable without
+// clobbering an in-flight `xcw tail`/`watch` process reading the old
+// inode.
+package updater
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Repo is the GitHub repository this binary updates itself from.
+const Repo = "vburojevic/xcw"
+
+// Channel selects which releases FetchRelease considers.
+type Channel string
+
+const (
+	ChannelStable     Channel = "stable"
+	ChannelPrerelease Channel = "prerelease"
+)
+
+// Asset is one file attached to a GitHub release.
+type Asset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
+}
+
+// Release is the subset of GitHub's release API this package reads.
+type Release struct {
+	TagName    string  `json:"tag_name"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Version strips a release's leading "v" so it can be compared against
+// the xcw.Version this binary was built with (e.g. "v1.2.0" -> "1.2.0").
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// httpClient is a package variable so tests can point it at a local
+// httptest.Server without this package taking a dependency on a
+// pluggable-transport abstraction it doesn't otherwise need.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// apiBaseURL is overridden in tests; in production it's GitHub's API.
+var apiBaseURL = "https://api.github.com"
+
+// FetchRelease returns the newest release on channel for Repo. The stable
+// channel uses GitHub's "latest" alias (which already excludes
+// prereleases and drafts); the prerelease channel lists all releases and
+// returns the first one, since "latest" ignores prereleases entirely.
+func FetchRelease(ctx context.Context, channel Channel) (*Release, error) {
+	if channel == ChannelPrerelease {
+		releases, err := fetchReleaseList(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(releases) == 0 {
+			return nil, errors.New("no releases found")
+		}
+		return &releases[0], nil
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", apiBaseURL, Repo)
+	var rel Release
+	if err := getJSON(ctx, url, &rel); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+func fetchReleaseList(ctx context.Context) ([]Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases", apiBaseURL, Repo)
+	var releases []Release
+	if err := getJSON(ctx, url, &releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+func getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("building request for %s: %w", url, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: unexpected status %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// AssetName is the filename xcw's release workflow publishes for goos/
+// goarch, e.g. "xcw_darwin_arm64.tar.gz".
+func AssetName(goos, goarch string) string {
+	return fmt.Sprintf("xcw_%s_%s.tar.gz", goos, goarch)
+}
+
+// SelectAsset finds the release asset matching goos/goarch (runtime.GOOS/
+// runtime.GOARCH in production), or an error naming what was being looked
+// for if the release doesn't publish one.
+func SelectAsset(rel *Release, goos, goarch string) (*Asset, error) {
+	name := AssetName(goos, goarch)
+	for i := range rel.Assets {
+		if rel.Assets[i].Name == name {
+			return &rel.Assets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("release %s has no asset named %s", rel.TagName, name)
+}
+
+// ChecksumAssetName is the sha256 sidecar GitHub Actions publishes
+// alongside asset, e.g. "xcw_darwin_arm64.tar.gz.sha256".
+func ChecksumAssetName(asset string) string {
+	return asset + ".sha256"
+}
+
+// ProgressFunc is called as Download reads bytes, so a caller can surface
+// download progress (e.g. as NDJSON update_progress events).
+type ProgressFunc func(bytesRead int64)
+
+// Download streams url to a new file at destPath, calling onProgress (if
+// non-nil) as bytes arrive. It does not verify the result - callers pair
+// it with VerifyChecksum.
+func Download(ctx context.Context, url, destPath string, onProgress ProgressFunc) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("building request for %s: %w", url, err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading %s: unexpected status %s", url, resp.Status)
+	}
+
+	f, err := os.OpenFile(destPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", destPath, err)
+	}
+	defer f.Close()
+
+	var written int64
+	buf := make([]byte, 32*1024)
+	for {
+		n, rerr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, werr := f.Write(buf[:n]); werr != nil {
+				return fmt.Errorf("writing %s: %w", destPath, werr)
+			}
+			written += int64(n)
+			if onProgress != nil {
+				onProgress(written)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return fmt.Errorf("downloading %s: %w", url, rerr)
+		}
+	}
+	return nil
+}
+
+// FetchChecksum downloads url (a ".sha256" sidecar) and returns the hex
+// digest it contains, tolerating both the bare-hex form and the
+// "<hex>  <filename>" form `shasum`/`sha256sum` emit.
+func FetchChecksum(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("building request for %s: %w", url, err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching %s: unexpected status %s", url, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", url, err)
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("%s is empty", url)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// VerifyChecksum reports an error unless path's sha256 digest matches
+// wantHex (case-insensitive).
+func VerifyChecksum(path, wantHex string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("hashing %s: %w", path, err)
+	}
+	got := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(got, wantHex) {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", path, got, wantHex)
+	}
+	return nil
+}
+
+// IsHomebrewInstall reports whether execPath looks like it lives under a
+// Homebrew Cellar (including the Cellar symlink farms under /opt/homebrew
+// and /usr/local), where xcw's binary is owned and managed by `brew` and
+// silently overwriting it would leave brew's own bookkeeping (and its
+// next `brew upgrade`) out of sync with what's actually on disk.
+func IsHomebrewInstall(execPath string) bool {
+	resolved, err := filepath.EvalSymlinks(execPath)
+	if err != nil {
+		resolved = execPath
+	}
+	for _, p := range []string{execPath, resolved} {
+		if strings.Contains(p, "/Cellar/") || strings.Contains(p, "/homebrew/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply atomically replaces currentPath with newPath: currentPath is
+// first renamed to currentPath+".old" (never deleted outright, so Apply
+// can be undone even if the rename of newPath fails), then newPath is
+// renamed into currentPath's place. Both renames are same-filesystem (the
+// new binary lives alongside currentPath, written there by ExtractBinary) so
+// neither step can be interrupted mid-copy; a process already holding
+// currentPath open (an in-flight `tail`) keeps reading its old inode via
+// the ".old" name until it exits.
+func Apply(newPath, currentPath string) (oldPath string, err error) {
+	oldPath = currentPath + ".old"
+	os.Remove(oldPath) // best effort; a stale .old from a prior update is harmless to replace
+
+	if err := os.Rename(currentPath, oldPath); err != nil {
+		return "", fmt.Errorf("renaming current binary aside to %s: %w", oldPath, err)
+	}
+	if err := os.Rename(newPath, currentPath); err != nil {
+		// Best effort to restore the binary we just moved aside - a
+		// running xcw should never end this dance with nothing at
+		// currentPath.
+		if rerr := os.Rename(oldPath, currentPath); rerr != nil {
+			return "", fmt.Errorf("installing new binary: %v (and restoring original failed: %v)", err, rerr)
+		}
+		return "", fmt.Errorf("installing new binary over %s: %w", currentPath, err)
+	}
+	if runtime.GOOS != "windows" {
+		_ = os.Chmod(currentPath, 0o755)
+	}
+	return oldPath, nil
+}
+
+// Rollback restores currentPath+".old" over currentPath, undoing Apply.
+func Rollback(currentPath string) error {
+	oldPath := currentPath + ".old"
+	if _, err := os.Stat(oldPath); err != nil {
+		return fmt.Errorf("no rollback binary at %s: %w", oldPath, err)
+	}
+	return os.Rename(oldPath, currentPath)
+}
@@ -0,0 +1,173 @@
+package updater
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelectAsset(t *testing.T) {
+	rel := &Release{
+		TagName: "v1.2.0",
+		Assets: []Asset{
+			{Name: "xcw_darwin_arm64.tar.gz", BrowserDownloadURL: "https://example.com/darwin_arm64"},
+			{Name: "xcw_linux_amd64.tar.gz", BrowserDownloadURL: "https://example.com/linux_amd64"},
+		},
+	}
+
+	asset, err := SelectAsset(rel, "darwin", "arm64")
+	if err != nil {
+		t.Fatalf("SelectAsset: %v", err)
+	}
+	if asset.BrowserDownloadURL != "https://example.com/darwin_arm64" {
+		t.Fatalf("unexpected asset: %+v", asset)
+	}
+
+	if _, err := SelectAsset(rel, "windows", "amd64"); err == nil {
+		t.Fatal("expected error for unpublished goos/goarch combination")
+	}
+}
+
+func TestReleaseVersionStripsLeadingV(t *testing.T) {
+	rel := &Release{TagName: "v1.2.0"}
+	if got := rel.Version(); got != "1.2.0" {
+		t.Fatalf("Version() = %q, want %q", got, "1.2.0")
+	}
+}
+
+func TestFetchReleaseStable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/vburojevic/xcw/releases/latest" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"tag_name":"v2.0.0","prerelease":false,"assets":[]}`))
+	}))
+	defer srv.Close()
+
+	prev := apiBaseURL
+	apiBaseURL = srv.URL
+	defer func() { apiBaseURL = prev }()
+
+	rel, err := FetchRelease(context.Background(), ChannelStable)
+	if err != nil {
+		t.Fatalf("FetchRelease: %v", err)
+	}
+	if rel.TagName != "v2.0.0" {
+		t.Fatalf("unexpected release: %+v", rel)
+	}
+}
+
+func TestDownloadAndVerifyChecksum(t *testing.T) {
+	const body = "pretend binary contents"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "xcw.new")
+
+	var lastProgress int64
+	err := Download(context.Background(), srv.URL, dest, func(n int64) { lastProgress = n })
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if lastProgress != int64(len(body)) {
+		t.Fatalf("progress reported %d bytes, want %d", lastProgress, len(body))
+	}
+
+	// A made-up digest, not sha256("pretend binary contents").
+	const wantSum = "05baf0dc9a7670db3879f7c5f8154cd4405acd52f6d0c6f1e0f8e6b3dd9c0a7e"
+	if err := VerifyChecksum(dest, wantSum); err == nil {
+		t.Fatal("expected mismatch against a made-up checksum")
+	}
+
+	b, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(b) != body {
+		t.Fatalf("downloaded content = %q, want %q", b, body)
+	}
+}
+
+func TestApplyAndRollback(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, "xcw")
+	if err := os.WriteFile(current, []byte("old binary"), 0o755); err != nil {
+		t.Fatalf("seeding current binary: %v", err)
+	}
+	newBinary := filepath.Join(dir, "xcw.new")
+	if err := os.WriteFile(newBinary, []byte("new binary"), 0o755); err != nil {
+		t.Fatalf("seeding new binary: %v", err)
+	}
+
+	oldPath, err := Apply(newBinary, current)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	got, err := os.ReadFile(current)
+	if err != nil {
+		t.Fatalf("ReadFile current: %v", err)
+	}
+	if string(got) != "new binary" {
+		t.Fatalf("current binary = %q, want %q", got, "new binary")
+	}
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Fatalf("expected backup at %s: %v", oldPath, err)
+	}
+
+	if err := Rollback(current); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	got, err = os.ReadFile(current)
+	if err != nil {
+		t.Fatalf("ReadFile after rollback: %v", err)
+	}
+	if string(got) != "old binary" {
+		t.Fatalf("current binary after rollback = %q, want %q", got, "old binary")
+	}
+}
+
+func TestIsHomebrewInstall(t *testing.T) {
+	cases := map[string]bool{
+		"/opt/homebrew/Cellar/xcw/1.0.0/bin/xcw": true,
+		"/usr/local/Cellar/xcw/1.0.0/bin/xcw":    true,
+		"/Users/dev/go/bin/xcw":                  false,
+	}
+	for path, want := range cases {
+		if got := IsHomebrewInstall(path); got != want {
+			t.Errorf("IsHomebrewInstall(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestHistoryRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "updates.json")
+
+	h, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory on missing file: %v", err)
+	}
+	if h.Last != nil {
+		t.Fatalf("expected no history yet, got %+v", h.Last)
+	}
+
+	h.Last = &HistoryEntry{PreviousVersion: "1.0.0", NewVersion: "1.1.0", RollbackPath: "/tmp/xcw.old"}
+	if err := SaveHistory(path, h); err != nil {
+		t.Fatalf("SaveHistory: %v", err)
+	}
+
+	reloaded, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	if reloaded.Last == nil || reloaded.Last.NewVersion != "1.1.0" {
+		t.Fatalf("unexpected reloaded history: %+v", reloaded.Last)
+	}
+}